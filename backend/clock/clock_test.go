@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake_SetAndAdvance(t *testing.T) {
+	start := time.Unix(1000, 0)
+	fake := NewFake(start)
+
+	if !fake.Now().Equal(start) {
+		t.Errorf("esperava %v, obteve %v", start, fake.Now())
+	}
+
+	fake.Advance(90 * time.Second)
+	if fake.Now().Unix() != 1090 {
+		t.Errorf("esperava 1090, obteve %d", fake.Now().Unix())
+	}
+
+	fake.Set(time.Unix(5, 0))
+	if fake.Now().Unix() != 5 {
+		t.Errorf("esperava 5, obteve %d", fake.Now().Unix())
+	}
+}
+
+func TestNew_ReturnsCurrentTime(t *testing.T) {
+	before := time.Now()
+	now := New().Now()
+	if now.Before(before) || now.Sub(before) > time.Second {
+		t.Errorf("relógio real fora do esperado: %v", now)
+	}
+}
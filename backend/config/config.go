@@ -1,46 +1,409 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverMemory   = "memory"
+	StorageDriverSQLite   = "sqlite"
+)
+
+const (
+	SecurityProfileDevelopment = "development"
+	SecurityProfileProduction  = "production"
 )
 
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmEdDSA = "EdDSA"
+)
+
+const (
+	NotifierDriverLog     = "log"
+	NotifierDriverWebhook = "webhook"
+	NotifierDriverNone    = "none"
+)
+
+const redacted = "[REDACTED]"
+
+type StorageConfig struct {
+	Driver string `yaml:"driver"`
+}
+
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	ConnectAttempts int           `yaml:"connect_attempts"`
+	ConnectBackoff  time.Duration `yaml:"connect_backoff"`
+	QueryTimeout    time.Duration `yaml:"query_timeout"`
+	AutoMigrate     bool          `yaml:"auto_migrate"`
+	SQLitePath      string        `yaml:"sqlite_path"`
 }
 
 type JWTConfig struct {
-	Secret string
+	Algorithm string `yaml:"algorithm"`
+	// Secret signs tokens with HS256.
+	Secret string `yaml:"secret"`
+	// PrivateKeyFile signs tokens with RS256 or EdDSA. PublicKeyFiles are
+	// previous keys still accepted while their tokens expire.
+	PrivateKeyFile string        `yaml:"private_key_file"`
+	PublicKeyFiles []string      `yaml:"public_key_files"`
+	Issuer         string        `yaml:"issuer"`
+	Audience       string        `yaml:"audience"`
+	TTL            time.Duration `yaml:"ttl"`
+}
+
+type LoginConfig struct {
+	MaxFailures        int           `yaml:"max_failures"`
+	LockoutDuration    time.Duration `yaml:"lockout_duration"`
+	MaxLockoutDuration time.Duration `yaml:"max_lockout_duration"`
+	IPRequests         int           `yaml:"ip_requests"`
+	IPWindow           time.Duration `yaml:"ip_window"`
+	CPFRequests        int           `yaml:"cpf_requests"`
+	CPFWindow          time.Duration `yaml:"cpf_window"`
+}
+
+// PasswordConfig is the strength policy enforced whenever a password is set,
+// and the lifetime of the tokens that reset it. Activation tokens, sent to
+// imported associates to choose their first password, live longer.
+type PasswordConfig struct {
+	MinLength          int           `yaml:"min_length"`
+	RequireLetter      bool          `yaml:"require_letter"`
+	RequireDigit       bool          `yaml:"require_digit"`
+	RequireSymbol      bool          `yaml:"require_symbol"`
+	ResetTokenTTL      time.Duration `yaml:"reset_token_ttl"`
+	ActivationTokenTTL time.Duration `yaml:"activation_token_ttl"`
+}
+
+// TwoFactorConfig configures TOTP. Issuer is the account label shown by
+// authenticator apps; ChallengeTTL is how long a user has to type the code
+// after the password was accepted.
+type TwoFactorConfig struct {
+	Issuer       string        `yaml:"issuer"`
+	ChallengeTTL time.Duration `yaml:"challenge_ttl"`
+}
+
+// OIDCConfig enables single sign-on through an OpenID Connect provider.
+// CPFClaim names the ID token claim holding the user's CPF ("sub" when the
+// provider identifies users by CPF); AutoProvision creates the users it does
+// not know yet instead of refusing them.
+type OIDCConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	IssuerURL     string        `yaml:"issuer_url"`
+	ClientID      string        `yaml:"client_id"`
+	ClientSecret  string        `yaml:"client_secret"`
+	RedirectURL   string        `yaml:"redirect_url"`
+	Scopes        []string      `yaml:"scopes"`
+	CPFClaim      string        `yaml:"cpf_claim"`
+	AutoProvision bool          `yaml:"auto_provision"`
+	StateTTL      time.Duration `yaml:"state_ttl"`
+	HTTPTimeout   time.Duration `yaml:"http_timeout"`
+}
+
+// NotifierConfig selects how messages such as password reset tokens reach the
+// users.
+type NotifierConfig struct {
+	Driver         string        `yaml:"driver"`
+	WebhookURL     string        `yaml:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+}
+
+type RouteLimit struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// RateLimitConfig holds the limits of each route group registered in
+// routes.RegisterRoutes.
+type RateLimitConfig struct {
+	Enabled       bool       `yaml:"enabled"`
+	Public        RouteLimit `yaml:"public"`
+	Authenticated RouteLimit `yaml:"authenticated"`
+	Votes         RouteLimit `yaml:"votes"`
+}
+
+type ServerConfig struct {
+	Address           string        `yaml:"address"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	// TrustedProxies lists the addresses or CIDRs of the reverse proxies
+	// whose X-Forwarded-For is believed. Empty trusts none, so the client IP
+	// used by the rate limits can't be spoofed.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+type SecurityConfig struct {
+	HeadersProfile string `yaml:"headers_profile"`
+}
+
+type WorkersConfig struct {
+	SessionExpiryInterval time.Duration `yaml:"session_expiry_interval"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter"`
+	ServiceName string `yaml:"service_name"`
 }
 
 type Config struct {
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Server    ServerConfig    `yaml:"server"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Security  SecurityConfig  `yaml:"security"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Login     LoginConfig     `yaml:"login"`
+	Password  PasswordConfig  `yaml:"password"`
+	TwoFactor TwoFactorConfig `yaml:"two_factor"`
+	OIDC      OIDCConfig      `yaml:"oidc"`
+	Notifier  NotifierConfig  `yaml:"notifier"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Workers   WorkersConfig   `yaml:"workers"`
 }
 
 var AppConfig *Config
 
-func LoadConfig() {
-	AppConfig = &Config{
+func Defaults() *Config {
+	return &Config{
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "votacao-api",
+		},
+		Server: ServerConfig{
+			Address:           ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   []string{"http://localhost:5173", "http://localhost", "http://localhost:80"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowCredentials: true,
+		},
+		Security: SecurityConfig{
+			HeadersProfile: SecurityProfileDevelopment,
+		},
+		Storage: StorageConfig{
+			Driver: StorageDriverPostgres,
+		},
 		Database: DatabaseConfig{
-			Host:     getEnv("POSTGRES_HOST", ""),
-			Port:     getEnv("POSTGRES_PORT", ""),
-			User:     getEnv("POSTGRES_USER", ""),
-			Password: getEnv("POSTGRES_PASSWORD", ""),
-			Name:     getEnv("POSTGRES_DB", ""),
+			ConnectAttempts: 10,
+			ConnectBackoff:  500 * time.Millisecond,
+			QueryTimeout:    5 * time.Second,
+			SQLitePath:      "votacao.db",
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", ""),
+			Algorithm: JWTAlgorithmHS256,
+			Issuer:    "votacao-api",
+			Audience:  "votacao-api",
+			TTL:       time.Hour,
+		},
+		Login: LoginConfig{
+			MaxFailures:        5,
+			LockoutDuration:    time.Minute,
+			MaxLockoutDuration: time.Hour,
+			IPRequests:         20,
+			IPWindow:           time.Minute,
+			CPFRequests:        10,
+			CPFWindow:          time.Minute,
+		},
+		Password: PasswordConfig{
+			MinLength:          8,
+			RequireLetter:      true,
+			RequireDigit:       true,
+			ResetTokenTTL:      30 * time.Minute,
+			ActivationTokenTTL: 7 * 24 * time.Hour,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:       "Votação Cooperativa",
+			ChallengeTTL: 5 * time.Minute,
+		},
+		OIDC: OIDCConfig{
+			Scopes:      []string{"openid", "profile"},
+			CPFClaim:    "cpf",
+			StateTTL:    10 * time.Minute,
+			HTTPTimeout: 10 * time.Second,
 		},
+		Notifier: NotifierConfig{
+			Driver:         NotifierDriverNone,
+			WebhookTimeout: 5 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:       true,
+			Public:        RouteLimit{Requests: 300, Window: time.Minute},
+			Authenticated: RouteLimit{Requests: 120, Window: time.Minute},
+			Votes:         RouteLimit{Requests: 10, Window: time.Minute},
+		},
+		Workers: WorkersConfig{
+			SessionExpiryInterval: 15 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if
+// any) and the environment, in increasing order of precedence. Unless the file
+// itself cannot be read, the result is returned alongside any validation
+// errors so it can still be inspected.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if cfg == nil {
+		return nil, err
+	}
+	return cfg, errors.Join(err, cfg.Validate())
+}
+
+// Read is Load without the validation, for callers that need only part of
+// the configuration and check it with ValidateStorage.
+func Read(path string) (*Config, error) {
+	cfg := Defaults()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler arquivo de configuração: %w", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("arquivo de configuração %s inválido: %w", path, err)
+		}
 	}
+
+	return cfg, applyEnv(cfg)
 }
 
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Redacted returns a copy safe to print or log.
+func (c Config) Redacted() Config {
+	if c.JWT.Secret != "" {
+		c.JWT.Secret = redacted
+	}
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
 	}
-	return fallback
+	if c.OIDC.ClientSecret != "" {
+		c.OIDC.ClientSecret = redacted
+	}
+	return c
+}
+
+func applyEnv(cfg *Config) error {
+	env := &envReader{}
+
+	env.string("LOG_LEVEL", &cfg.Log.Level)
+	env.string("LOG_FORMAT", &cfg.Log.Format)
+	env.string("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	env.string("TRACING_SERVICE_NAME", &cfg.Tracing.ServiceName)
+
+	env.string("SERVER_ADDRESS", &cfg.Server.Address)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	env.duration("SERVER_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	env.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	env.list("SERVER_TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	env.list("CORS_ALLOWED_ORIGINS", &cfg.CORS.AllowedOrigins)
+	env.list("CORS_ALLOWED_METHODS", &cfg.CORS.AllowedMethods)
+	env.bool("CORS_ALLOW_CREDENTIALS", &cfg.CORS.AllowCredentials)
+	env.string("SECURITY_HEADERS_PROFILE", &cfg.Security.HeadersProfile)
+
+	env.string("STORAGE_DRIVER", &cfg.Storage.Driver)
+	env.string("POSTGRES_HOST", &cfg.Database.Host)
+	env.string("POSTGRES_PORT", &cfg.Database.Port)
+	env.string("POSTGRES_USER", &cfg.Database.User)
+	env.string("POSTGRES_PASSWORD", &cfg.Database.Password)
+	env.string("POSTGRES_DB", &cfg.Database.Name)
+	env.int("DB_CONNECT_ATTEMPTS", &cfg.Database.ConnectAttempts)
+	env.duration("DB_CONNECT_BACKOFF", &cfg.Database.ConnectBackoff)
+	env.duration("DB_QUERY_TIMEOUT", &cfg.Database.QueryTimeout)
+	env.bool("DB_AUTO_MIGRATE", &cfg.Database.AutoMigrate)
+	env.string("SQLITE_PATH", &cfg.Database.SQLitePath)
+
+	env.string("JWT_ALGORITHM", &cfg.JWT.Algorithm)
+	env.string("JWT_SECRET", &cfg.JWT.Secret)
+	env.string("JWT_PRIVATE_KEY_FILE", &cfg.JWT.PrivateKeyFile)
+	env.list("JWT_PUBLIC_KEY_FILES", &cfg.JWT.PublicKeyFiles)
+	env.string("JWT_ISSUER", &cfg.JWT.Issuer)
+	env.string("JWT_AUDIENCE", &cfg.JWT.Audience)
+	env.duration("JWT_TTL", &cfg.JWT.TTL)
+
+	env.int("LOGIN_MAX_FAILURES", &cfg.Login.MaxFailures)
+	env.duration("LOGIN_LOCKOUT_DURATION", &cfg.Login.LockoutDuration)
+	env.duration("LOGIN_MAX_LOCKOUT_DURATION", &cfg.Login.MaxLockoutDuration)
+	env.int("LOGIN_IP_REQUESTS", &cfg.Login.IPRequests)
+	env.duration("LOGIN_IP_WINDOW", &cfg.Login.IPWindow)
+	env.int("LOGIN_CPF_REQUESTS", &cfg.Login.CPFRequests)
+	env.duration("LOGIN_CPF_WINDOW", &cfg.Login.CPFWindow)
+
+	env.int("PASSWORD_MIN_LENGTH", &cfg.Password.MinLength)
+	env.bool("PASSWORD_REQUIRE_LETTER", &cfg.Password.RequireLetter)
+	env.bool("PASSWORD_REQUIRE_DIGIT", &cfg.Password.RequireDigit)
+	env.bool("PASSWORD_REQUIRE_SYMBOL", &cfg.Password.RequireSymbol)
+	env.duration("PASSWORD_RESET_TOKEN_TTL", &cfg.Password.ResetTokenTTL)
+	env.duration("PASSWORD_ACTIVATION_TOKEN_TTL", &cfg.Password.ActivationTokenTTL)
+
+	env.string("TWO_FACTOR_ISSUER", &cfg.TwoFactor.Issuer)
+	env.duration("TWO_FACTOR_CHALLENGE_TTL", &cfg.TwoFactor.ChallengeTTL)
+
+	env.bool("OIDC_ENABLED", &cfg.OIDC.Enabled)
+	env.string("OIDC_ISSUER_URL", &cfg.OIDC.IssuerURL)
+	env.string("OIDC_CLIENT_ID", &cfg.OIDC.ClientID)
+	env.string("OIDC_CLIENT_SECRET", &cfg.OIDC.ClientSecret)
+	env.string("OIDC_REDIRECT_URL", &cfg.OIDC.RedirectURL)
+	env.list("OIDC_SCOPES", &cfg.OIDC.Scopes)
+	env.string("OIDC_CPF_CLAIM", &cfg.OIDC.CPFClaim)
+	env.bool("OIDC_AUTO_PROVISION", &cfg.OIDC.AutoProvision)
+	env.duration("OIDC_STATE_TTL", &cfg.OIDC.StateTTL)
+	env.duration("OIDC_HTTP_TIMEOUT", &cfg.OIDC.HTTPTimeout)
+
+	env.string("NOTIFIER_DRIVER", &cfg.Notifier.Driver)
+	env.string("NOTIFIER_WEBHOOK_URL", &cfg.Notifier.WebhookURL)
+	env.duration("NOTIFIER_WEBHOOK_TIMEOUT", &cfg.Notifier.WebhookTimeout)
+
+	env.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	env.int("RATE_LIMIT_PUBLIC_REQUESTS", &cfg.RateLimit.Public.Requests)
+	env.duration("RATE_LIMIT_PUBLIC_WINDOW", &cfg.RateLimit.Public.Window)
+	env.int("RATE_LIMIT_AUTHENTICATED_REQUESTS", &cfg.RateLimit.Authenticated.Requests)
+	env.duration("RATE_LIMIT_AUTHENTICATED_WINDOW", &cfg.RateLimit.Authenticated.Window)
+	env.int("RATE_LIMIT_VOTES_REQUESTS", &cfg.RateLimit.Votes.Requests)
+	env.duration("RATE_LIMIT_VOTES_WINDOW", &cfg.RateLimit.Votes.Window)
+
+	env.duration("SESSION_EXPIRY_INTERVAL", &cfg.Workers.SessionExpiryInterval)
+
+	return errors.Join(env.errs...)
 }
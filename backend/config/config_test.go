@@ -0,0 +1,289 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+var envKeys = []string{
+	"LOG_LEVEL", "LOG_FORMAT", "TRACING_EXPORTER", "TRACING_SERVICE_NAME",
+	"SERVER_ADDRESS", "SERVER_READ_TIMEOUT", "SERVER_READ_HEADER_TIMEOUT",
+	"SERVER_WRITE_TIMEOUT", "SERVER_IDLE_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT", "SERVER_TRUSTED_PROXIES",
+	"CORS_ALLOWED_ORIGINS", "CORS_ALLOWED_METHODS", "CORS_ALLOW_CREDENTIALS",
+	"SECURITY_HEADERS_PROFILE", "STORAGE_DRIVER",
+	"POSTGRES_HOST", "POSTGRES_PORT", "POSTGRES_USER", "POSTGRES_PASSWORD", "POSTGRES_DB",
+	"DB_CONNECT_ATTEMPTS", "DB_CONNECT_BACKOFF", "DB_QUERY_TIMEOUT", "DB_AUTO_MIGRATE",
+	"SQLITE_PATH", "JWT_SECRET", "SESSION_EXPIRY_INTERVAL",
+	"JWT_ALGORITHM", "JWT_PRIVATE_KEY_FILE", "JWT_PUBLIC_KEY_FILES", "JWT_ISSUER", "JWT_AUDIENCE", "JWT_TTL",
+	"LOGIN_MAX_FAILURES", "LOGIN_LOCKOUT_DURATION", "LOGIN_MAX_LOCKOUT_DURATION",
+	"LOGIN_IP_REQUESTS", "LOGIN_IP_WINDOW", "LOGIN_CPF_REQUESTS", "LOGIN_CPF_WINDOW",
+	"RATE_LIMIT_ENABLED", "RATE_LIMIT_PUBLIC_REQUESTS", "RATE_LIMIT_PUBLIC_WINDOW",
+	"RATE_LIMIT_AUTHENTICATED_REQUESTS", "RATE_LIMIT_AUTHENTICATED_WINDOW",
+	"RATE_LIMIT_VOTES_REQUESTS", "RATE_LIMIT_VOTES_WINDOW",
+	"OIDC_ENABLED", "OIDC_ISSUER_URL", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_REDIRECT_URL",
+	"OIDC_SCOPES", "OIDC_CPF_CLAIM", "OIDC_AUTO_PROVISION", "OIDC_STATE_TTL", "OIDC_HTTP_TIMEOUT",
+}
+
+// clearEnv unsets every variable read by Load for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range envKeys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_DefaultsWithRequiredSettings(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("STORAGE_DRIVER", StorageDriverMemory)
+	t.Setenv("JWT_SECRET", testSecret)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if cfg.Server.Address != ":8080" || cfg.Server.ShutdownTimeout != 20*time.Second || len(cfg.Server.TrustedProxies) != 0 {
+		t.Errorf("servidor com padrões inesperados: %+v", cfg.Server)
+	}
+	if cfg.Database.ConnectAttempts != 10 || cfg.Workers.SessionExpiryInterval != 15*time.Second {
+		t.Errorf("padrões inesperados: %+v %+v", cfg.Database, cfg.Workers)
+	}
+	if cfg.Notifier.Driver != NotifierDriverNone {
+		t.Errorf("esperava notificador %q por padrão, obteve %q", NotifierDriverNone, cfg.Notifier.Driver)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 3 {
+		t.Errorf("origens CORS padrão inesperadas: %v", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, `
+server:
+  address: ":9090"
+  write_timeout: 30s
+cors:
+  allowed_origins:
+    - https://arquivo.example
+storage:
+  driver: sqlite
+database:
+  sqlite_path: /tmp/arquivo.db
+jwt:
+  secret: `+testSecret+`
+`)
+	t.Setenv("SERVER_ADDRESS", ":7070")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.1, 172.16.0.0/12")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example,")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	t.Setenv("SECURITY_HEADERS_PROFILE", SecurityProfileProduction)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if cfg.Server.Address != ":7070" {
+		t.Errorf("esperava endereço do ambiente, obteve %q", cfg.Server.Address)
+	}
+	if cfg.Server.WriteTimeout != 30*time.Second {
+		t.Errorf("esperava write_timeout do arquivo, obteve %v", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.ReadTimeout != 15*time.Second {
+		t.Errorf("esperava read_timeout padrão, obteve %v", cfg.Server.ReadTimeout)
+	}
+	if cfg.Storage.Driver != StorageDriverSQLite || cfg.Database.SQLitePath != "/tmp/arquivo.db" {
+		t.Errorf("armazenamento do arquivo não aplicado: %+v %+v", cfg.Storage, cfg.Database)
+	}
+	if want := []string{"10.0.0.1", "172.16.0.0/12"}; !slices.Equal(cfg.Server.TrustedProxies, want) {
+		t.Errorf("esperava proxies %v, obteve %v", want, cfg.Server.TrustedProxies)
+	}
+	want := []string{"https://a.example", "https://b.example"}
+	if !slices.Equal(cfg.CORS.AllowedOrigins, want) {
+		t.Errorf("esperava %v, obteve %v", want, cfg.CORS.AllowedOrigins)
+	}
+	if cfg.CORS.AllowCredentials || cfg.Security.HeadersProfile != SecurityProfileProduction {
+		t.Errorf("CORS/segurança do ambiente não aplicados: %+v %+v", cfg.CORS, cfg.Security)
+	}
+}
+
+func TestLoad_RejectsUnknownFileKeys(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "server:\n  adress: \":9090\"\n")
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("esperava erro para chave desconhecida")
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	clearEnv(t)
+
+	if _, err := Load(filepath.Join(t.TempDir(), "inexistente.yaml")); err == nil {
+		t.Fatal("esperava erro para arquivo inexistente")
+	}
+}
+
+func TestLoad_InvalidEnvValue(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("STORAGE_DRIVER", StorageDriverMemory)
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("DB_QUERY_TIMEOUT", "cinco")
+	t.Setenv("DB_CONNECT_ATTEMPTS", "dez")
+
+	_, err := Load("")
+	if err == nil {
+		t.Fatal("esperava erro para valores inválidos")
+	}
+	for _, key := range []string{"DB_QUERY_TIMEOUT", "DB_CONNECT_ATTEMPTS"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("erro deveria mencionar %s: %v", key, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		cfg := Defaults()
+		cfg.Storage.Driver = StorageDriverMemory
+		cfg.JWT.Secret = testSecret
+		return cfg
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   string
+	}{
+		{"segredo ausente", func(c *Config) { c.JWT.Secret = "" }, "JWT_SECRET) é obrigatório"},
+		{"algoritmo desconhecido", func(c *Config) { c.JWT.Algorithm = "none" }, "JWT_ALGORITHM"},
+		{"chave privada ausente", func(c *Config) { c.JWT.Algorithm = JWTAlgorithmEdDSA }, "JWT_PRIVATE_KEY_FILE"},
+		{"audiência ausente", func(c *Config) { c.JWT.Audience = "" }, "JWT_AUDIENCE"},
+		{"segredo curto", func(c *Config) { c.JWT.Secret = "curto" }, "pelo menos 32 caracteres"},
+		{"driver desconhecido", func(c *Config) { c.Storage.Driver = "mysql" }, "STORAGE_DRIVER"},
+		{"postgres sem host", func(c *Config) {
+			c.Storage.Driver = StorageDriverPostgres
+			c.Database.Port, c.Database.User, c.Database.Name = "5432", "u", "db"
+		}, "POSTGRES_HOST"},
+		{"sqlite sem caminho", func(c *Config) {
+			c.Storage.Driver = StorageDriverSQLite
+			c.Database.SQLitePath = ""
+		}, "SQLITE_PATH"},
+		{"formato de log inválido", func(c *Config) { c.Log.Format = "xml" }, "LOG_FORMAT"},
+		{"exportador inválido", func(c *Config) { c.Tracing.Exporter = "jaeger" }, "TRACING_EXPORTER"},
+		{"timeout zerado", func(c *Config) { c.Server.WriteTimeout = 0 }, "SERVER_WRITE_TIMEOUT"},
+		{"proxy inválido", func(c *Config) { c.Server.TrustedProxies = []string{"proxy.interno"} }, "SERVER_TRUSTED_PROXIES"},
+		{"sem tentativas de conexão", func(c *Config) { c.Database.ConnectAttempts = 0 }, "DB_CONNECT_ATTEMPTS"},
+		{"origem vazia", func(c *Config) { c.CORS.AllowedOrigins = []string{""} }, "CORS_ALLOWED_ORIGINS"},
+		{"curinga com credenciais", func(c *Config) { c.CORS.AllowedOrigins = []string{"*"} }, "CORS_ALLOW_CREDENTIALS"},
+		{"método desconhecido", func(c *Config) { c.CORS.AllowedMethods = []string{"FETCH"} }, "CORS_ALLOWED_METHODS"},
+		{"sem métodos", func(c *Config) { c.CORS.AllowedMethods = nil }, "CORS_ALLOWED_METHODS"},
+		{"bloqueio máximo menor que o inicial", func(c *Config) { c.Login.MaxLockoutDuration = time.Second }, "LOGIN_MAX_LOCKOUT_DURATION"},
+		{"sem requisições de login por IP", func(c *Config) { c.Login.IPRequests = 0 }, "LOGIN_IP_REQUESTS"},
+		{"limite de votos sem janela", func(c *Config) { c.RateLimit.Votes.Window = 0 }, "RATE_LIMIT_VOTES_WINDOW"},
+		{"senha mínima acima do bcrypt", func(c *Config) { c.Password.MinLength = 73 }, "PASSWORD_MIN_LENGTH"},
+		{"redefinição sem validade", func(c *Config) { c.Password.ResetTokenTTL = 0 }, "PASSWORD_RESET_TOKEN_TTL"},
+		{"ativação sem validade", func(c *Config) { c.Password.ActivationTokenTTL = 0 }, "PASSWORD_ACTIVATION_TOKEN_TTL"},
+		{"desafio 2FA sem validade", func(c *Config) { c.TwoFactor.ChallengeTTL = 0 }, "TWO_FACTOR_CHALLENGE_TTL"},
+		{"notificador desconhecido", func(c *Config) { c.Notifier.Driver = "smtp" }, "NOTIFIER_DRIVER"},
+		{"notificador de log em produção", func(c *Config) {
+			c.Notifier.Driver = NotifierDriverLog
+			c.Security.HeadersProfile = SecurityProfileProduction
+		}, "NOTIFIER_DRIVER"},
+		{"webhook sem URL", func(c *Config) { c.Notifier.Driver = NotifierDriverWebhook }, "NOTIFIER_WEBHOOK_URL"},
+		{"OIDC sem emissor", func(c *Config) {
+			c.OIDC.Enabled, c.OIDC.ClientID, c.OIDC.RedirectURL = true, "votacao", "http://localhost/login/oidc"
+		}, "OIDC_ISSUER_URL"},
+		{"OIDC sem escopo openid", func(c *Config) {
+			c.OIDC = OIDCConfig{Enabled: true, IssuerURL: "https://idp.exemplo", ClientID: "votacao", RedirectURL: "http://localhost/login/oidc", Scopes: []string{"profile"}, CPFClaim: "cpf", StateTTL: time.Minute, HTTPTimeout: time.Second}
+		}, "OIDC_SCOPES"},
+		{"perfil desconhecido", func(c *Config) { c.Security.HeadersProfile = "staging" }, "SECURITY_HEADERS_PROFILE"},
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("configuração base deveria ser válida: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatal("esperava erro de validação")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("esperava erro contendo %q, obteve %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateStorage_IgnoresAPISettings(t *testing.T) {
+	cfg := Defaults()
+	cfg.Storage.Driver = StorageDriverSQLite
+	cfg.Server.Address = ""
+
+	if err := cfg.ValidateStorage(); err != nil {
+		t.Errorf("apenas o armazenamento deveria ser validado: %v", err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("a validação completa deveria exigir JWT_SECRET e SERVER_ADDRESS")
+	}
+
+	cfg.Database.SQLitePath = ""
+	if err := cfg.ValidateStorage(); err == nil || !strings.Contains(err.Error(), "SQLITE_PATH") {
+		t.Errorf("esperava erro de SQLITE_PATH, obteve %v", err)
+	}
+}
+
+func TestValidate_AsymmetricJWTDoesNotNeedSecret(t *testing.T) {
+	cfg := Defaults()
+	cfg.Storage.Driver = StorageDriverMemory
+	cfg.JWT.Algorithm = JWTAlgorithmRS256
+	cfg.JWT.PrivateKeyFile = "/etc/votacao/jwt.pem"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("RS256 com chave privada deveria ser válido sem segredo: %v", err)
+	}
+}
+
+func TestValidate_RateLimitDisabledSkipsLimits(t *testing.T) {
+	cfg := Defaults()
+	cfg.Storage.Driver = StorageDriverMemory
+	cfg.JWT.Secret = testSecret
+	cfg.RateLimit = RateLimitConfig{Enabled: false}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("limites não deveriam ser validados com o limite desativado: %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Defaults()
+	cfg.JWT.Secret = testSecret
+	cfg.Database.Password = "senha"
+	cfg.OIDC.ClientSecret = "segredo-oidc"
+
+	r := cfg.Redacted()
+	if r.JWT.Secret != redacted || r.Database.Password != redacted || r.OIDC.ClientSecret != redacted {
+		t.Errorf("segredos não ocultados: %q %q %q", r.JWT.Secret, r.Database.Password, r.OIDC.ClientSecret)
+	}
+	if cfg.JWT.Secret != testSecret || cfg.Database.Password != "senha" {
+		t.Error("Redacted não deveria alterar a configuração original")
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReader overrides fields with the environment variables that are set,
+// collecting every malformed value instead of stopping at the first one.
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (r *envReader) invalid(key, value string, err error) {
+	r.errs = append(r.errs, fmt.Errorf("valor inválido para %s (%q): %w", key, value, err))
+}
+
+func (r *envReader) string(key string, dst *string) {
+	if value, ok := r.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (r *envReader) duration(key string, dst *time.Duration) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		r.invalid(key, value, err)
+		return
+	}
+	*dst = d
+}
+
+func (r *envReader) int(key string, dst *int) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		r.invalid(key, value, err)
+		return
+	}
+	*dst = n
+}
+
+func (r *envReader) bool(key string, dst *bool) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		r.invalid(key, value, err)
+		return
+	}
+	*dst = b
+}
+
+// list reads a comma-separated value, ignoring blanks around each item.
+func (r *envReader) list(key string, dst *[]string) {
+	value, ok := r.lookup(key)
+	if !ok {
+		return
+	}
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
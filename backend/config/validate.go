@@ -0,0 +1,221 @@
+package config
+
+import (
+	"desafio-tecnico-fullstack/backend/passwords"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const minJWTSecretLength = 32
+
+// Validate reports every problem at once so a misconfigured deployment can be
+// fixed in a single pass.
+func (c *Config) Validate() error {
+	v := &validator{}
+	c.validateLog(v)
+	v.oneOf("tracing.exporter (TRACING_EXPORTER)", c.Tracing.Exporter, "none", "stdout", "otlp")
+
+	if c.Server.Address == "" {
+		v.fail("server.address (SERVER_ADDRESS) é obrigatório")
+	}
+	v.positive("server.read_timeout (SERVER_READ_TIMEOUT)", c.Server.ReadTimeout)
+	v.positive("server.read_header_timeout (SERVER_READ_HEADER_TIMEOUT)", c.Server.ReadHeaderTimeout)
+	v.positive("server.write_timeout (SERVER_WRITE_TIMEOUT)", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout (SERVER_IDLE_TIMEOUT)", c.Server.IdleTimeout)
+	v.positive("server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT)", c.Server.ShutdownTimeout)
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			v.fail("server.trusted_proxies (SERVER_TRUSTED_PROXIES) deve conter IPs ou CIDRs, obteve %q", proxy)
+		}
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "" {
+			v.fail("cors.allowed_origins (CORS_ALLOWED_ORIGINS) não pode conter origens vazias")
+		}
+	}
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		v.fail("cors.allowed_origins (CORS_ALLOWED_ORIGINS) não pode usar \"*\" com cors.allow_credentials (CORS_ALLOW_CREDENTIALS)")
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		v.fail("cors.allowed_methods (CORS_ALLOWED_METHODS) deve ter pelo menos um método")
+	}
+	for _, method := range c.CORS.AllowedMethods {
+		v.oneOf("cors.allowed_methods (CORS_ALLOWED_METHODS)", method, "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS")
+	}
+	v.oneOf("security.headers_profile (SECURITY_HEADERS_PROFILE)", c.Security.HeadersProfile, SecurityProfileDevelopment, SecurityProfileProduction)
+
+	c.validateStorage(v)
+
+	v.oneOf("jwt.algorithm (JWT_ALGORITHM)", c.JWT.Algorithm, JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA)
+	switch c.JWT.Algorithm {
+	case JWTAlgorithmHS256:
+		switch {
+		case c.JWT.Secret == "":
+			v.fail("jwt.secret (JWT_SECRET) é obrigatório com o algoritmo HS256")
+		case len(c.JWT.Secret) < minJWTSecretLength:
+			v.fail("jwt.secret (JWT_SECRET) deve ter pelo menos %d caracteres", minJWTSecretLength)
+		}
+	case JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+		if c.JWT.PrivateKeyFile == "" {
+			v.fail("jwt.private_key_file (JWT_PRIVATE_KEY_FILE) é obrigatório com o algoritmo %s", c.JWT.Algorithm)
+		}
+	}
+	if c.JWT.Issuer == "" {
+		v.fail("jwt.issuer (JWT_ISSUER) é obrigatório")
+	}
+	if c.JWT.Audience == "" {
+		v.fail("jwt.audience (JWT_AUDIENCE) é obrigatório")
+	}
+	v.positive("jwt.ttl (JWT_TTL)", c.JWT.TTL)
+
+	if c.Login.MaxFailures < 0 {
+		v.fail("login.max_failures (LOGIN_MAX_FAILURES) não pode ser negativo")
+	}
+	v.positive("login.lockout_duration (LOGIN_LOCKOUT_DURATION)", c.Login.LockoutDuration)
+	if c.Login.MaxLockoutDuration < c.Login.LockoutDuration {
+		v.fail("login.max_lockout_duration (LOGIN_MAX_LOCKOUT_DURATION) não pode ser menor que login.lockout_duration (LOGIN_LOCKOUT_DURATION)")
+	}
+	if c.Login.IPRequests < 1 {
+		v.fail("login.ip_requests (LOGIN_IP_REQUESTS) deve ser pelo menos 1")
+	}
+	v.positive("login.ip_window (LOGIN_IP_WINDOW)", c.Login.IPWindow)
+	if c.Login.CPFRequests < 1 {
+		v.fail("login.cpf_requests (LOGIN_CPF_REQUESTS) deve ser pelo menos 1")
+	}
+	v.positive("login.cpf_window (LOGIN_CPF_WINDOW)", c.Login.CPFWindow)
+
+	if c.Password.MinLength < 1 || c.Password.MinLength > passwords.MaxLength {
+		v.fail("password.min_length (PASSWORD_MIN_LENGTH) deve estar entre 1 e %d", passwords.MaxLength)
+	}
+	v.positive("password.reset_token_ttl (PASSWORD_RESET_TOKEN_TTL)", c.Password.ResetTokenTTL)
+	v.positive("password.activation_token_ttl (PASSWORD_ACTIVATION_TOKEN_TTL)", c.Password.ActivationTokenTTL)
+
+	if c.TwoFactor.Issuer == "" {
+		v.fail("two_factor.issuer (TWO_FACTOR_ISSUER) é obrigatório")
+	}
+	v.positive("two_factor.challenge_ttl (TWO_FACTOR_CHALLENGE_TTL)", c.TwoFactor.ChallengeTTL)
+
+	if c.OIDC.Enabled {
+		httpURL := func(field, value string) {
+			if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				v.fail("%s deve ser uma URL http(s) com o OIDC ativado", field)
+			}
+		}
+		httpURL("oidc.issuer_url (OIDC_ISSUER_URL)", c.OIDC.IssuerURL)
+		httpURL("oidc.redirect_url (OIDC_REDIRECT_URL)", c.OIDC.RedirectURL)
+		if c.OIDC.ClientID == "" {
+			v.fail("oidc.client_id (OIDC_CLIENT_ID) é obrigatório com o OIDC ativado")
+		}
+		if !slices.Contains(c.OIDC.Scopes, "openid") {
+			v.fail("oidc.scopes (OIDC_SCOPES) deve incluir \"openid\"")
+		}
+		if c.OIDC.CPFClaim == "" {
+			v.fail("oidc.cpf_claim (OIDC_CPF_CLAIM) é obrigatório com o OIDC ativado")
+		}
+		v.positive("oidc.state_ttl (OIDC_STATE_TTL)", c.OIDC.StateTTL)
+		v.positive("oidc.http_timeout (OIDC_HTTP_TIMEOUT)", c.OIDC.HTTPTimeout)
+	}
+
+	v.oneOf("notifier.driver (NOTIFIER_DRIVER)", c.Notifier.Driver, NotifierDriverLog, NotifierDriverWebhook, NotifierDriverNone)
+	if c.Notifier.Driver == NotifierDriverLog && c.Security.HeadersProfile == SecurityProfileProduction {
+		v.fail("notifier.driver (NOTIFIER_DRIVER) log é apenas para desenvolvimento e não pode ser usado com security.headers_profile (SECURITY_HEADERS_PROFILE) production")
+	}
+	if c.Notifier.Driver == NotifierDriverWebhook {
+		if u, err := url.Parse(c.Notifier.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.fail("notifier.webhook_url (NOTIFIER_WEBHOOK_URL) deve ser uma URL http(s) com o driver webhook")
+		}
+		v.positive("notifier.webhook_timeout (NOTIFIER_WEBHOOK_TIMEOUT)", c.Notifier.WebhookTimeout)
+	}
+
+	if c.RateLimit.Enabled {
+		groups := []struct {
+			name  string
+			limit RouteLimit
+		}{
+			{"public", c.RateLimit.Public},
+			{"authenticated", c.RateLimit.Authenticated},
+			{"votes", c.RateLimit.Votes},
+		}
+		for _, g := range groups {
+			env := "RATE_LIMIT_" + strings.ToUpper(g.name)
+			if g.limit.Requests < 1 {
+				v.fail("rate_limit.%s.requests (%s_REQUESTS) deve ser pelo menos 1", g.name, env)
+			}
+			v.positive(fmt.Sprintf("rate_limit.%s.window (%s_WINDOW)", g.name, env), g.limit.Window)
+		}
+	}
+
+	v.positive("workers.session_expiry_interval (SESSION_EXPIRY_INTERVAL)", c.Workers.SessionExpiryInterval)
+
+	return errors.Join(v.errs...)
+}
+
+// ValidateStorage checks only the settings the database subcommands (migrate,
+// user) depend on, so they run without the rest of the API configured.
+func (c *Config) ValidateStorage() error {
+	v := &validator{}
+	c.validateLog(v)
+	c.validateStorage(v)
+	return errors.Join(v.errs...)
+}
+
+func (c *Config) validateLog(v *validator) {
+	v.oneOf("log.level (LOG_LEVEL)", strings.ToLower(c.Log.Level), "debug", "info", "warn", "error")
+	v.oneOf("log.format (LOG_FORMAT)", strings.ToLower(c.Log.Format), "json", "text")
+}
+
+func (c *Config) validateStorage(v *validator) {
+	v.oneOf("storage.driver (STORAGE_DRIVER)", c.Storage.Driver, StorageDriverPostgres, StorageDriverSQLite, StorageDriverMemory)
+	switch c.Storage.Driver {
+	case StorageDriverPostgres:
+		required := map[string]string{
+			"database.host (POSTGRES_HOST)": c.Database.Host,
+			"database.port (POSTGRES_PORT)": c.Database.Port,
+			"database.user (POSTGRES_USER)": c.Database.User,
+			"database.name (POSTGRES_DB)":   c.Database.Name,
+		}
+		for _, field := range slices.Sorted(maps.Keys(required)) {
+			if required[field] == "" {
+				v.fail("%s é obrigatório com o armazenamento postgres", field)
+			}
+		}
+	case StorageDriverSQLite:
+		if c.Database.SQLitePath == "" {
+			v.fail("database.sqlite_path (SQLITE_PATH) é obrigatório com o armazenamento sqlite")
+		}
+	}
+	if c.Database.ConnectAttempts < 1 {
+		v.fail("database.connect_attempts (DB_CONNECT_ATTEMPTS) deve ser pelo menos 1")
+	}
+	v.positive("database.connect_backoff (DB_CONNECT_BACKOFF)", c.Database.ConnectBackoff)
+	if c.Database.QueryTimeout < 0 {
+		v.fail("database.query_timeout (DB_QUERY_TIMEOUT) não pode ser negativo")
+	}
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.fail("%s deve ser um de %s, obteve %q", field, strings.Join(allowed, ", "), value)
+	}
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	if d <= 0 {
+		v.fail("%s deve ser maior que zero", field)
+	}
+}
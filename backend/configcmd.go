@@ -0,0 +1,33 @@
+package main
+
+import (
+	"desafio-tecnico-fullstack/backend/config"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = "uso: app config print"
+
+// runConfig implements the "config print" subcommand: it writes the effective
+// configuration with secrets redacted and still fails when it is invalid.
+func runConfig(w io.Writer, cfg *config.Config, loadErr error, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+	if cfg == nil {
+		return loadErr
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return fmt.Errorf("erro ao exibir configuração: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("erro ao exibir configuração: %w", err)
+	}
+	return loadErr
+}
@@ -1,9 +1,16 @@
 package auth
 
 import (
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
+	"desafio-tecnico-fullstack/backend/passwords"
+	"desafio-tecnico-fullstack/backend/ratelimit"
 	"desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/tokens"
 	"desafio-tecnico-fullstack/backend/utils"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,11 +32,12 @@ func RegisterHandler(userService user.UserService) gin.HandlerFunc {
 			return
 		}
 
-		err := userService.RegisterUser(req.Name, req.CPF, req.Password)
+		var weak *passwords.PolicyError
+		err := userService.RegisterUser(c.Request.Context(), req.Name, req.CPF, req.Password)
 		if err != nil {
 			if err.Error() == "usuário já existe" {
 				utils.RespondError(c, http.StatusConflict, err.Error())
-			} else if err.Error() == "cpf inválido" || err.Error() == "senha muito curta" {
+			} else if err.Error() == "cpf inválido" || errors.As(err, &weak) {
 				utils.RespondError(c, http.StatusBadRequest, err.Error())
 			} else {
 				utils.RespondError(c, http.StatusInternalServerError, err.Error())
@@ -37,7 +45,7 @@ func RegisterHandler(userService user.UserService) gin.HandlerFunc {
 			return
 		}
 
-		token, user, err := userService.AuthenticateUser(req.CPF, req.Password)
+		token, user, err := userService.AuthenticateUser(c.Request.Context(), req.CPF, req.Password)
 		if err != nil {
 			utils.RespondError(c, http.StatusInternalServerError, "erro ao gerar token")
 			return
@@ -47,12 +55,25 @@ func RegisterHandler(userService user.UserService) gin.HandlerFunc {
 			"token": token,
 			"name":  user.Name,
 			"cpf":   user.CPF,
+			"role":  user.Role,
 		})
 	}
 }
 
-func LoginHandler(userService user.UserService) gin.HandlerFunc {
+// LoginLimiters throttle login attempts per client IP and per CPF, so neither
+// one address nor a distributed attack on one account can guess passwords
+// freely.
+type LoginLimiters struct {
+	ByIP  ratelimit.Limiter
+	ByCPF ratelimit.Limiter
+}
+
+func LoginHandler(userService user.UserService, limiters LoginLimiters) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if !allow(c, limiters.ByIP, "login_ip", c.ClientIP()) {
+			return
+		}
+
 		var req struct {
 			CPF      string `json:"cpf"`
 			Password string `json:"password"`
@@ -61,10 +82,26 @@ func LoginHandler(userService user.UserService) gin.HandlerFunc {
 			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
 			return
 		}
-		token, user, err := userService.AuthenticateUser(req.CPF, req.Password)
+		if !allow(c, limiters.ByCPF, "login_cpf", req.CPF) {
+			return
+		}
+
+		var locked *user.AccountLockedError
+		var twoFactor *user.TwoFactorRequiredError
+		token, user, err := userService.AuthenticateUser(c.Request.Context(), req.CPF, req.Password)
 		if err != nil {
-			if err.Error() == "usuário ou senha inválidos" {
+			if errors.As(err, &twoFactor) {
+				utils.RespondSuccess(c, gin.H{
+					"two_factor_required": true,
+					"challenge_token":     twoFactor.ChallengeToken,
+					"expires_in":          int(twoFactor.ExpiresIn.Seconds()),
+				})
+			} else if errors.As(err, &locked) {
+				respondTooManyRequests(c, ratelimit.Result{RetryAfter: locked.RetryAfter}, err.Error())
+			} else if err.Error() == "usuário ou senha inválidos" {
 				utils.RespondError(c, http.StatusUnauthorized, err.Error())
+			} else if err.Error() == "conta desativada" {
+				utils.RespondError(c, http.StatusForbidden, err.Error())
 			} else {
 				utils.RespondError(c, http.StatusInternalServerError, err.Error())
 			}
@@ -74,6 +111,124 @@ func LoginHandler(userService user.UserService) gin.HandlerFunc {
 			"token": token,
 			"name":  user.Name,
 			"cpf":   user.CPF,
+			"role":  user.Role,
 		})
 	}
 }
+
+// allow reports whether the request may proceed, answering 429 otherwise. A
+// failing limiter store lets the request through: lockout still protects the
+// accounts.
+func allow(c *gin.Context, limiter ratelimit.Limiter, name, key string) bool {
+	result, err := limiter.Allow(c.Request.Context(), key)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn("erro no limitador de taxa", "limiter", name, "error", err)
+		return true
+	}
+	if result.Allowed {
+		return true
+	}
+	metrics.RateLimited.WithLabelValues(name).Inc()
+	respondTooManyRequests(c, result, "muitas tentativas de login, tente novamente mais tarde")
+	return false
+}
+
+func respondTooManyRequests(c *gin.Context, result ratelimit.Result, message string) {
+	c.Header("Retry-After", strconv.Itoa(max(result.RetryAfterSeconds(), 1)))
+	utils.RespondError(c, http.StatusTooManyRequests, message)
+}
+
+// ChangePasswordHandler answers with a new token: the change revokes every
+// token issued before it, including the one used for this request.
+func ChangePasswordHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		if req.CurrentPassword == "" || req.NewPassword == "" {
+			utils.RespondError(c, http.StatusBadRequest, "campos obrigatórios não preenchidos")
+			return
+		}
+
+		var weak *passwords.PolicyError
+		token, err := userService.ChangePassword(c.Request.Context(), c.GetInt("user_id"), req.CurrentPassword, req.NewPassword, c.GetBool("two_factor"))
+		if err != nil {
+			if err.Error() == "senha atual incorreta" {
+				utils.RespondError(c, http.StatusUnauthorized, err.Error())
+			} else if err.Error() == "nova senha deve ser diferente da atual" || errors.As(err, &weak) {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, gin.H{"token": token})
+	}
+}
+
+// RequestPasswordResetHandler answers the same way whether or not the CPF has
+// an account.
+func RequestPasswordResetHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			CPF string `json:"cpf"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.CPF == "" {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		if err := userService.RequestPasswordReset(c.Request.Context(), req.CPF); err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		utils.RespondSuccess(c, gin.H{
+			"message": "se o CPF estiver cadastrado, as instruções de redefinição serão enviadas",
+		})
+	}
+}
+
+func ResetPasswordHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		if req.Token == "" || req.Password == "" {
+			utils.RespondError(c, http.StatusBadRequest, "campos obrigatórios não preenchidos")
+			return
+		}
+
+		var weak *passwords.PolicyError
+		err := userService.ResetPassword(c.Request.Context(), req.Token, req.Password)
+		if err != nil {
+			if err.Error() == "token de redefinição inválido ou expirado" || errors.As(err, &weak) {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, nil)
+	}
+}
+
+// JWKSHandler publishes the token verification keys in the standard JWKS
+// format, without the API response envelope, so other services can consume it
+// directly.
+func JWKSHandler(tokens tokens.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, tokens.JWKS())
+	}
+}
@@ -1,13 +1,22 @@
 package auth
 
 import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/passwords"
+	"desafio-tecnico-fullstack/backend/ratelimit"
+	userService "desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/tokens"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,19 +26,118 @@ type mockUserService struct {
 	authenticateErr   error
 	authenticateUser  *models.User
 	authenticateToken string
+	changeErr         error
+	changedUser       int
+	resetRequestErr   error
+	resetRequestedCPF string
+	resetErr          error
+	enrollErr         error
+	confirmErr        error
+	verifyErr         error
 }
 
-func (m *mockUserService) RegisterUser(name, cpf, password string) error {
+func (m *mockUserService) RegisterUser(ctx context.Context, name, cpf, password string) error {
 	return m.registerErr
 }
 
-func (m *mockUserService) AuthenticateUser(cpf, password string) (string, *models.User, error) {
+func (m *mockUserService) AuthenticateUser(ctx context.Context, cpf, password string) (string, *models.User, error) {
 	if m.authenticateErr != nil {
 		return "", nil, m.authenticateErr
 	}
 	return m.authenticateToken, m.authenticateUser, nil
 }
 
+func (m *mockUserService) UnlockUser(ctx context.Context, userID int) error {
+	return nil
+}
+
+func (m *mockUserService) AuthorizeToken(ctx context.Context, claims tokens.Claims) error {
+	return nil
+}
+
+func (m *mockUserService) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string, twoFactor bool) (string, error) {
+	m.changedUser = userID
+	if m.changeErr != nil {
+		return "", m.changeErr
+	}
+	return "new-token", nil
+}
+
+func (m *mockUserService) RequestPasswordReset(ctx context.Context, cpf string) error {
+	m.resetRequestedCPF = cpf
+	return m.resetRequestErr
+}
+
+func (m *mockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return m.resetErr
+}
+
+func (m *mockUserService) EnrollTOTP(ctx context.Context, userID int) (*userService.TOTPEnrollment, error) {
+	if m.enrollErr != nil {
+		return nil, m.enrollErr
+	}
+	return &userService.TOTPEnrollment{Secret: "SEGREDO", URI: "otpauth://totp/x"}, nil
+}
+
+func (m *mockUserService) ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error) {
+	if m.confirmErr != nil {
+		return nil, m.confirmErr
+	}
+	return []string{"abcde-fghij"}, nil
+}
+
+func (m *mockUserService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, *models.User, error) {
+	if m.verifyErr != nil {
+		return "", nil, m.verifyErr
+	}
+	return m.authenticateToken, m.authenticateUser, nil
+}
+
+func (m *mockUserService) GetProfile(ctx context.Context, userID int) (*userService.Profile, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) UpdateName(ctx context.Context, userID int, name string) (*userService.Profile, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) ListUsers(ctx context.Context, search string, page, pageSize int) (*userService.UserPage, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) SetUserActive(ctx context.Context, adminID, userID int, active bool) error {
+	return nil
+}
+
+func (m *mockUserService) ChangeRole(ctx context.Context, adminID, userID int, role string) error {
+	return nil
+}
+
+func (m *mockUserService) AdminResetPassword(ctx context.Context, adminID, userID int) (*userService.PasswordResetTicket, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) ImportUsers(ctx context.Context, adminID int, file io.Reader, opts userService.ImportOptions) (*userService.ImportReport, error) {
+	return nil, nil
+}
+
+func testLimiters(perIP, perCPF int) LoginLimiters {
+	store := ratelimit.NewMemoryStore()
+	clk := clock.NewFake(time.Unix(1000, 0))
+	return LoginLimiters{
+		ByIP:  ratelimit.NewLimiter(store, "ip", ratelimit.Limit{Requests: perIP, Window: time.Minute}, clk),
+		ByCPF: ratelimit.NewLimiter(store, "cpf", ratelimit.Limit{Requests: perCPF, Window: time.Minute}, clk),
+	}
+}
+
+func postLogin(router *gin.Engine, cpf string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"cpf":"`+cpf+`","password":"senha123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -180,17 +288,17 @@ func TestRegisterHandler_UserAlreadyExists(t *testing.T) {
 func TestRegisterHandler_ValidationErrors(t *testing.T) {
 	testCases := []struct {
 		name         string
-		serviceErr   string
+		serviceErr   error
 		expectedCode int
 	}{
-		{"invalid CPF", "cpf inválido", http.StatusBadRequest},
-		{"short password", "senha muito curta", http.StatusBadRequest},
+		{"invalid CPF", errors.New("cpf inválido"), http.StatusBadRequest},
+		{"weak password", &passwords.PolicyError{Violations: []string{"deve ter pelo menos 8 caracteres"}}, http.StatusBadRequest},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			service := &mockUserService{
-				registerErr: errors.New(tc.serviceErr),
+				registerErr: tc.serviceErr,
 			}
 
 			router := setupRouter()
@@ -210,7 +318,7 @@ func TestRegisterHandler_ValidationErrors(t *testing.T) {
 			var response map[string]interface{}
 			json.Unmarshal(w.Body.Bytes(), &response)
 
-			if response["error"] != tc.serviceErr {
+			if response["error"] != tc.serviceErr.Error() {
 				t.Errorf("esperava erro '%s', obteve '%v'", tc.serviceErr, response["error"])
 			}
 		})
@@ -275,7 +383,7 @@ func TestLoginHandler_Success(t *testing.T) {
 	}
 
 	router := setupRouter()
-	router.POST("/login", LoginHandler(service))
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
 
 	reqBody := `{"cpf":"98765432109","password":"senha123"}`
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(reqBody))
@@ -311,7 +419,7 @@ func TestLoginHandler_Success(t *testing.T) {
 func TestLoginHandler_InvalidJSON(t *testing.T) {
 	service := &mockUserService{}
 	router := setupRouter()
-	router.POST("/login", LoginHandler(service))
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
 
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{invalid json}`))
 	req.Header.Set("Content-Type", "application/json")
@@ -337,7 +445,7 @@ func TestLoginHandler_InvalidCredentials(t *testing.T) {
 	}
 
 	router := setupRouter()
-	router.POST("/login", LoginHandler(service))
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
 
 	reqBody := `{"cpf":"98765432109","password":"wrongpassword"}`
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(reqBody))
@@ -358,13 +466,32 @@ func TestLoginHandler_InvalidCredentials(t *testing.T) {
 	}
 }
 
+func TestLoginHandler_DeactivatedAccount(t *testing.T) {
+	service := &mockUserService{
+		authenticateErr: errors.New("conta desativada"),
+	}
+
+	router := setupRouter()
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
+
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"cpf":"98765432109","password":"senha123"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("esperava status 403, obteve %d", w.Code)
+	}
+}
+
 func TestLoginHandler_InternalServerError(t *testing.T) {
 	service := &mockUserService{
 		authenticateErr: errors.New("database connection failed"),
 	}
 
 	router := setupRouter()
-	router.POST("/login", LoginHandler(service))
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
 
 	reqBody := `{"cpf":"98765432109","password":"senha123"}`
 	req, _ := http.NewRequest("POST", "/login", strings.NewReader(reqBody))
@@ -377,3 +504,235 @@ func TestLoginHandler_InternalServerError(t *testing.T) {
 		t.Errorf("esperava status 500, obteve %d", w.Code)
 	}
 }
+
+func TestLoginHandler_RateLimitedByIP(t *testing.T) {
+	service := &mockUserService{authenticateErr: errors.New("usuário ou senha inválidos")}
+
+	router := setupRouter()
+	router.POST("/login", LoginHandler(service, testLimiters(2, 100)))
+
+	postLogin(router, "11111111111")
+	postLogin(router, "22222222222")
+	w := postLogin(router, "33333333333")
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("esperava status 429, obteve %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" {
+		t.Errorf("esperava Retry-After 30, obteve %q", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestLoginHandler_RateLimitedByIPIgnoresSpoofedForwardedFor(t *testing.T) {
+	service := &mockUserService{authenticateErr: errors.New("usuário ou senha inválidos")}
+
+	router := setupRouter()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatal(err)
+	}
+	router.POST("/login", LoginHandler(service, testLimiters(1, 100)))
+
+	var w *httptest.ResponseRecorder
+	for i, cpf := range []string{"11111111111", "22222222222"} {
+		req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"cpf":"`+cpf+`","password":"senha123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+		req.RemoteAddr = "198.51.100.7:1234"
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+	}
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("um X-Forwarded-For forjado não deveria renovar o limite por IP, obteve %d", w.Code)
+	}
+}
+
+func TestLoginHandler_RateLimitedByCPF(t *testing.T) {
+	service := &mockUserService{authenticateErr: errors.New("usuário ou senha inválidos")}
+
+	router := setupRouter()
+	router.POST("/login", LoginHandler(service, testLimiters(100, 1)))
+
+	if w := postLogin(router, "11111111111"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("esperava status 401, obteve %d", w.Code)
+	}
+	if w := postLogin(router, "11111111111"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("esperava status 429 para o mesmo CPF, obteve %d", w.Code)
+	}
+	if w := postLogin(router, "22222222222"); w.Code != http.StatusUnauthorized {
+		t.Errorf("esperava status 401 para outro CPF, obteve %d", w.Code)
+	}
+}
+
+func TestLoginHandler_AccountLocked(t *testing.T) {
+	service := &mockUserService{
+		authenticateErr: &userService.AccountLockedError{RetryAfter: 90*time.Second + time.Millisecond},
+	}
+
+	router := setupRouter()
+	router.POST("/login", LoginHandler(service, testLimiters(100, 100)))
+
+	w := postLogin(router, "11111111111")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("esperava status 429, obteve %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "91" {
+		t.Errorf("esperava Retry-After 91, obteve %q", w.Header().Get("Retry-After"))
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error"] != "conta bloqueada temporariamente" {
+		t.Errorf("esperava erro de conta bloqueada, obteve '%v'", response["error"])
+	}
+}
+
+type mockTokens struct {
+	jwks tokens.JWKS
+}
+
+func (m *mockTokens) Generate(userID int, role string, twoFactor bool) (string, error) {
+	return "", nil
+}
+
+func (m *mockTokens) Validate(token string) (tokens.Claims, error) { return tokens.Claims{}, nil }
+
+func (m *mockTokens) GenerateChallenge(userID int, ttl time.Duration) (string, error) {
+	return "", nil
+}
+
+func (m *mockTokens) ValidateChallenge(token string) (int, error) { return 0, nil }
+
+func (m *mockTokens) JWKS() tokens.JWKS { return m.jwks }
+
+func TestJWKSHandler(t *testing.T) {
+	manager := &mockTokens{jwks: tokens.JWKS{Keys: []tokens.JWK{{KeyType: "OKP", KeyID: "kid-1", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "abc"}}}}
+
+	router := setupRouter()
+	router.GET("/.well-known/jwks.json", JWKSHandler(manager))
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	var response tokens.JWKS
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("JWKS deveria ser publicado sem envelope: %v", err)
+	}
+	if len(response.Keys) != 1 || response.Keys[0].KeyID != "kid-1" {
+		t.Errorf("JWKS inesperado: %s", w.Body.String())
+	}
+}
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func setupChangePasswordRouter(service *mockUserService) *gin.Engine {
+	router := setupRouter()
+	router.POST("/password", func(c *gin.Context) {
+		c.Set("user_id", 5)
+		c.Next()
+	}, ChangePasswordHandler(service))
+	return router
+}
+
+func TestChangePasswordHandler_Success(t *testing.T) {
+	service := &mockUserService{}
+	router := setupChangePasswordRouter(service)
+
+	w := postJSON(router, "/password", `{"current_password":"senha123","new_password":"novaSenha1"}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	if service.changedUser != 5 {
+		t.Errorf("esperava troca para o usuário 5, obteve %d", service.changedUser)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if data := response["data"].(map[string]interface{}); data["token"] != "new-token" {
+		t.Errorf("esperava novo token, obteve %v", data["token"])
+	}
+}
+
+func TestChangePasswordHandler_Errors(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		serviceErr   error
+		expectedCode int
+	}{
+		{"missing fields", `{"current_password":"senha123"}`, nil, http.StatusBadRequest},
+		{"wrong current password", `{"current_password":"x","new_password":"novaSenha1"}`, errors.New("senha atual incorreta"), http.StatusUnauthorized},
+		{"same password", `{"current_password":"senha123","new_password":"senha123"}`, errors.New("nova senha deve ser diferente da atual"), http.StatusBadRequest},
+		{"weak password", `{"current_password":"senha123","new_password":"abc"}`, &passwords.PolicyError{Violations: []string{"deve conter um número"}}, http.StatusBadRequest},
+		{"unexpected error", `{"current_password":"senha123","new_password":"novaSenha1"}`, errors.New("erro no banco"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setupChangePasswordRouter(&mockUserService{changeErr: tc.serviceErr})
+
+			w := postJSON(router, "/password", tc.body)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("esperava status %d, obteve %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequestPasswordResetHandler(t *testing.T) {
+	service := &mockUserService{}
+	router := setupRouter()
+	router.POST("/reset-request", RequestPasswordResetHandler(service))
+
+	w := postJSON(router, "/reset-request", `{"cpf":"12345678901"}`)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	if service.resetRequestedCPF != "12345678901" {
+		t.Errorf("esperava redefinição para o CPF enviado, obteve %q", service.resetRequestedCPF)
+	}
+
+	if w := postJSON(router, "/reset-request", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("esperava status 400 sem CPF, obteve %d", w.Code)
+	}
+}
+
+func TestResetPasswordHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		serviceErr   error
+		expectedCode int
+	}{
+		{"success", `{"token":"abc","password":"novaSenha1"}`, nil, http.StatusOK},
+		{"missing token", `{"password":"novaSenha1"}`, nil, http.StatusBadRequest},
+		{"invalid token", `{"token":"abc","password":"novaSenha1"}`, errors.New("token de redefinição inválido ou expirado"), http.StatusBadRequest},
+		{"weak password", `{"token":"abc","password":"abc"}`, &passwords.PolicyError{Violations: []string{"deve conter um número"}}, http.StatusBadRequest},
+		{"unexpected error", `{"token":"abc","password":"novaSenha1"}`, errors.New("erro no banco"), http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setupRouter()
+			router.POST("/reset", ResetPasswordHandler(&mockUserService{resetErr: tc.serviceErr}))
+
+			w := postJSON(router, "/reset", tc.body)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("esperava status %d, obteve %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
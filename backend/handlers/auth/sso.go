@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ssoStateCookie ties a login to the browser that started it, so a state
+// obtained by someone else can't complete a login in the victim's browser.
+const (
+	ssoStateCookie     = "oidc_state"
+	ssoStateCookiePath = "/api/auth/oidc"
+)
+
+func setSSOStateCookie(c *gin.Context, state string, maxAge int, secure bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    state,
+		Path:     ssoStateCookiePath,
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// SSOLoginHandler answers with the identity provider URL the frontend must
+// redirect the browser to, and sets the state cookie; secureCookie adds the
+// Secure attribute to it.
+func SSOLoginHandler(ssoService user.SSOService, secureCookie bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authURL, state, err := ssoService.StartLogin(c.Request.Context())
+		if err != nil {
+			if err.Error() == "provedor de identidade indisponível" {
+				utils.RespondError(c, http.StatusBadGateway, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		setSSOStateCookie(c, state, 0, secureCookie)
+		utils.RespondSuccess(c, gin.H{"authorization_url": authURL})
+	}
+}
+
+// SSOCallbackHandler receives the code and state the provider appended to the
+// redirect URL, forwarded by the frontend with its cookies, and answers like
+// LoginHandler. The state must match the cookie set by SSOLoginHandler.
+func SSOCallbackHandler(ssoService user.SSOService, secureCookie bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Code  string `json:"code"`
+			State string `json:"state"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" || req.State == "" {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+
+		cookie, _ := c.Cookie(ssoStateCookie)
+		setSSOStateCookie(c, "", -1, secureCookie)
+		if subtle.ConstantTimeCompare([]byte(cookie), []byte(req.State)) != 1 {
+			utils.RespondError(c, http.StatusUnauthorized, "login SSO inválido ou expirado")
+			return
+		}
+
+		token, user, err := ssoService.CompleteLogin(c.Request.Context(), req.Code, req.State)
+		if err != nil {
+			if err.Error() == "login SSO inválido ou expirado" || err.Error() == "falha na autenticação com o provedor de identidade" || err.Error() == "provedor de identidade não informou um CPF válido" {
+				utils.RespondError(c, http.StatusUnauthorized, err.Error())
+			} else if err.Error() == "usuário não cadastrado" || err.Error() == "conta desativada" {
+				utils.RespondError(c, http.StatusForbidden, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, gin.H{
+			"token": token,
+			"name":  user.Name,
+			"cpf":   user.CPF,
+			"role":  user.Role,
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockSSOService struct {
+	startErr    error
+	completeErr error
+	code, state string
+}
+
+func (m *mockSSOService) StartLogin(ctx context.Context) (string, string, error) {
+	if m.startErr != nil {
+		return "", "", m.startErr
+	}
+	return "https://idp.exemplo/authorize?state=abc", "abc", nil
+}
+
+func (m *mockSSOService) CompleteLogin(ctx context.Context, code, state string) (string, *models.User, error) {
+	m.code, m.state = code, state
+	if m.completeErr != nil {
+		return "", nil, m.completeErr
+	}
+	return "jwt", &models.User{ID: 7, Name: "Maria", CPF: "12345678901", Role: models.RoleAssociate}, nil
+}
+
+func TestSSOLoginHandler(t *testing.T) {
+	router := setupRouter()
+	router.GET("/oidc/login", SSOLoginHandler(&mockSSOService{}, true))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/oidc/login", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != ssoStateCookie || cookies[0].Value != "abc" || !cookies[0].HttpOnly || !cookies[0].Secure || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie de estado inesperado: %+v", cookies)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if data := response["data"].(map[string]interface{}); data["authorization_url"] != "https://idp.exemplo/authorize?state=abc" {
+		t.Errorf("resposta inesperada: %v", data)
+	}
+}
+
+func TestSSOLoginHandler_ProviderUnavailable(t *testing.T) {
+	router := setupRouter()
+	router.GET("/oidc/login", SSOLoginHandler(&mockSSOService{startErr: errors.New("provedor de identidade indisponível")}, false))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/oidc/login", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("esperava status 502, obteve %d", w.Code)
+	}
+}
+
+func TestSSOCallbackHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		cookie       string
+		serviceErr   error
+		expectedCode int
+	}{
+		{"success", `{"code":"codigo","state":"estado"}`, "estado", nil, http.StatusOK},
+		{"missing state", `{"code":"codigo"}`, "estado", nil, http.StatusBadRequest},
+		{"missing cookie", `{"code":"codigo","state":"estado"}`, "", nil, http.StatusUnauthorized},
+		{"state from another browser", `{"code":"codigo","state":"estado"}`, "outro", nil, http.StatusUnauthorized},
+		{"expired state", `{"code":"codigo","state":"velho"}`, "velho", errors.New("login SSO inválido ou expirado"), http.StatusUnauthorized},
+		{"provider refused", `{"code":"codigo","state":"estado"}`, "estado", errors.New("falha na autenticação com o provedor de identidade"), http.StatusUnauthorized},
+		{"unknown user", `{"code":"codigo","state":"estado"}`, "estado", errors.New("usuário não cadastrado"), http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockSSOService{completeErr: tc.serviceErr}
+			router := setupRouter()
+			router.POST("/oidc/callback", SSOCallbackHandler(service, false))
+
+			req, _ := http.NewRequest("POST", "/oidc/callback", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: ssoStateCookie, Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("esperava status %d, obteve %d", tc.expectedCode, w.Code)
+			}
+			if tc.expectedCode == http.StatusUnauthorized && tc.serviceErr == nil && service.code != "" {
+				t.Error("um estado sem o cookie correspondente não deveria chegar ao serviço")
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+				t.Errorf("esperava o cookie de estado removido, obteve %+v", cookies)
+			}
+			if service.code != "codigo" || service.state != "estado" {
+				t.Errorf("parâmetros repassados incorretamente: %q %q", service.code, service.state)
+			}
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if data := response["data"].(map[string]interface{}); data["token"] != "jwt" || data["cpf"] != "12345678901" {
+				t.Errorf("resposta inesperada: %v", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"desafio-tecnico-fullstack/backend/ratelimit"
+	"desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorLoginHandler completes a login that LoginHandler answered with a
+// challenge token, given a TOTP or recovery code.
+func TwoFactorLoginHandler(userService user.UserService, limiters LoginLimiters) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !allow(c, limiters.ByIP, "login_ip", c.ClientIP()) {
+			return
+		}
+
+		var req struct {
+			ChallengeToken string `json:"challenge_token"`
+			Code           string `json:"code"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		if req.ChallengeToken == "" || req.Code == "" {
+			utils.RespondError(c, http.StatusBadRequest, "campos obrigatórios não preenchidos")
+			return
+		}
+
+		var locked *user.AccountLockedError
+		token, user, err := userService.VerifyTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code)
+		if err != nil {
+			if errors.As(err, &locked) {
+				respondTooManyRequests(c, ratelimit.Result{RetryAfter: locked.RetryAfter}, err.Error())
+			} else if err.Error() == "desafio inválido ou expirado" || err.Error() == "código inválido" {
+				utils.RespondError(c, http.StatusUnauthorized, err.Error())
+			} else if err.Error() == "conta desativada" {
+				utils.RespondError(c, http.StatusForbidden, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, gin.H{
+			"token": token,
+			"name":  user.Name,
+			"cpf":   user.CPF,
+			"role":  user.Role,
+		})
+	}
+}
+
+func EnrollTOTPHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enrollment, err := userService.EnrollTOTP(c.Request.Context(), c.GetInt("user_id"))
+		if err != nil {
+			if err.Error() == "verificação em duas etapas já está ativada" {
+				utils.RespondError(c, http.StatusConflict, err.Error())
+			} else if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, enrollment)
+	}
+}
+
+func ConfirmTOTPHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+
+		codes, err := userService.ConfirmTOTP(c.Request.Context(), c.GetInt("user_id"), req.Code)
+		if err != nil {
+			if err.Error() == "verificação em duas etapas já está ativada" {
+				utils.RespondError(c, http.StatusConflict, err.Error())
+			} else if err.Error() == "código inválido" || err.Error() == "verificação em duas etapas não iniciada" {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, gin.H{"recovery_codes": codes})
+	}
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"desafio-tecnico-fullstack/backend/models"
+	userService "desafio-tecnico-fullstack/backend/services/user"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginHandler_TwoFactorChallenge(t *testing.T) {
+	service := &mockUserService{
+		authenticateErr: &userService.TwoFactorRequiredError{ChallengeToken: "desafio", ExpiresIn: 5 * time.Minute},
+	}
+	router := setupRouter()
+	router.POST("/login", LoginHandler(service, testLimiters(10, 10)))
+
+	w := postLogin(router, "12345678901")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	if data["two_factor_required"] != true || data["challenge_token"] != "desafio" || data["expires_in"] != float64(300) {
+		t.Errorf("resposta de desafio inesperada: %v", data)
+	}
+	if _, ok := data["token"]; ok {
+		t.Error("não deveria emitir token antes da segunda etapa")
+	}
+}
+
+func TestTwoFactorLoginHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		serviceErr   error
+		expectedCode int
+	}{
+		{"success", `{"challenge_token":"desafio","code":"123456"}`, nil, http.StatusOK},
+		{"missing code", `{"challenge_token":"desafio"}`, nil, http.StatusBadRequest},
+		{"wrong code", `{"challenge_token":"desafio","code":"000000"}`, errors.New("código inválido"), http.StatusUnauthorized},
+		{"expired challenge", `{"challenge_token":"velho","code":"123456"}`, errors.New("desafio inválido ou expirado"), http.StatusUnauthorized},
+		{"locked", `{"challenge_token":"desafio","code":"123456"}`, &userService.AccountLockedError{RetryAfter: time.Minute}, http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockUserService{
+				verifyErr:         tc.serviceErr,
+				authenticateToken: "jwt",
+				authenticateUser:  &models.User{ID: 1, Name: "Admin", CPF: "12345678901", Role: models.RoleAdmin},
+			}
+			router := setupRouter()
+			router.POST("/login/2fa", TwoFactorLoginHandler(service, testLimiters(10, 10)))
+
+			w := postJSON(router, "/login/2fa", tc.body)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("esperava status %d, obteve %d", tc.expectedCode, w.Code)
+			}
+			if tc.expectedCode == http.StatusOK {
+				var response map[string]interface{}
+				json.Unmarshal(w.Body.Bytes(), &response)
+				if data := response["data"].(map[string]interface{}); data["token"] != "jwt" || data["role"] != models.RoleAdmin {
+					t.Errorf("resposta inesperada: %v", data)
+				}
+			}
+		})
+	}
+}
+
+func setupTOTPRouter(service *mockUserService) *gin.Engine {
+	router := setupRouter()
+	withUser := func(c *gin.Context) {
+		c.Set("user_id", 1)
+		c.Next()
+	}
+	router.POST("/2fa/enroll", withUser, EnrollTOTPHandler(service))
+	router.POST("/2fa/confirm", withUser, ConfirmTOTPHandler(service))
+	return router
+}
+
+func TestEnrollTOTPHandler(t *testing.T) {
+	w := postJSON(setupTOTPRouter(&mockUserService{}), "/2fa/enroll", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if data := response["data"].(map[string]interface{}); data["secret"] != "SEGREDO" || data["otpauth_uri"] != "otpauth://totp/x" {
+		t.Errorf("resposta inesperada: %v", data)
+	}
+
+	service := &mockUserService{enrollErr: errors.New("verificação em duas etapas já está ativada")}
+	if w := postJSON(setupTOTPRouter(service), "/2fa/enroll", ""); w.Code != http.StatusConflict {
+		t.Errorf("esperava status 409, obteve %d", w.Code)
+	}
+}
+
+func TestConfirmTOTPHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		serviceErr   error
+		expectedCode int
+	}{
+		{"success", `{"code":"123456"}`, nil, http.StatusOK},
+		{"missing code", `{}`, nil, http.StatusBadRequest},
+		{"wrong code", `{"code":"000000"}`, errors.New("código inválido"), http.StatusBadRequest},
+		{"not enrolled", `{"code":"123456"}`, errors.New("verificação em duas etapas não iniciada"), http.StatusBadRequest},
+		{"already enabled", `{"code":"123456"}`, errors.New("verificação em duas etapas já está ativada"), http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := postJSON(setupTOTPRouter(&mockUserService{confirmErr: tc.serviceErr}), "/2fa/confirm", tc.body)
+
+			if w.Code != tc.expectedCode {
+				t.Errorf("esperava status %d, obteve %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package health
+
+import (
+	"desafio-tecnico-fullstack/backend/services/health"
+	"desafio-tecnico-fullstack/backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		utils.RespondSuccess(c, gin.H{"status": health.StatusUp})
+	}
+}
+
+func ReadyzHandler(healthService health.HealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ready, checks := healthService.Readiness(c.Request.Context())
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, utils.APIResponse{
+				Status:    "error",
+				Data:      gin.H{"checks": checks},
+				Error:     "serviço indisponível",
+				RequestID: c.GetString("request_id"),
+			})
+			return
+		}
+		utils.RespondSuccess(c, gin.H{"checks": checks})
+	}
+}
@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/services/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHealthService struct {
+	ready  bool
+	checks map[string]health.CheckResult
+}
+
+func (m *mockHealthService) Readiness(ctx context.Context) (bool, map[string]health.CheckResult) {
+	return m.ready, m.checks
+}
+
+func setupTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return gin.New()
+}
+
+func TestHealthzHandler(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/healthz", HealthzHandler())
+
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestReadyzHandler_Ready(t *testing.T) {
+	service := &mockHealthService{
+		ready:  true,
+		checks: map[string]health.CheckResult{"database": {Status: health.StatusUp}},
+	}
+	router := setupTestRouter()
+	router.GET("/readyz", ReadyzHandler(service))
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	checks := response["data"].(map[string]interface{})["checks"].(map[string]interface{})
+	assert.Equal(t, "up", checks["database"].(map[string]interface{})["status"])
+}
+
+func TestReadyzHandler_NotReady(t *testing.T) {
+	service := &mockHealthService{
+		ready: false,
+		checks: map[string]health.CheckResult{
+			"database":   {Status: health.StatusDown, Error: "connection refused"},
+			"migrations": {Status: health.StatusUp},
+		},
+	}
+	router := setupTestRouter()
+	router.GET("/readyz", ReadyzHandler(service))
+
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, "error", response["status"])
+	checks := response["data"].(map[string]interface{})["checks"].(map[string]interface{})
+	assert.Equal(t, "connection refused", checks["database"].(map[string]interface{})["error"])
+}
@@ -1,8 +1,11 @@
 package session
 
 import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
 	"desafio-tecnico-fullstack/backend/services/session"
 	"desafio-tecnico-fullstack/backend/utils"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -23,8 +26,8 @@ func OpenSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
 		if err := c.ShouldBindJSON(&req); err != nil {
 			req.DurationMinutes = 1
 		}
-		
-		err = sessionService.OpenSession(topicID, req.DurationMinutes)
+
+		err = sessionService.OpenSession(c.Request.Context(), topicID, req.DurationMinutes)
 		if err != nil {
 			utils.RespondError(c, http.StatusBadRequest, err.Error())
 			return
@@ -32,3 +35,102 @@ func OpenSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
 		utils.RespondSuccess(c, nil)
 	}
 }
+
+func GetSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicID, err := strconv.Atoi(c.Param("topic_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "topic_id inválido")
+			return
+		}
+		details, err := sessionService.GetSessionDetails(c.Request.Context(), topicID)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		utils.RespondSuccess(c, details)
+	}
+}
+
+func ExtendSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicID, err := strconv.Atoi(c.Param("topic_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "topic_id inválido")
+			return
+		}
+		var req struct {
+			Minutes int `json:"minutes"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+
+		s, err := sessionService.ExtendSession(c.Request.Context(), topicID, c.GetInt("user_id"), req.Minutes)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		utils.RespondSuccess(c, s)
+	}
+}
+
+func CloseSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return sessionActionHandler(sessionService.CloseSession)
+}
+
+func PauseSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return sessionActionHandler(sessionService.PauseSession)
+}
+
+func ResumeSessionHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return sessionActionHandler(sessionService.ResumeSession)
+}
+
+func ListSessionEventsHandler(sessionService session.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicID, err := strconv.Atoi(c.Param("topic_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "topic_id inválido")
+			return
+		}
+		events, err := sessionService.ListSessionEvents(c.Request.Context(), topicID)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		utils.RespondSuccess(c, events)
+	}
+}
+
+func sessionActionHandler(action func(ctx context.Context, topicID int, userID int) (*models.Session, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		topicID, err := strconv.Atoi(c.Param("topic_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "topic_id inválido")
+			return
+		}
+		s, err := action(c.Request.Context(), topicID, c.GetInt("user_id"))
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		utils.RespondSuccess(c, s)
+	}
+}
+
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, session.ErrSessionNotFound):
+		utils.RespondError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, session.ErrSessionClosed),
+		errors.Is(err, session.ErrSessionPaused),
+		errors.Is(err, session.ErrSessionNotPaused):
+		utils.RespondError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, session.ErrInvalidExtension):
+		utils.RespondError(c, http.StatusBadRequest, err.Error())
+	default:
+		utils.RespondError(c, http.StatusInternalServerError, err.Error())
+	}
+}
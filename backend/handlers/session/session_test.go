@@ -2,6 +2,7 @@ package session
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/services/session"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -19,20 +21,59 @@ type mockSessionService struct {
 	getSession *models.Session
 	getErr     error
 	updateErr  error
+	details    *models.SessionDetails
+	actionErr  error
+	events     []models.SessionEvent
+	lastUserID int
 }
 
-func (m *mockSessionService) OpenSession(topicID int, durationMinutes int) error {
+func (m *mockSessionService) OpenSession(ctx context.Context, topicID int, durationMinutes int) error {
 	return m.openErr
 }
 
-func (m *mockSessionService) GetSessionByTopic(topicID int) (*models.Session, error) {
+func (m *mockSessionService) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
 	return m.getSession, m.getErr
 }
 
-func (m *mockSessionService) UpdateExpiredSessions() error {
+func (m *mockSessionService) GetSessionDetails(ctx context.Context, topicID int) (*models.SessionDetails, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.details, nil
+}
+
+func (m *mockSessionService) UpdateExpiredSessions(ctx context.Context) error {
 	return m.updateErr
 }
 
+func (m *mockSessionService) ExtendSession(ctx context.Context, topicID int, userID int, minutes int) (*models.Session, error) {
+	return m.action(userID)
+}
+
+func (m *mockSessionService) CloseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return m.action(userID)
+}
+
+func (m *mockSessionService) PauseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return m.action(userID)
+}
+
+func (m *mockSessionService) ResumeSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return m.action(userID)
+}
+
+func (m *mockSessionService) ListSessionEvents(ctx context.Context, topicID int) ([]models.SessionEvent, error) {
+	return m.events, m.actionErr
+}
+
+func (m *mockSessionService) action(userID int) (*models.Session, error) {
+	m.lastUserID = userID
+	if m.actionErr != nil {
+		return nil, m.actionErr
+	}
+	return m.getSession, nil
+}
+
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -319,3 +360,146 @@ func TestOpenSessionHandler_DatabaseErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestExtendSessionHandler_Success(t *testing.T) {
+	service := &mockSessionService{
+		getSession: &models.Session{ID: 1, TopicID: 1, CloseAt: 2000, Status: models.SessionStatusOpen},
+	}
+	router := setupTestRouter()
+
+	router.POST("/api/topics/:topic_id/session/extend", func(c *gin.Context) {
+		c.Set("user_id", 9)
+		ExtendSessionHandler(service)(c)
+	})
+
+	jsonBody, _ := json.Marshal(map[string]int{"minutes": 5})
+	req, _ := http.NewRequest("POST", "/api/topics/1/session/extend", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 9, service.lastUserID)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, "success", response["status"])
+	assert.Equal(t, float64(2000), response["data"].(map[string]interface{})["close_at"])
+}
+
+func TestExtendSessionHandler_InvalidJSON(t *testing.T) {
+	service := &mockSessionService{}
+	router := setupTestRouter()
+
+	router.POST("/api/topics/:topic_id/session/extend", ExtendSessionHandler(service))
+
+	req, _ := http.NewRequest("POST", "/api/topics/1/session/extend", bytes.NewBuffer([]byte("invalid json")))
+	req.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestSessionActionHandlers_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{"not found", session.ErrSessionNotFound, http.StatusNotFound},
+		{"closed", session.ErrSessionClosed, http.StatusConflict},
+		{"paused", session.ErrSessionPaused, http.StatusConflict},
+		{"not paused", session.ErrSessionNotPaused, http.StatusConflict},
+		{"database", errors.New("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockSessionService{actionErr: tt.err}
+			router := setupTestRouter()
+
+			router.POST("/api/topics/:topic_id/session/close", CloseSessionHandler(service))
+			router.POST("/api/topics/:topic_id/session/pause", PauseSessionHandler(service))
+			router.POST("/api/topics/:topic_id/session/resume", ResumeSessionHandler(service))
+
+			for _, action := range []string{"close", "pause", "resume"} {
+				req, _ := http.NewRequest("POST", "/api/topics/1/session/"+action, nil)
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+
+				assert.Equal(t, tt.expectedCode, recorder.Code, action)
+
+				var response map[string]interface{}
+				json.Unmarshal(recorder.Body.Bytes(), &response)
+				assert.Equal(t, tt.err.Error(), response["error"])
+			}
+		})
+	}
+}
+
+func TestListSessionEventsHandler_Success(t *testing.T) {
+	service := &mockSessionService{
+		events: []models.SessionEvent{
+			{ID: 1, SessionID: 1, UserID: 9, Type: models.SessionEventPaused},
+			{ID: 2, SessionID: 1, UserID: 9, Type: models.SessionEventResumed},
+		},
+	}
+	router := setupTestRouter()
+
+	router.GET("/api/topics/:topic_id/session/events", ListSessionEventsHandler(service))
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/session/events", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Len(t, response["data"], 2)
+}
+
+func TestGetSessionHandler_Success(t *testing.T) {
+	service := &mockSessionService{
+		details: &models.SessionDetails{
+			Session:          models.Session{ID: 1, TopicID: 1, Round: 1, OpenAt: 1000, CloseAt: 1300, Status: models.SessionStatusOpen},
+			State:            models.SessionStatusOpen,
+			ServerTime:       1100,
+			RemainingSeconds: 200,
+		},
+	}
+	router := setupTestRouter()
+
+	router.GET("/api/topics/:topic_id/session", GetSessionHandler(service))
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/session", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(1000), data["open_at"])
+	assert.Equal(t, float64(1300), data["close_at"])
+	assert.Equal(t, "open", data["state"])
+	assert.Equal(t, float64(1100), data["server_time"])
+	assert.Equal(t, float64(200), data["remaining_seconds"])
+}
+
+func TestGetSessionHandler_NotFound(t *testing.T) {
+	service := &mockSessionService{getErr: session.ErrSessionNotFound}
+	router := setupTestRouter()
+
+	router.GET("/api/topics/:topic_id/session", GetSessionHandler(service))
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/session", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -25,7 +25,7 @@ func CreateTopicHandler(topicService topic.TopicService) gin.HandlerFunc {
 			return
 		}
 
-		err := topicService.CreateTopic(req.Name, req.Status)
+		err := topicService.CreateTopic(c.Request.Context(), req.Name, req.Status)
 		if err != nil {
 			utils.RespondError(c, http.StatusBadRequest, err.Error())
 			return
@@ -36,7 +36,7 @@ func CreateTopicHandler(topicService topic.TopicService) gin.HandlerFunc {
 
 func ListTopicsHandler(topicService topic.TopicService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		topics, err := topicService.ListTopics()
+		topics, err := topicService.ListTopics(c.Request.Context())
 		if err != nil {
 			utils.RespondError(c, http.StatusInternalServerError, err.Error())
 			return
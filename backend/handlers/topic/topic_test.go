@@ -1,6 +1,7 @@
 package topic
 
 import (
+	"context"
 	"desafio-tecnico-fullstack/backend/models"
 	"encoding/json"
 	"errors"
@@ -18,11 +19,11 @@ type mockTopicService struct {
 	listErr   error
 }
 
-func (m *mockTopicService) CreateTopic(name, status string) error {
+func (m *mockTopicService) CreateTopic(ctx context.Context, name, status string) error {
 	return m.createErr
 }
 
-func (m *mockTopicService) ListTopics() ([]models.Topic, error) {
+func (m *mockTopicService) ListTopics(ctx context.Context) ([]models.Topic, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
 	}
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/services/vote"
+	"desafio-tecnico-fullstack/backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMeHandler returns the authenticated user's profile together with a
+// summary of how they voted.
+func GetMeHandler(userService user.UserService, voteService vote.VoteService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("user_id")
+		profile, err := userService.GetProfile(c.Request.Context(), userID)
+		if err != nil {
+			if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		summary, err := voteService.GetVotingSummary(c.Request.Context(), userID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		utils.RespondSuccess(c, gin.H{
+			"id":                 profile.ID,
+			"name":               profile.Name,
+			"cpf":                profile.CPF,
+			"role":               profile.Role,
+			"two_factor_enabled": profile.TwoFactorEnabled,
+			"voting":             summary,
+		})
+	}
+}
+
+func UpdateMeHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		profile, err := userService.UpdateName(c.Request.Context(), c.GetInt("user_id"), req.Name)
+		if err != nil {
+			if err.Error() == "nome deve ter entre 1 e 100 caracteres" {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, profile)
+	}
+}
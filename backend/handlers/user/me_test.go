@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	userService "desafio-tecnico-fullstack/backend/services/user"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockVoteService struct {
+	summary    models.VotingSummary
+	summaryErr error
+}
+
+func (m *mockVoteService) Vote(ctx context.Context, topicID int, userID int, choice string) error {
+	return nil
+}
+
+func (m *mockVoteService) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
+	return 0, 0, nil
+}
+
+func (m *mockVoteService) GetRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error) {
+	return nil, nil
+}
+
+func (m *mockVoteService) GetVotingSummary(ctx context.Context, userID int) (models.VotingSummary, error) {
+	return m.summary, m.summaryErr
+}
+
+func setupMeRouter(service *mockUserService, votes *mockVoteService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", 7) })
+	router.GET("/me", GetMeHandler(service, votes))
+	router.PATCH("/me", UpdateMeHandler(service))
+	return router
+}
+
+func TestGetMeHandler_Success(t *testing.T) {
+	service := &mockUserService{profile: &userService.Profile{ID: 7, Name: "Maria", CPF: "12345678901", Role: models.RoleAssociate}}
+	votes := &mockVoteService{summary: models.VotingSummary{TotalVotes: 1, Topics: 1, Yes: 1, Recent: []models.VoteRecord{{TopicID: 3, TopicName: "Pauta", Round: 1, Choice: "Sim"}}}}
+	router := setupMeRouter(service, votes)
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data["name"] != "Maria" || body.Data["role"] != models.RoleAssociate {
+		t.Errorf("perfil inesperado: %v", body.Data)
+	}
+	voting, _ := body.Data["voting"].(map[string]any)
+	if voting["total_votes"] != float64(1) {
+		t.Errorf("resumo de votos inesperado: %v", body.Data["voting"])
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Errorf("resposta não deveria conter a senha: %s", w.Body.String())
+	}
+}
+
+func TestGetMeHandler_NotFound(t *testing.T) {
+	service := &mockUserService{profileErr: errors.New("usuário não encontrado")}
+	router := setupMeRouter(service, &mockVoteService{})
+
+	req, _ := http.NewRequest("GET", "/me", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("esperava status 404, obteve %d", w.Code)
+	}
+}
+
+func TestUpdateMeHandler_Success(t *testing.T) {
+	service := &mockUserService{profile: &userService.Profile{ID: 7, Name: "Maria Souza"}}
+	router := setupMeRouter(service, &mockVoteService{})
+
+	req, _ := http.NewRequest("PATCH", "/me", strings.NewReader(`{"name":"Maria Souza"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("esperava status 200, obteve %d", w.Code)
+	}
+	if service.updatedName != "Maria Souza" {
+		t.Errorf("esperava nome repassado ao serviço, obteve %q", service.updatedName)
+	}
+}
+
+func TestUpdateMeHandler_InvalidName(t *testing.T) {
+	service := &mockUserService{updateErr: errors.New("nome deve ter entre 1 e 100 caracteres")}
+	router := setupMeRouter(service, &mockVoteService{})
+
+	req, _ := http.NewRequest("PATCH", "/me", strings.NewReader(`{"name":"  "}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("esperava status 400, obteve %d", w.Code)
+	}
+}
@@ -0,0 +1,205 @@
+package user
+
+import (
+	"desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/utils"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func UnlockUserHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "user_id inválido")
+			return
+		}
+		if err := userService.UnlockUser(c.Request.Context(), userID); err != nil {
+			if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, nil)
+	}
+}
+
+// ListUsersHandler accepts ?search=, matching names or CPF prefixes, and the
+// page and page_size of the results.
+func ListUsersHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var page, pageSize int
+		var err error
+		if v := c.Query("page"); v != "" {
+			if page, err = strconv.Atoi(v); err != nil || page < 1 || page > user.MaxPage {
+				utils.RespondError(c, http.StatusBadRequest, "page inválido")
+				return
+			}
+		}
+		if v := c.Query("page_size"); v != "" {
+			if pageSize, err = strconv.Atoi(v); err != nil || pageSize < 1 {
+				utils.RespondError(c, http.StatusBadRequest, "page_size inválido")
+				return
+			}
+		}
+		result, err := userService.ListUsers(c.Request.Context(), c.Query("search"), page, pageSize)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		utils.RespondSuccess(c, result)
+	}
+}
+
+// SetUserActiveHandler deactivates the user, or reactivates them when active
+// is true.
+func SetUserActiveHandler(userService user.UserService, active bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "user_id inválido")
+			return
+		}
+		if err := userService.SetUserActive(c.Request.Context(), c.GetInt("user_id"), userID, active); err != nil {
+			if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else if err.Error() == "não é possível desativar a própria conta" {
+				utils.RespondError(c, http.StatusConflict, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, nil)
+	}
+}
+
+func ChangeRoleHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "user_id inválido")
+			return
+		}
+		var req struct {
+			Role string `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "requisição inválida")
+			return
+		}
+		if err := userService.ChangeRole(c.Request.Context(), c.GetInt("user_id"), userID, req.Role); err != nil {
+			if err.Error() == "papel inválido" {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else if err.Error() == "não é possível alterar o próprio papel" {
+				utils.RespondError(c, http.StatusConflict, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, nil)
+	}
+}
+
+// ResetUserPasswordHandler answers with the reset token, which the user
+// also receives through the notifier.
+func ResetUserPasswordHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			utils.RespondError(c, http.StatusBadRequest, "user_id inválido")
+			return
+		}
+		ticket, err := userService.AdminResetPassword(c.Request.Context(), c.GetInt("user_id"), userID)
+		if err != nil {
+			if err.Error() == "usuário não encontrado" {
+				utils.RespondError(c, http.StatusNotFound, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, ticket)
+	}
+}
+
+// maxImportSize is far above a CSV with the maximum number of rows.
+const maxImportSize = 5 << 20
+
+// ImportUsersHandler takes the CSV as the "file" field of a multipart form
+// or as the raw body. ?dry_run=true only validates it and ?credentials=
+// chooses between activation tokens, the default, and initial passwords.
+// Invalid rows are answered with 422 and the report in data.
+func ImportUsersHandler(userService user.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := user.ImportOptions{Credentials: c.Query("credentials")}
+		if v := c.Query("dry_run"); v != "" {
+			dryRun, err := strconv.ParseBool(v)
+			if err != nil {
+				utils.RespondError(c, http.StatusBadRequest, "dry_run inválido")
+				return
+			}
+			opts.DryRun = dryRun
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		var file io.Reader = c.Request.Body
+		if c.ContentType() == "multipart/form-data" {
+			header, err := c.FormFile("file")
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					utils.RespondError(c, http.StatusRequestEntityTooLarge, "arquivo muito grande")
+				} else {
+					utils.RespondError(c, http.StatusBadRequest, "campo file é obrigatório")
+				}
+				return
+			}
+			f, err := header.Open()
+			if err != nil {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			defer f.Close()
+			file = f
+		}
+
+		report, err := userService.ImportUsers(c.Request.Context(), c.GetInt("user_id"), file, opts)
+		if err != nil {
+			var importErr *user.ImportError
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &importErr) {
+				utils.RespondErrorWithData(c, http.StatusUnprocessableEntity, err.Error(), importErr.Report)
+			} else if errors.As(err, &tooLarge) {
+				utils.RespondError(c, http.StatusRequestEntityTooLarge, "arquivo muito grande")
+			} else if isImportValidationError(err) {
+				utils.RespondError(c, http.StatusBadRequest, err.Error())
+			} else {
+				utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		utils.RespondSuccess(c, report)
+	}
+}
+
+// isImportValidationError tells the problems with the file itself, as
+// opposed to its rows, from storage failures.
+func isImportValidationError(err error) bool {
+	for _, prefix := range []string{"credentials deve ser", "arquivo vazio", "arquivo sem associados", "CSV inválido", "coluna ", "o arquivo deve ter no máximo"} {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,370 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	userService "desafio-tecnico-fullstack/backend/services/user"
+	"desafio-tecnico-fullstack/backend/tokens"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockUserService struct {
+	unlockErr    error
+	unlockedUser int
+	profile      *userService.Profile
+	profileErr   error
+	updatedName  string
+	updateErr    error
+	page         *userService.UserPage
+	listArgs     []any
+	adminErr     error
+	adminCall    []any
+	importArgs   []any
+	importedFile string
+	importErr    error
+}
+
+func (m *mockUserService) RegisterUser(ctx context.Context, name, cpf, password string) error {
+	return nil
+}
+
+func (m *mockUserService) AuthenticateUser(ctx context.Context, cpf, password string) (string, *models.User, error) {
+	return "", nil, nil
+}
+
+func (m *mockUserService) UnlockUser(ctx context.Context, userID int) error {
+	m.unlockedUser = userID
+	return m.unlockErr
+}
+
+func (m *mockUserService) AuthorizeToken(ctx context.Context, claims tokens.Claims) error {
+	return nil
+}
+
+func (m *mockUserService) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string, twoFactor bool) (string, error) {
+	return "", nil
+}
+
+func (m *mockUserService) RequestPasswordReset(ctx context.Context, cpf string) error {
+	return nil
+}
+
+func (m *mockUserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return nil
+}
+
+func (m *mockUserService) EnrollTOTP(ctx context.Context, userID int) (*userService.TOTPEnrollment, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockUserService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, *models.User, error) {
+	return "", nil, nil
+}
+
+func (m *mockUserService) GetProfile(ctx context.Context, userID int) (*userService.Profile, error) {
+	return m.profile, m.profileErr
+}
+
+func (m *mockUserService) UpdateName(ctx context.Context, userID int, name string) (*userService.Profile, error) {
+	m.updatedName = name
+	if m.updateErr != nil {
+		return nil, m.updateErr
+	}
+	return m.profile, nil
+}
+
+func (m *mockUserService) ListUsers(ctx context.Context, search string, page, pageSize int) (*userService.UserPage, error) {
+	m.listArgs = []any{search, page, pageSize}
+	return m.page, m.adminErr
+}
+
+func (m *mockUserService) SetUserActive(ctx context.Context, adminID, userID int, active bool) error {
+	m.adminCall = []any{adminID, userID, active}
+	return m.adminErr
+}
+
+func (m *mockUserService) ChangeRole(ctx context.Context, adminID, userID int, role string) error {
+	m.adminCall = []any{adminID, userID, role}
+	return m.adminErr
+}
+
+func (m *mockUserService) AdminResetPassword(ctx context.Context, adminID, userID int) (*userService.PasswordResetTicket, error) {
+	m.adminCall = []any{adminID, userID}
+	if m.adminErr != nil {
+		return nil, m.adminErr
+	}
+	return &userService.PasswordResetTicket{Token: "token-de-redefinicao"}, nil
+}
+
+func (m *mockUserService) ImportUsers(ctx context.Context, adminID int, file io.Reader, opts userService.ImportOptions) (*userService.ImportReport, error) {
+	m.importArgs = []any{adminID, opts}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %w", err)
+	}
+	m.importedFile = string(data)
+	if m.importErr != nil {
+		return nil, m.importErr
+	}
+	return &userService.ImportReport{DryRun: opts.DryRun, Created: 1}, nil
+}
+
+func setupRouter(service *mockUserService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", 1) })
+	router.POST("/users/:user_id/unlock", UnlockUserHandler(service))
+	router.GET("/users", ListUsersHandler(service))
+	router.POST("/users/:user_id/deactivate", SetUserActiveHandler(service, false))
+	router.POST("/users/:user_id/activate", SetUserActiveHandler(service, true))
+	router.PUT("/users/:user_id/role", ChangeRoleHandler(service))
+	router.POST("/users/:user_id/password-reset", ResetUserPasswordHandler(service))
+	router.POST("/users/import", ImportUsersHandler(service))
+	return router
+}
+
+func TestUnlockUserHandler_Success(t *testing.T) {
+	service := &mockUserService{}
+	router := setupRouter(service)
+
+	req, _ := http.NewRequest("POST", "/users/7/unlock", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("esperava status 200, obteve %d", w.Code)
+	}
+	if service.unlockedUser != 7 {
+		t.Errorf("esperava desbloqueio do usuário 7, obteve %d", service.unlockedUser)
+	}
+}
+
+func TestUnlockUserHandler_InvalidID(t *testing.T) {
+	router := setupRouter(&mockUserService{})
+
+	req, _ := http.NewRequest("POST", "/users/abc/unlock", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("esperava status 400, obteve %d", w.Code)
+	}
+}
+
+func TestUnlockUserHandler_NotFound(t *testing.T) {
+	router := setupRouter(&mockUserService{unlockErr: errors.New("usuário não encontrado")})
+
+	req, _ := http.NewRequest("POST", "/users/99/unlock", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("esperava status 404, obteve %d", w.Code)
+	}
+}
+
+func TestListUsersHandler(t *testing.T) {
+	lockedUntil := int64(2000)
+	service := &mockUserService{page: &userService.UserPage{Users: []models.User{{ID: 7, Name: "Maria", Password: "hash", FailedLoginAttempts: 3, LockedUntil: &lockedUntil}}, Total: 1, Page: 2, PageSize: 10}}
+	router := setupRouter(service)
+
+	req, _ := http.NewRequest("GET", "/users?search=maria&page=2&page_size=10", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("esperava status 200, obteve %d", w.Code)
+	}
+	if !reflect.DeepEqual(service.listArgs, []any{"maria", 2, 10}) {
+		t.Errorf("argumentos inesperados: %v", service.listArgs)
+	}
+	if strings.Contains(w.Body.String(), "hash") {
+		t.Errorf("resposta não deveria conter o hash da senha: %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "failed_login_attempts") || strings.Contains(w.Body.String(), "locked_until") {
+		t.Errorf("resposta não deveria expor o estado do bloqueio: %s", w.Body.String())
+	}
+}
+
+func TestListUsersHandler_InvalidPage(t *testing.T) {
+	router := setupRouter(&mockUserService{})
+
+	for _, query := range []string{"page=0", "page=abc", "page_size=-1", "page=100001", "page=9223372036854775807"} {
+		req, _ := http.NewRequest("GET", "/users?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: esperava status 400, obteve %d", query, w.Code)
+		}
+	}
+}
+
+func TestSetUserActiveHandler(t *testing.T) {
+	testCases := []struct {
+		path   string
+		err    error
+		status int
+		call   []any
+	}{
+		{"/users/7/deactivate", nil, http.StatusOK, []any{1, 7, false}},
+		{"/users/7/activate", nil, http.StatusOK, []any{1, 7, true}},
+		{"/users/99/deactivate", errors.New("usuário não encontrado"), http.StatusNotFound, []any{1, 99, false}},
+		{"/users/1/deactivate", errors.New("não é possível desativar a própria conta"), http.StatusConflict, []any{1, 1, false}},
+		{"/users/abc/deactivate", nil, http.StatusBadRequest, nil},
+	}
+
+	for _, tc := range testCases {
+		service := &mockUserService{adminErr: tc.err}
+		router := setupRouter(service)
+
+		req, _ := http.NewRequest("POST", tc.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: esperava status %d, obteve %d", tc.path, tc.status, w.Code)
+		}
+		if !reflect.DeepEqual(service.adminCall, tc.call) {
+			t.Errorf("%s: chamada inesperada: %v", tc.path, service.adminCall)
+		}
+	}
+}
+
+func TestChangeRoleHandler(t *testing.T) {
+	testCases := []struct {
+		body   string
+		err    error
+		status int
+	}{
+		{`{"role":"admin"}`, nil, http.StatusOK},
+		{`{"role":"superusuario"}`, errors.New("papel inválido"), http.StatusBadRequest},
+		{`{"role":"admin"}`, errors.New("não é possível alterar o próprio papel"), http.StatusConflict},
+		{`{"role":"admin"}`, errors.New("usuário não encontrado"), http.StatusNotFound},
+		{`{`, nil, http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		router := setupRouter(&mockUserService{adminErr: tc.err})
+
+		req, _ := http.NewRequest("PUT", "/users/7/role", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tc.status {
+			t.Errorf("%s (%v): esperava status %d, obteve %d", tc.body, tc.err, tc.status, w.Code)
+		}
+	}
+}
+
+func TestResetUserPasswordHandler(t *testing.T) {
+	service := &mockUserService{}
+	router := setupRouter(service)
+
+	req, _ := http.NewRequest("POST", "/users/7/password-reset", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "token-de-redefinicao") {
+		t.Errorf("esperava status 200 com o token, obteve %d: %s", w.Code, w.Body.String())
+	}
+	if !reflect.DeepEqual(service.adminCall, []any{1, 7}) {
+		t.Errorf("chamada inesperada: %v", service.adminCall)
+	}
+
+	router = setupRouter(&mockUserService{adminErr: errors.New("usuário não encontrado")})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("esperava status 404, obteve %d", w.Code)
+	}
+}
+
+func TestImportUsersHandler_RawBody(t *testing.T) {
+	service := &mockUserService{}
+	router := setupRouter(service)
+
+	req, _ := http.NewRequest("POST", "/users/import?dry_run=true&credentials=password", strings.NewReader("nome,cpf\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"dry_run":true`) {
+		t.Errorf("esperava status 200 com o relatório, obteve %d: %s", w.Code, w.Body.String())
+	}
+	want := []any{1, userService.ImportOptions{DryRun: true, Credentials: "password"}}
+	if !reflect.DeepEqual(service.importArgs, want) || service.importedFile != "nome,cpf\n" {
+		t.Errorf("chamada inesperada: %v, arquivo %q", service.importArgs, service.importedFile)
+	}
+}
+
+func TestImportUsersHandler_Multipart(t *testing.T) {
+	service := &mockUserService{}
+	router := setupRouter(service)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "associados.csv")
+	part.Write([]byte("nome,cpf\nAna,11111111111\n"))
+	form.Close()
+
+	req, _ := http.NewRequest("POST", "/users/import", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("esperava status 200, obteve %d: %s", w.Code, w.Body.String())
+	}
+	if service.importedFile != "nome,cpf\nAna,11111111111\n" {
+		t.Errorf("arquivo inesperado: %q", service.importedFile)
+	}
+}
+
+func TestImportUsersHandler_Errors(t *testing.T) {
+	report := &userService.ImportReport{Invalid: 1, Rows: []userService.ImportRow{{Line: 2, Status: userService.ImportInvalid, Error: "cpf inválido"}}}
+	testCases := []struct {
+		name       string
+		url        string
+		body       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"invalid dry_run", "/users/import?dry_run=talvez", "", nil, http.StatusBadRequest, "dry_run inválido"},
+		{"invalid rows", "/users/import", "", &userService.ImportError{Report: report}, http.StatusUnprocessableEntity, "cpf inválido"},
+		{"invalid file", "/users/import", "", errors.New("coluna cpf é obrigatória"), http.StatusBadRequest, "coluna cpf é obrigatória"},
+		{"too large", "/users/import", strings.Repeat("a", maxImportSize+1), nil, http.StatusRequestEntityTooLarge, "arquivo muito grande"},
+		{"storage failure", "/users/import", "", errors.New("conexão recusada"), http.StatusInternalServerError, "conexão recusada"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := setupRouter(&mockUserService{importErr: tc.err})
+
+			req, _ := http.NewRequest("POST", tc.url, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus || !strings.Contains(w.Body.String(), tc.wantBody) {
+				t.Errorf("esperava status %d com %q, obteve %d: %s", tc.wantStatus, tc.wantBody, w.Code, w.Body.String())
+			}
+		})
+	}
+}
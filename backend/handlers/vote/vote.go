@@ -32,7 +32,7 @@ func VoteHandler(voteService vote.VoteService) gin.HandlerFunc {
 			return
 		}
 
-		err = voteService.Vote(topicID, userID.(int), req.Choice)
+		err = voteService.Vote(c.Request.Context(), topicID, userID.(int), req.Choice)
 		if err != nil {
 			utils.RespondError(c, http.StatusBadRequest, err.Error())
 			return
@@ -48,11 +48,16 @@ func ResultHandler(voteService vote.VoteService) gin.HandlerFunc {
 			utils.RespondError(c, http.StatusBadRequest, "topic_id inválido")
 			return
 		}
-		yes, no, err := voteService.GetResult(topicID)
+		yes, no, err := voteService.GetResult(c.Request.Context(), topicID)
 		if err != nil {
 			utils.RespondError(c, http.StatusInternalServerError, err.Error())
 			return
 		}
-		utils.RespondSuccess(c, gin.H{"Sim": yes, "Não": no})
+		rounds, err := voteService.GetRoundResults(c.Request.Context(), topicID)
+		if err != nil {
+			utils.RespondError(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		utils.RespondSuccess(c, gin.H{"Sim": yes, "Não": no, "rounds": rounds})
 	}
 }
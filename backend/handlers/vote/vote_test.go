@@ -2,12 +2,15 @@ package vote
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"desafio-tecnico-fullstack/backend/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -17,16 +20,27 @@ type mockVoteService struct {
 	resultYes int
 	resultNo  int
 	resultErr error
+	rounds    []models.RoundResult
+	roundsErr error
+	summary   models.VotingSummary
 }
 
-func (m *mockVoteService) Vote(topicID int, userID int, choice string) error {
+func (m *mockVoteService) Vote(ctx context.Context, topicID int, userID int, choice string) error {
 	return m.voteErr
 }
 
-func (m *mockVoteService) GetResult(topicID int) (yes int, no int, err error) {
+func (m *mockVoteService) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
 	return m.resultYes, m.resultNo, m.resultErr
 }
 
+func (m *mockVoteService) GetRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error) {
+	return m.rounds, m.roundsErr
+}
+
+func (m *mockVoteService) GetVotingSummary(ctx context.Context, userID int) (models.VotingSummary, error) {
+	return m.summary, m.resultErr
+}
+
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -217,6 +231,54 @@ func TestResultHandler_Success(t *testing.T) {
 	assert.Equal(t, float64(5), data["Não"])
 }
 
+func TestResultHandler_RoundResults(t *testing.T) {
+	service := &mockVoteService{
+		resultYes: 4,
+		resultNo:  1,
+		rounds: []models.RoundResult{
+			{SessionID: 1, Round: 1, Yes: 2, No: 2},
+			{SessionID: 3, Round: 2, Yes: 4, No: 1},
+		},
+	}
+	router := setupTestRouter()
+
+	router.GET("/api/topics/:topic_id/result", ResultHandler(service))
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/result", nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	rounds := data["rounds"].([]interface{})
+	assert.Len(t, rounds, 2)
+	second := rounds[1].(map[string]interface{})
+	assert.Equal(t, float64(2), second["round"])
+	assert.Equal(t, float64(4), second["Sim"])
+	assert.Equal(t, float64(1), second["Não"])
+}
+
+func TestResultHandler_RoundResultsError(t *testing.T) {
+	service := &mockVoteService{
+		roundsErr: errors.New("database error"),
+	}
+	router := setupTestRouter()
+
+	router.GET("/api/topics/:topic_id/result", ResultHandler(service))
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/result", nil)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
 func TestResultHandler_InvalidTopicID(t *testing.T) {
 	service := &mockVoteService{}
 	router := setupTestRouter()
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestFromContext_IncludesRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	Setup(&buf, "json", "info")
+
+	ctx := WithRequestID(context.Background(), "abc-123")
+	FromContext(ctx).Info("voto registrado", "topic_id", 1)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("esperava log em JSON, obteve %q: %v", buf.String(), err)
+	}
+	if entry["request_id"] != "abc-123" {
+		t.Errorf("esperava request_id 'abc-123', obteve %v", entry["request_id"])
+	}
+	if entry["msg"] != "voto registrado" {
+		t.Errorf("mensagem incorreta: %v", entry["msg"])
+	}
+}
+
+func TestSetup_RespectsLevel(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	Setup(&buf, "json", "warn")
+
+	slog.Info("ignorado")
+	if buf.Len() != 0 {
+		t.Errorf("não esperava logs abaixo de warn, obteve %q", buf.String())
+	}
+}
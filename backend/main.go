@@ -1,60 +1,263 @@
 package main
 
 import (
+	"desafio-tecnico-fullstack/backend/clock"
 	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/handlers/auth"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/middleware"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/oidc"
+	"desafio-tecnico-fullstack/backend/passwords"
+	"desafio-tecnico-fullstack/backend/ratelimit"
 	"desafio-tecnico-fullstack/backend/routes"
+	healthService "desafio-tecnico-fullstack/backend/services/health"
 	sessionService "desafio-tecnico-fullstack/backend/services/session"
 	topicService "desafio-tecnico-fullstack/backend/services/topic"
 	userService "desafio-tecnico-fullstack/backend/services/user"
 	voteService "desafio-tecnico-fullstack/backend/services/vote"
-	"desafio-tecnico-fullstack/backend/storage/connection"
-	sessionRepo "desafio-tecnico-fullstack/backend/storage/repository/session"
-	topicRepo "desafio-tecnico-fullstack/backend/storage/repository/topic"
-	userRepo "desafio-tecnico-fullstack/backend/storage/repository/user"
-	voteRepo "desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"desafio-tecnico-fullstack/backend/tokens"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"desafio-tecnico-fullstack/backend/workers"
 
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func main() {
-	config.LoadConfig()
+	os.Exit(run())
+}
+
+// run returns the exit code instead of calling os.Exit, so the deferred
+// cleanups (storage, signal handling) run on every path.
+func run() int {
+	if len(os.Args) > 1 && (os.Args[1] == "migrate" || os.Args[1] == "user") {
+		return runStorageCommand(os.Args[1], os.Args[2:])
+	}
 
-	db, err := connection.NewDB()
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Stdout, cfg, err, os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
 	if err != nil {
-		log.Fatalf("Erro ao conectar no banco: %v", err)
+		fmt.Fprintf(os.Stderr, "configuração inválida:\n%v\n", err)
+		return 1
 	}
-	defer db.Close()
+	config.AppConfig = cfg
 
-	userRepository := userRepo.NewUserRepository(db)
-	topicRepository := topicRepo.NewTopicRepository(db)
-	sessionRepository := sessionRepo.NewSessionRepository(db)
-	voteRepository := voteRepo.NewVoteRepository(db)
+	logger.Setup(os.Stdout, config.AppConfig.Log.Format, config.AppConfig.Log.Level)
+	gin.SetMode(gin.ReleaseMode)
 
-	userService := userService.NewUserService(userRepository)
-	sessionService := sessionService.NewSessionService(sessionRepository)
-	topicService := topicService.NewTopicService(topicRepository, sessionService)
-	voteService := voteService.NewVoteService(voteRepository, sessionRepository)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, config.AppConfig.Tracing.Exporter, config.AppConfig.Tracing.ServiceName)
+	if err != nil {
+		slog.Error("erro ao configurar tracing", "error", err)
+		return 1
+	}
 
+	clk := clock.New()
+
+	repos, err := openStorage(ctx, config.AppConfig.Storage.Driver, clk)
+	if err != nil {
+		slog.Error("erro ao conectar no banco", "error", err)
+		return 1
+	}
+	defer repos.close()
+
+	tokenManager, err := tokens.NewManager(config.AppConfig.JWT, clk)
+	if err != nil {
+		slog.Error("erro ao carregar chaves JWT", "error", err)
+		return 1
+	}
+
+	notifier, err := notify.New(config.AppConfig.Notifier)
+	if err != nil {
+		slog.Error("erro ao configurar notificações", "error", err)
+		return 1
+	}
+
+	var ssoService userService.SSOService
+	if oidcCfg := config.AppConfig.OIDC; oidcCfg.Enabled {
+		ssoService = userService.NewSSOService(oidc.New(oidcCfg, clk), repos.oidcStates, repos.users, tokenManager, clk, userService.SSOOptions{
+			CPFClaim:      oidcCfg.CPFClaim,
+			AutoProvision: oidcCfg.AutoProvision,
+			StateTTL:      oidcCfg.StateTTL,
+		})
+	}
+
+	loginCfg := config.AppConfig.Login
+	passwordCfg := config.AppConfig.Password
+	userService := userService.NewUserService(repos.users, repos.passwordResets, repos.twoFactor, repos.unitOfWork, tokenManager, notifier, clk, userService.Options{
+		Lockout: userService.LockoutPolicy{
+			MaxFailures: loginCfg.MaxFailures,
+			Duration:    loginCfg.LockoutDuration,
+			MaxDuration: loginCfg.MaxLockoutDuration,
+		},
+		Password: passwords.Policy{
+			MinLength:     passwordCfg.MinLength,
+			RequireLetter: passwordCfg.RequireLetter,
+			RequireDigit:  passwordCfg.RequireDigit,
+			RequireSymbol: passwordCfg.RequireSymbol,
+		},
+		ResetTokenTTL:      passwordCfg.ResetTokenTTL,
+		ActivationTokenTTL: passwordCfg.ActivationTokenTTL,
+		TwoFactor: userService.TwoFactorOptions{
+			Issuer:       config.AppConfig.TwoFactor.Issuer,
+			ChallengeTTL: config.AppConfig.TwoFactor.ChallengeTTL,
+		},
+	})
+	sessionService := sessionService.NewSessionService(repos.sessions, repos.unitOfWork, clk)
+	topicService := topicService.NewTopicService(repos.topics, sessionService)
+	voteService := voteService.NewVoteService(repos.votes, repos.sessions, repos.unitOfWork, clk)
+
+	expiryWorker := workers.NewSessionExpiryWorker(sessionService, config.AppConfig.Workers.SessionExpiryInterval)
+
+	checks := repos.checks
+	checks["workers"] = func(context.Context) error {
+		if !expiryWorker.Alive() {
+			return errors.New("rotina de expiração de sessões parada")
+		}
+		return nil
+	}
+	healthService := healthService.NewHealthService(checks)
+
+	rateLimitStore := ratelimit.NewMemoryStore()
 	deps := &routes.Services{
 		UserService:    userService,
+		SSOService:     ssoService,
 		TopicService:   topicService,
 		SessionService: sessionService,
 		VoteService:    voteService,
+		HealthService:  healthService,
+		Tokens:         tokenManager,
+		SecureCookies:  config.AppConfig.Security.HeadersProfile == config.SecurityProfileProduction,
+		LoginLimiters: auth.LoginLimiters{
+			ByIP:  ratelimit.NewLimiter(rateLimitStore, "login_ip", ratelimit.Limit{Requests: loginCfg.IPRequests, Window: loginCfg.IPWindow}, clk),
+			ByCPF: ratelimit.NewLimiter(rateLimitStore, "login_cpf", ratelimit.Limit{Requests: loginCfg.CPFRequests, Window: loginCfg.CPFWindow}, clk),
+		},
+	}
+	if rateLimitCfg := config.AppConfig.RateLimit; rateLimitCfg.Enabled {
+		deps.RateLimiters = routes.RateLimiters{
+			Public:        ratelimit.NewLimiter(rateLimitStore, "public", ratelimit.Limit(rateLimitCfg.Public), clk),
+			Authenticated: ratelimit.NewLimiter(rateLimitStore, "authenticated", ratelimit.Limit(rateLimitCfg.Authenticated), clk),
+			Votes:         ratelimit.NewLimiter(rateLimitStore, "votes", ratelimit.Limit(rateLimitCfg.Votes), clk),
+		}
 	}
 
-	router := gin.Default()
-
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:5173", "http://localhost", "http://localhost:80"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	router := gin.New()
+	if err := router.SetTrustedProxies(config.AppConfig.Server.TrustedProxies); err != nil {
+		slog.Error("erro ao configurar proxies confiáveis", "error", err)
+		return 1
+	}
+	router.Use(
+		gin.Recovery(),
+		otelgin.Middleware(config.AppConfig.Tracing.ServiceName),
+		middleware.RequestIDMiddleware(),
+		middleware.RequestLoggerMiddleware(),
+		middleware.MetricsMiddleware(),
+		middleware.SecurityHeadersMiddleware(config.AppConfig.Security.HeadersProfile),
+		middleware.CORSMiddleware(config.AppConfig.CORS),
+	)
 
 	routes.RegisterRoutes(router, deps)
-	router.Run(":8080")
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		expiryWorker.Run(workerCtx)
+	}()
+
+	serverCfg := config.AppConfig.Server
+	srv := &http.Server{
+		Addr:              serverCfg.Address,
+		Handler:           router,
+		ReadTimeout:       serverCfg.ReadTimeout,
+		ReadHeaderTimeout: serverCfg.ReadHeaderTimeout,
+		WriteTimeout:      serverCfg.WriteTimeout,
+		IdleTimeout:       serverCfg.IdleTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("servidor ouvindo", "address", serverCfg.Address)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	failed := false
+	select {
+	case <-ctx.Done():
+		slog.Info("sinal de encerramento recebido, aguardando requisições em andamento")
+	case err := <-serverErr:
+		slog.Error("erro no servidor HTTP", "error", err)
+		failed = true
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverCfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("erro ao encerrar o servidor", "error", err)
+	}
+
+	stopWorkers()
+	wg.Wait()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Error("erro ao enviar traces pendentes", "error", err)
+	}
+	slog.Info("servidor encerrado")
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// runStorageCommand runs the subcommands that only touch the database. They
+// need just the storage settings, so a host without the JWT keys or the
+// other API settings can still migrate the schema.
+func runStorageCommand(command string, args []string) int {
+	cfg, err := config.Read(os.Getenv("CONFIG_FILE"))
+	if cfg != nil {
+		err = errors.Join(err, cfg.ValidateStorage())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuração inválida:\n%v\n", err)
+		return 1
+	}
+	config.AppConfig = cfg
+
+	logger.Setup(os.Stdout, config.AppConfig.Log.Format, config.AppConfig.Log.Level)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if command == "user" {
+		err = runUser(ctx, args)
+	} else {
+		err = runMigrate(ctx, args)
+	}
+	if err != nil {
+		slog.Error("erro ao executar comando", "command", command, "error", err)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "votacao"
+
+const (
+	SessionClosedExpired = "expired"
+	SessionClosedManual  = "manual"
+
+	LoginFailureUnknownUser   = "unknown_user"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureLocked        = "locked"
+	LoginFailureWrongCode     = "wrong_code"
+	LoginFailureSSO           = "sso_rejected"
+	LoginFailureDeactivated   = "deactivated"
+)
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP por rota, método e status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	VotesCast = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "votes_cast_total",
+		Help:      "Votos registrados por pauta e escolha.",
+	}, []string{"topic_id", "choice"})
+
+	SessionsOpened = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_opened_total",
+		Help:      "Sessões (rodadas) de votação abertas.",
+	})
+
+	SessionsClosed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sessions_closed_total",
+		Help:      "Sessões de votação encerradas, por expiração ou manualmente.",
+	}, []string{"reason"})
+
+	LoginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Tentativas de login malsucedidas por motivo.",
+	}, []string{"reason"})
+
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requisições recusadas por limite de taxa, por limitador.",
+	}, []string{"limiter"})
+)
+
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
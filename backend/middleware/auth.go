@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/tokens"
 	"desafio-tecnico-fullstack/backend/utils"
 	"net/http"
 	"strings"
@@ -8,7 +11,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// TokenAuthorizer decides whether a token that passed signature and expiry
+// checks is still honoured, e.g. after its user changed the password.
+type TokenAuthorizer interface {
+	AuthorizeToken(ctx context.Context, claims tokens.Claims) error
+}
+
+// claimsKey holds the validated claims between TokenMiddleware and
+// AuthorizeMiddleware.
+const claimsKey = "token_claims"
+
+// TokenMiddleware checks the bearer token's signature and expiry, which needs
+// no database, and exposes its user. AuthorizeMiddleware must follow it; the
+// per-user rate limiter goes in between, so throttled requests never reach the
+// database.
+func TokenMiddleware(tokens tokens.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
@@ -16,12 +33,56 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 		token := strings.TrimPrefix(authHeader, "Bearer ")
-		userID, err := utils.ValidateJWT(token)
+		claims, err := tokens.Validate(token)
 		if err != nil {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		c.Set("user_id", userID)
+		c.Set(claimsKey, claims)
+		c.Set("user_id", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("two_factor", claims.TwoFactor)
+		c.Next()
+	}
+}
+
+func AuthorizeMiddleware(authorizer TokenAuthorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get(claimsKey)
+		claims, ok := value.(tokens.Claims)
+		if !ok {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if err := authorizer.AuthorizeToken(c.Request.Context(), claims); err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != models.RoleAdmin {
+			utils.RespondError(c, http.StatusForbidden, "acesso restrito a administradores")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// TwoFactorMiddleware admits only tokens issued after a second factor was
+// verified. An administrator without TOTP enrolled can still reach the
+// enrollment routes, then log in again to get such a token.
+func TwoFactorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("two_factor") {
+			utils.RespondError(c, http.StatusForbidden, "verificação em duas etapas obrigatória")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/tokens"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuthorizer struct {
+	err    error
+	claims tokens.Claims
+	calls  int
+}
+
+func (m *mockAuthorizer) AuthorizeToken(ctx context.Context, claims tokens.Claims) error {
+	m.claims = claims
+	m.calls++
+	return m.err
+}
+
+func setupAuthRouter(t *testing.T, authorizer TokenAuthorizer, limits ...gin.HandlerFunc) (*gin.Engine, string) {
+	t.Helper()
+	manager, err := tokens.NewManager(config.JWTConfig{
+		Algorithm: config.JWTAlgorithmHS256,
+		Secret:    "0123456789abcdef0123456789abcdef",
+		Issuer:    "votacao-api",
+		Audience:  "votacao-api",
+		TTL:       time.Hour,
+	}, clock.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := manager.Generate(7, "admin", true)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers := append([]gin.HandlerFunc{TokenMiddleware(manager)}, limits...)
+	handlers = append(handlers, AuthorizeMiddleware(authorizer), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetInt("user_id"), "role": c.GetString("role"), "two_factor": c.GetBool("two_factor")})
+	})
+	router.GET("/me", handlers...)
+	return router, token
+}
+
+func TestAuthMiddlewares_AcceptsAuthorizedToken(t *testing.T) {
+	authorizer := &mockAuthorizer{}
+	router, token := setupAuthRouter(t, authorizer)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":7,"role":"admin","two_factor":true}`, w.Body.String())
+	assert.Equal(t, 7, authorizer.claims.UserID)
+}
+
+func TestAuthMiddlewares_RejectsRevokedToken(t *testing.T) {
+	router, token := setupAuthRouter(t, &mockAuthorizer{err: tokens.ErrInvalidToken})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddlewares_RejectsMissingOrInvalidToken(t *testing.T) {
+	router, _ := setupAuthRouter(t, &mockAuthorizer{})
+
+	for _, header := range []string{"", "Bearer invalido", "Basic abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+	}
+}
+
+func TestAuthMiddlewares_RateLimitRunsBeforeAuthorization(t *testing.T) {
+	authorizer := &mockAuthorizer{}
+	router, token := setupAuthRouter(t, authorizer, RateLimitMiddleware("test", newTestLimiter(1)))
+
+	for _, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code)
+	}
+	assert.Equal(t, 1, authorizer.calls, "requisições limitadas não deveriam consultar o banco")
+}
+
+func TestTwoFactorMiddleware(t *testing.T) {
+	for _, verified := range []bool{true, false} {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/admin", func(c *gin.Context) { c.Set("two_factor", verified) }, TwoFactorMiddleware(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+		if verified {
+			assert.Equal(t, http.StatusOK, w.Code)
+		} else {
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.Contains(t, w.Body.String(), "verificação em duas etapas obrigatória")
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"desafio-tecnico-fullstack/backend/config"
+	"slices"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+func CORSMiddleware(cfg config.CORSConfig) gin.HandlerFunc {
+	corsConfig := cors.Config{
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", RequestIDHeader, "Retry-After", RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader},
+		AllowCredentials: cfg.AllowCredentials,
+	}
+	if slices.Contains(cfg.AllowedOrigins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.AllowedOrigins
+	}
+	return cors.New(corsConfig)
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"desafio-tecnico-fullstack/backend/metrics"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware_RecordsRouteAndStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(MetricsMiddleware())
+	router.GET("/api/topics/:topic_id/result", func(c *gin.Context) {
+		c.Status(http.StatusTeapot)
+	})
+
+	before := testutil.CollectAndCount(metrics.HTTPRequestDuration)
+
+	req, _ := http.NewRequest("GET", "/api/topics/42/result", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := testutil.CollectAndCount(metrics.HTTPRequestDuration); got != before+1 {
+		t.Errorf("esperava uma nova série de latência, obteve %d séries (antes %d)", got, before)
+	}
+
+	observer, err := metrics.HTTPRequestDuration.GetMetricWithLabelValues("GET", "/api/topics/:topic_id/result", "418")
+	if err != nil || observer == nil {
+		t.Errorf("esperava série rotulada pela rota do gin, obteve erro: %v", err)
+	}
+}
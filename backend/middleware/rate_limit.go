@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
+	"desafio-tecnico-fullstack/backend/ratelimit"
+	"desafio-tecnico-fullstack/backend/utils"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// RateLimitMiddleware limits requests per user, or per client IP when the
+// route has no TokenMiddleware before it. name labels the limiter in
+// metrics and logs. Requests go through if the limiter's store fails.
+func RateLimitMiddleware(name string, limiter ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), rateLimitKey(c))
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Warn("erro no limitador de taxa", "limiter", name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(name).Inc()
+			c.Header("Retry-After", strconv.Itoa(max(result.RetryAfterSeconds(), 1)))
+			utils.RespondError(c, http.StatusTooManyRequests, "limite de requisições excedido, tente novamente mais tarde")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitKey keys anonymous callers by ClientIP, which only honours
+// X-Forwarded-For from the proxies in server.trusted_proxies.
+func rateLimitKey(c *gin.Context) string {
+	if _, ok := c.Get("user_id"); ok {
+		return "user:" + strconv.Itoa(c.GetInt("user_id"))
+	}
+	return "ip:" + c.ClientIP()
+}
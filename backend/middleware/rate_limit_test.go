@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/ratelimit"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store indisponível")
+}
+
+func setupRateLimitRouter(limiter ratelimit.Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	withUser := func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set("user_id", map[string]int{"1": 1, "2": 2}[id])
+		}
+		c.Next()
+	}
+	router.GET("/ok", withUser, RateLimitMiddleware("test", limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func newTestLimiter(requests int) ratelimit.Limiter {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	return ratelimit.NewLimiter(ratelimit.NewMemoryStore(), "test", ratelimit.Limit{Requests: requests, Window: time.Minute}, clk)
+}
+
+func rateLimitedRequest(router *gin.Engine, ip, user string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.RemoteAddr = ip + ":1234"
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimitMiddleware_SetsHeadersAndRejects(t *testing.T) {
+	router := setupRateLimitRouter(newTestLimiter(2))
+
+	first := rateLimitedRequest(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", first.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "30", first.Header().Get(RateLimitResetHeader))
+
+	rateLimitedRequest(router, "10.0.0.1", "")
+	denied := rateLimitedRequest(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, denied.Code)
+	assert.Equal(t, "0", denied.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "30", denied.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, rateLimitedRequest(router, "10.0.0.2", "").Code)
+}
+
+func TestRateLimitMiddleware_KeysByUser(t *testing.T) {
+	router := setupRateLimitRouter(newTestLimiter(1))
+
+	assert.Equal(t, http.StatusOK, rateLimitedRequest(router, "10.0.0.1", "1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, rateLimitedRequest(router, "10.0.0.2", "1").Code,
+		"o mesmo usuário deveria ser limitado mesmo em outro IP")
+	assert.Equal(t, http.StatusOK, rateLimitedRequest(router, "10.0.0.1", "2").Code,
+		"outro usuário no mesmo IP não deveria ser limitado")
+}
+
+func TestRateLimitMiddleware_ForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	router := setupRateLimitRouter(newTestLimiter(1))
+	if err := router.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	request := func(remoteIP, forwardedFor string) int {
+		req, _ := http.NewRequest("GET", "/ok", nil)
+		req.RemoteAddr = remoteIP + ":1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.1", "203.0.113.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("192.0.2.1", "203.0.113.2"),
+		"um X-Forwarded-For forjado não deveria renovar o limite")
+
+	assert.Equal(t, http.StatusOK, request("10.0.0.1", "203.0.113.1"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.1", "203.0.113.2"),
+		"clientes atrás do proxy confiável deveriam ter limites próprios")
+}
+
+func TestRateLimitMiddleware_StoreErrorLetsRequestThrough(t *testing.T) {
+	router := setupRateLimitRouter(failingLimiter{})
+
+	recorder := rateLimitedRequest(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get(RateLimitLimitHeader))
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"bytes"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/utils"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), RequestLoggerMiddleware())
+	router.GET("/ok", func(c *gin.Context) {
+		utils.RespondSuccess(c, logger.RequestID(c.Request.Context()))
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		utils.RespondError(c, http.StatusInternalServerError, "database error")
+	})
+	return router
+}
+
+func TestRequestIDMiddleware_KeepsIncomingID(t *testing.T) {
+	router := setupRequestIDRouter()
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "req-42", recorder.Header().Get(RequestIDHeader))
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, "req-42", response["data"])
+}
+
+func TestRequestIDMiddleware_GeneratesIDWhenMissingOrInvalid(t *testing.T) {
+	router := setupRequestIDRouter()
+
+	for _, incoming := range []string{"", "invalid id with spaces", string(make([]byte, 200))} {
+		req, _ := http.NewRequest("GET", "/ok", nil)
+		if incoming != "" {
+			req.Header.Set(RequestIDHeader, incoming)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		generated := recorder.Header().Get(RequestIDHeader)
+		assert.Len(t, generated, 32)
+		assert.NotEqual(t, incoming, generated)
+	}
+}
+
+func TestRequestIDMiddleware_ErrorBodyAndLogIncludeRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	logger.Setup(&buf, "json", "info")
+
+	router := setupRequestIDRouter()
+
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	req.Header.Set(RequestIDHeader, "req-500")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	var response map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, "req-500", response["request_id"])
+	assert.Equal(t, "database error", response["error"])
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ERROR", entry["level"])
+	assert.Equal(t, "req-500", entry["request_id"])
+	assert.Equal(t, float64(500), entry["status"])
+	assert.Contains(t, entry["errors"], "database error")
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"desafio-tecnico-fullstack/backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// securityHeaders lists the response headers sent under each profile. HSTS is
+// only sent in production, where the API is served over HTTPS; in development
+// it would pin browsers to HTTPS on localhost.
+var securityHeaders = map[string]map[string]string{
+	config.SecurityProfileDevelopment: {
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "frame-ancestors 'none'",
+		"X-Frame-Options":         "DENY",
+		"Referrer-Policy":         "strict-origin-when-cross-origin",
+	},
+	config.SecurityProfileProduction: {
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "frame-ancestors 'none'",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "no-referrer",
+	},
+}
+
+func SecurityHeadersMiddleware(profile string) gin.HandlerFunc {
+	headers := securityHeaders[profile]
+	return func(c *gin.Context) {
+		for name, value := range headers {
+			c.Header(name, value)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"desafio-tecnico-fullstack/backend/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSecurityRouter(profile string, cors config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(SecurityHeadersMiddleware(profile), CORSMiddleware(cors))
+	router.GET("/ok", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestSecurityHeadersMiddleware_Development(t *testing.T) {
+	router := setupSecurityRouter(config.SecurityProfileDevelopment, config.Defaults().CORS)
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "frame-ancestors 'none'", recorder.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "strict-origin-when-cross-origin", recorder.Header().Get("Referrer-Policy"))
+	assert.Empty(t, recorder.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeadersMiddleware_Production(t *testing.T) {
+	router := setupSecurityRouter(config.SecurityProfileProduction, config.Defaults().CORS)
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, "max-age=63072000; includeSubDomains", recorder.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "no-referrer", recorder.Header().Get("Referrer-Policy"))
+}
+
+func TestCORSMiddleware_AllowsConfiguredOrigin(t *testing.T) {
+	router := setupSecurityRouter(config.SecurityProfileDevelopment, config.CORSConfig{
+		AllowedOrigins:   []string{"https://votacao.example"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowCredentials: true,
+	})
+
+	req, _ := http.NewRequest("OPTIONS", "/ok", nil)
+	req.Header.Set("Origin", "https://votacao.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "https://votacao.example", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET,POST", recorder.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORSMiddleware_RejectsUnknownOrigin(t *testing.T) {
+	router := setupSecurityRouter(config.SecurityProfileDevelopment, config.CORSConfig{
+		AllowedOrigins: []string{"https://votacao.example"},
+		AllowedMethods: []string{"GET"},
+	})
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.Header.Set("Origin", "https://malicioso.example")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSMiddleware_Wildcard(t *testing.T) {
+	router := setupSecurityRouter(config.SecurityProfileDevelopment, config.CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+	})
+
+	req, _ := http.NewRequest("GET", "/ok", nil)
+	req.Header.Set("Origin", "https://qualquer.example")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
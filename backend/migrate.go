@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+const migrateUsage = "uso: app migrate up|down|status"
+
+// runMigrate implements the "migrate" subcommand against the configured
+// database.
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := openDB(config.AppConfig.Storage.Driver)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := connection.NewMigrator(db, config.AppConfig.Storage.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrateUp(ctx, migrator)
+	case "down":
+		result, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("migração revertida", "version", result.Source.Version, "file", result.Source.Path, "duration", result.Duration.String())
+		return nil
+	case "status":
+		return printMigrationStatus(ctx, migrator)
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func migrateUp(ctx context.Context, migrator *goose.Provider) error {
+	results, err := migrator.Up(ctx)
+	for _, result := range results {
+		if result.Error == nil {
+			slog.Info("migração aplicada", "version", result.Source.Version, "file", result.Source.Path, "duration", result.Duration.String())
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		slog.Info("banco já está na última versão")
+	}
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *goose.Provider) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSÃO\tARQUIVO\tAPLICADA EM")
+	for _, status := range statuses {
+		appliedAt := "pendente"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Source.Version, status.Source.Path, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN round INTEGER NOT NULL DEFAULT 1;
+
+UPDATE sessions s
+SET round = numbered.round
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY topic_id ORDER BY id) AS round
+    FROM sessions
+) numbered
+WHERE s.id = numbered.id;
+
+ALTER TABLE sessions ADD CONSTRAINT sessions_topic_id_round_key UNIQUE (topic_id, round);
+
+ALTER TABLE votes ADD COLUMN session_id INTEGER REFERENCES sessions(id);
+
+UPDATE votes v
+SET session_id = (
+    SELECT s.id FROM sessions s WHERE s.topic_id = v.topic_id ORDER BY s.round LIMIT 1
+);
+
+ALTER TABLE votes ALTER COLUMN session_id SET NOT NULL;
+ALTER TABLE votes DROP CONSTRAINT votes_topic_id_user_id_key;
+ALTER TABLE votes ADD CONSTRAINT votes_session_id_user_id_key UNIQUE (session_id, user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM votes v
+WHERE v.session_id <> (
+    SELECT s.id FROM sessions s WHERE s.topic_id = v.topic_id ORDER BY s.round LIMIT 1
+);
+ALTER TABLE votes DROP CONSTRAINT votes_session_id_user_id_key;
+ALTER TABLE votes ADD CONSTRAINT votes_topic_id_user_id_key UNIQUE (topic_id, user_id);
+ALTER TABLE votes DROP COLUMN session_id;
+ALTER TABLE sessions DROP CONSTRAINT sessions_topic_id_round_key;
+ALTER TABLE sessions DROP COLUMN round;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Earlier rounds that expired on their own still say 'open'; they are closed
+-- so that the index only sees the latest round of each topic.
+UPDATE sessions
+SET status = 'closed', paused_at = NULL
+WHERE status IN ('open', 'paused')
+    AND round < (SELECT MAX(round) FROM sessions s2 WHERE s2.topic_id = sessions.topic_id);
+
+CREATE UNIQUE INDEX sessions_one_active_round_key ON sessions (topic_id) WHERE status IN ('open', 'paused');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX sessions_one_active_round_key;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migrations so the binary applies them by
+// itself. Postgres files live at the root and their SQLite counterparts, with
+// the same versions, under sqlite/.
+package migrations
+
+import "embed"
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
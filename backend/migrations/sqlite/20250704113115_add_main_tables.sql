@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    cpf TEXT UNIQUE NOT NULL,
+    password TEXT NOT NULL
+);
+
+CREATE TABLE topics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL
+);
+
+CREATE TABLE sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    open_at BIGINT NOT NULL,
+    close_at BIGINT NOT NULL
+);
+
+CREATE TABLE votes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    choice TEXT NOT NULL,
+    UNIQUE(topic_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS topics;
+DROP TABLE IF EXISTS users;
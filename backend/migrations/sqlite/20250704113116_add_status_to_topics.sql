@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE topics ADD COLUMN status TEXT NOT NULL DEFAULT 'Aguardando Abertura';
+
+-- +goose Down
+ALTER TABLE topics DROP COLUMN status;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'associado';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN role;
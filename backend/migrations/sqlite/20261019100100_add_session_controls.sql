@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE sessions ADD COLUMN status TEXT NOT NULL DEFAULT 'open';
+ALTER TABLE sessions ADD COLUMN paused_at BIGINT;
+
+CREATE TABLE session_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    type TEXT NOT NULL,
+    close_at BIGINT NOT NULL,
+    created_at BIGINT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS session_events;
+ALTER TABLE sessions DROP COLUMN paused_at;
+ALTER TABLE sessions DROP COLUMN status;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- SQLite cannot add constraints to existing tables, so votes is rebuilt with
+-- the new unique key and the uniqueness of rounds is kept by an index.
+ALTER TABLE sessions ADD COLUMN round INTEGER NOT NULL DEFAULT 1;
+
+UPDATE sessions
+SET round = (
+    SELECT COUNT(*) FROM sessions earlier
+    WHERE earlier.topic_id = sessions.topic_id AND earlier.id <= sessions.id
+);
+
+CREATE UNIQUE INDEX sessions_topic_id_round_key ON sessions (topic_id, round);
+
+CREATE TABLE votes_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    session_id INTEGER NOT NULL REFERENCES sessions(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    choice TEXT NOT NULL,
+    UNIQUE(session_id, user_id)
+);
+
+INSERT INTO votes_new (id, topic_id, session_id, user_id, choice)
+SELECT v.id, v.topic_id,
+    (SELECT s.id FROM sessions s WHERE s.topic_id = v.topic_id ORDER BY s.round LIMIT 1),
+    v.user_id, v.choice
+FROM votes v;
+
+DROP TABLE votes;
+ALTER TABLE votes_new RENAME TO votes;
+
+-- +goose Down
+CREATE TABLE votes_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    topic_id INTEGER NOT NULL REFERENCES topics(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    choice TEXT NOT NULL,
+    UNIQUE(topic_id, user_id)
+);
+
+INSERT INTO votes_old (id, topic_id, user_id, choice)
+SELECT v.id, v.topic_id, v.user_id, v.choice
+FROM votes v
+WHERE v.session_id = (
+    SELECT s.id FROM sessions s WHERE s.topic_id = v.topic_id ORDER BY s.round LIMIT 1
+);
+
+DROP TABLE votes;
+ALTER TABLE votes_old RENAME TO votes;
+
+DROP INDEX sessions_topic_id_round_key;
+ALTER TABLE sessions DROP COLUMN round;
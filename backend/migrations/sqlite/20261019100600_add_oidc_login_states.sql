@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE oidc_login_states (
+    state_hash TEXT PRIMARY KEY,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS oidc_login_states;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deactivated_at INTEGER;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN deactivated_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN weight INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN weight;
+ALTER TABLE users DROP COLUMN email;
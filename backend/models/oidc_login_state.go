@@ -0,0 +1,12 @@
+package models
+
+// OIDCLoginState is a single sign-on login waiting for the provider to send
+// the user back. The state travels through the browser, so only its SHA-256
+// is stored; the nonce and the PKCE verifier never leave the server.
+type OIDCLoginState struct {
+	StateHash    string `json:"-"`
+	Nonce        string `json:"-"`
+	CodeVerifier string `json:"-"`
+	ExpiresAt    int64  `json:"expires_at"`
+	CreatedAt    int64  `json:"created_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// PasswordResetToken stores only the SHA-256 of the token sent to the user, so
+// a leaked table cannot be used to reset passwords.
+type PasswordResetToken struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	TokenHash string `json:"-"`
+	ExpiresAt int64  `json:"expires_at"`
+	UsedAt    *int64 `json:"used_at,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -1,8 +1,24 @@
 package models
 
+const (
+	SessionStatusOpen   = "open"
+	SessionStatusPaused = "paused"
+	SessionStatusClosed = "closed"
+)
+
 type Session struct {
-	ID      int   `json:"id"`
-	TopicID int   `json:"topic_id"`
-	OpenAt  int64 `json:"open_at"`
-	CloseAt int64 `json:"close_at"`
+	ID       int    `json:"id"`
+	TopicID  int    `json:"topic_id"`
+	Round    int    `json:"round"`
+	OpenAt   int64  `json:"open_at"`
+	CloseAt  int64  `json:"close_at"`
+	Status   string `json:"status"`
+	PausedAt *int64 `json:"paused_at,omitempty"`
+}
+
+type SessionDetails struct {
+	Session
+	State            string `json:"state"`
+	ServerTime       int64  `json:"server_time"`
+	RemainingSeconds int64  `json:"remaining_seconds"`
 }
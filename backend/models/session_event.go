@@ -0,0 +1,17 @@
+package models
+
+const (
+	SessionEventExtended = "extended"
+	SessionEventClosed   = "closed"
+	SessionEventPaused   = "paused"
+	SessionEventResumed  = "resumed"
+)
+
+type SessionEvent struct {
+	ID        int    `json:"id"`
+	SessionID int    `json:"session_id"`
+	UserID    int    `json:"user_id"`
+	Type      string `json:"type"`
+	CloseAt   int64  `json:"close_at"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -1,5 +1,12 @@
 package models
 
+const (
+	TopicStatusWaiting = "Aguardando Abertura"
+	TopicStatusOpen    = "Sessão Aberta"
+	TopicStatusPaused  = "Sessão Pausada"
+	TopicStatusClosed  = "Votação Encerrada"
+)
+
 type Topic struct {
 	ID     int    `json:"id"`
 	Name   string `json:"name"`
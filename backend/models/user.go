@@ -1,8 +1,23 @@
 package models
 
+const (
+	RoleAssociate = "associado"
+	RoleAdmin     = "admin"
+)
+
+// DefaultWeight is the quota of associates registered without one.
+const DefaultWeight = 1
+
 type User struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	CPF      string `json:"cpf"`
-	Password string `json:"password"`
+	ID                  int    `json:"id"`
+	Name                string `json:"name"`
+	CPF                 string `json:"cpf"`
+	Email               string `json:"email,omitempty"`
+	Weight              int    `json:"weight"`
+	Password            string `json:"-"`
+	Role                string `json:"role"`
+	FailedLoginAttempts int    `json:"-"`
+	LockedUntil         *int64 `json:"-"`
+	PasswordChangedAt   *int64 `json:"password_changed_at,omitempty"`
+	DeactivatedAt       *int64 `json:"deactivated_at,omitempty"`
 }
@@ -0,0 +1,12 @@
+package models
+
+// UserTOTP is a user's authenticator enrollment. It only protects the login
+// once EnabledAt is set, after the user proves the authenticator works.
+type UserTOTP struct {
+	UserID    int    `json:"user_id"`
+	Secret    string `json:"-"`
+	EnabledAt *int64 `json:"enabled_at,omitempty"`
+	// LastUsedStep is the time step of the last accepted code, so a code
+	// cannot be replayed.
+	LastUsedStep int64 `json:"-"`
+}
@@ -1,8 +1,36 @@
 package models
 
 type Vote struct {
-	ID      int    `json:"id"`
-	TopicID int    `json:"topic_id"`
-	UserID  int    `json:"user_id"`
-	Choice  string `json:"choice"`
+	ID        int    `json:"id"`
+	TopicID   int    `json:"topic_id"`
+	SessionID int    `json:"session_id"`
+	UserID    int    `json:"user_id"`
+	Choice    string `json:"choice"`
+}
+
+type RoundResult struct {
+	SessionID int   `json:"session_id"`
+	Round     int   `json:"round"`
+	OpenAt    int64 `json:"open_at"`
+	CloseAt   int64 `json:"close_at"`
+	Yes       int   `json:"Sim"`
+	No        int   `json:"Não"`
+}
+
+// VotingSummary is a user's participation across every round voted, with the
+// latest votes first in Recent.
+type VotingSummary struct {
+	TotalVotes int          `json:"total_votes"`
+	Topics     int          `json:"topics"`
+	Yes        int          `json:"Sim"`
+	No         int          `json:"Não"`
+	Recent     []VoteRecord `json:"recent"`
+}
+
+type VoteRecord struct {
+	TopicID   int    `json:"topic_id"`
+	TopicName string `json:"topic_name"`
+	Round     int    `json:"round"`
+	Choice    string `json:"choice"`
+	OpenAt    int64  `json:"open_at"`
 }
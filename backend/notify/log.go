@@ -0,0 +1,20 @@
+package notify
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/logger"
+)
+
+// logNotifier records that a notification was due, for development where
+// there is nothing to deliver it to. The token is left out: whoever reads the
+// log could otherwise take over the account.
+type logNotifier struct{}
+
+func (n *logNotifier) Notify(ctx context.Context, msg Notification) error {
+	logger.FromContext(ctx).Info("notificação",
+		"type", msg.Type,
+		"user_id", msg.UserID,
+		"expires_at", msg.ExpiresAt,
+	)
+	return nil
+}
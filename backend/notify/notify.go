@@ -0,0 +1,49 @@
+// Package notify delivers messages to users, such as password reset tokens,
+// through a driver chosen by configuration.
+package notify
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/config"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	TypePasswordReset = "password_reset"
+	// TypeActivation carries the token an imported associate redeems like a
+	// password reset token to choose their first password.
+	TypeActivation = "activation"
+)
+
+type Notification struct {
+	Type      string    `json:"type"`
+	UserID    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	CPF       string    `json:"cpf"`
+	Email     string    `json:"email,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+func New(cfg config.NotifierConfig) (Notifier, error) {
+	switch cfg.Driver {
+	case config.NotifierDriverLog:
+		return &logNotifier{}, nil
+	case config.NotifierDriverWebhook:
+		return &webhookNotifier{url: cfg.WebhookURL, client: &http.Client{Timeout: cfg.WebhookTimeout}}, nil
+	case config.NotifierDriverNone:
+		return noopNotifier{}, nil
+	default:
+		return nil, fmt.Errorf("notificador desconhecido: %q", cfg.Driver)
+	}
+}
+
+type noopNotifier struct{}
+
+func (noopNotifier) Notify(context.Context, Notification) error { return nil }
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/logger"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_PostsJSON(t *testing.T) {
+	var got Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("requisição inesperada: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("corpo inválido: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	n, err := New(config.NotifierConfig{Driver: config.NotifierDriverWebhook, WebhookURL: server.URL, WebhookTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Notification{Type: TypePasswordReset, UserID: 7, Name: "Maria", CPF: "12345678901", Token: "abc", ExpiresAt: time.Unix(1750000000, 0).UTC()}
+	if err := n.Notify(context.Background(), want); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if got != want {
+		t.Errorf("esperava %+v, obteve %+v", want, got)
+	}
+}
+
+func TestWebhookNotifier_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	n, _ := New(config.NotifierConfig{Driver: config.NotifierDriverWebhook, WebhookURL: server.URL, WebhookTimeout: time.Second})
+	if err := n.Notify(context.Background(), Notification{Type: TypePasswordReset}); err == nil {
+		t.Error("esperava erro para resposta 502")
+	}
+}
+
+func TestLogNotifier_OmitsToken(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	logger.Setup(&buf, "json", "info")
+
+	n, _ := New(config.NotifierConfig{Driver: config.NotifierDriverLog})
+	if err := n.Notify(context.Background(), Notification{Type: TypePasswordReset, UserID: 7, Token: "token-secreto"}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if strings.Contains(buf.String(), "token-secreto") {
+		t.Errorf("o token não deveria ir para o log: %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), `"user_id":7`) {
+		t.Errorf("esperava a notificação no log: %s", buf.String())
+	}
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	if _, err := New(config.NotifierConfig{Driver: "smtp"}); err == nil {
+		t.Error("esperava erro para driver desconhecido")
+	}
+}
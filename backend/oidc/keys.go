@@ -0,0 +1,156 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// supportedAlgorithms excludes HS256, which would make the client secret a
+// signing key, and "none".
+var supportedAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// minKeysRefresh bounds how often tokens with an unknown kid can make us fetch
+// the provider's keys again.
+const minKeysRefresh = time.Minute
+
+type keySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// key returns the provider key with the given kid, fetching the key set when
+// it is not known yet: providers publish new keys before signing with them.
+// Tokens without kid are accepted only while the provider has a single key.
+func (p *provider) key(ctx context.Context, metadata *discovery, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys.find(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && p.clock.Now().Sub(p.keysFetch) < minKeysRefresh {
+		return nil, fmt.Errorf("chave %q desconhecida", kid)
+	}
+	keys, err := p.fetchKeys(ctx, metadata.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysFetch = keys, p.clock.Now()
+	if key, ok := p.keys.find(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("chave %q desconhecida", kid)
+}
+
+func (s *keySet) find(kid string) (crypto.PublicKey, bool) {
+	if s == nil {
+		return nil, false
+	}
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (p *provider) fetchKeys(ctx context.Context, jwksURI string) (*keySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	status, err := p.do(req, &set)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("status %d", status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter as chaves do provedor OIDC: %w", err)
+	}
+
+	keys := &keySet{keys: map[string]crypto.PublicKey{}}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Keys of unsupported types are skipped so they do not prevent the
+		// use of the others.
+		if key, err := k.publicKey(); err == nil {
+			keys.keys[k.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("expoente RSA inválido")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curva %q não suportada", k.Curve)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("ponto fora da curva")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("tipo de chave %q não suportado", k.KeyType)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("inteiro base64url inválido")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,231 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE: provider discovery, the authorization
+// URL, the code exchange and the verification of the ID token.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// clockSkew tolerates some drift between our clock and the provider's when
+// checking the ID token lifetime.
+const clockSkew = time.Minute
+
+var ErrInvalidIDToken = errors.New("id token inválido")
+
+// IDToken holds the verified claims of an ID token.
+type IDToken struct {
+	Subject string
+	Claims  map[string]any
+}
+
+// String returns the claim as text, or "" when it is absent. Numeric claims
+// keep their digits, since some providers publish the CPF as a number.
+func (t *IDToken) String(name string) string {
+	switch v := t.Claims[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+type Provider interface {
+	// AuthCodeURL is where the browser is sent to log in. codeChallenge is
+	// derived from the verifier later given to Exchange (see CodeChallenge).
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange redeems the authorization code and returns the ID token once
+	// its signature, issuer, audience, lifetime and nonce are verified.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDToken, error)
+}
+
+// discovery is the part of the provider metadata the flow needs.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type provider struct {
+	cfg    config.OIDCConfig
+	client *http.Client
+	clock  clock.Clock
+
+	// The metadata is fetched on first use, so the API starts even while the
+	// provider is unreachable; the keys are fetched again whenever a token is
+	// signed by an unknown one.
+	mu        sync.Mutex
+	metadata  *discovery
+	keys      *keySet
+	keysFetch time.Time
+}
+
+func New(cfg config.OIDCConfig, clock clock.Clock) Provider {
+	return &provider{cfg: cfg, client: &http.Client{Timeout: cfg.HTTPTimeout}, clock: clock}
+}
+
+// CodeChallenge derives the S256 PKCE challenge sent in the authorization
+// request from the verifier kept by the server.
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("authorization_endpoint inválido: %w", err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	authURL.RawQuery = q.Encode()
+	return authURL.String(), nil
+}
+
+func (p *provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*IDToken, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao trocar o código no provedor OIDC: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("provedor OIDC recusou o código (%d): %s %s", status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: resposta do provedor sem id_token", ErrInvalidIDToken)
+	}
+	return p.verify(ctx, metadata, body.IDToken, nonce)
+}
+
+func (p *provider) verify(ctx context.Context, metadata *discovery, rawToken, nonce string) (*IDToken, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods(supportedAlgorithms), jwt.WithoutClaimsValidation(), jwt.WithJSONNumber())
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, metadata, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	now := p.clock.Now()
+	subject, _ := claims["sub"].(string)
+	tokenNonce, _ := claims["nonce"].(string)
+	switch {
+	case !claims.VerifyIssuer(metadata.Issuer, true):
+		return nil, fmt.Errorf("%w: emissor inesperado", ErrInvalidIDToken)
+	case !claims.VerifyAudience(p.cfg.ClientID, true):
+		return nil, fmt.Errorf("%w: audiência inesperada", ErrInvalidIDToken)
+	case !claims.VerifyExpiresAt(now.Add(-clockSkew).Unix(), true):
+		return nil, fmt.Errorf("%w: expirado", ErrInvalidIDToken)
+	case !claims.VerifyIssuedAt(now.Add(clockSkew).Unix(), true):
+		return nil, fmt.Errorf("%w: emitido no futuro", ErrInvalidIDToken)
+	case tokenNonce == "" || tokenNonce != nonce:
+		return nil, fmt.Errorf("%w: nonce inesperado", ErrInvalidIDToken)
+	case subject == "":
+		return nil, fmt.Errorf("%w: sem sub", ErrInvalidIDToken)
+	}
+	return &IDToken{Subject: subject, Claims: claims}, nil
+}
+
+func (p *provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.IssuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var metadata discovery
+	status, err := p.do(req, &metadata)
+	if err == nil && status != http.StatusOK {
+		err = fmt.Errorf("status %d", status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter a configuração do provedor OIDC: %w", err)
+	}
+	// The issuer must be the one configured, otherwise another provider could
+	// sign tokens accepted here (OpenID Connect Discovery, section 4.3).
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("provedor OIDC informou o emissor %q, esperava %q", metadata.Issuer, p.cfg.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("configuração do provedor OIDC incompleta")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// do sends the request and decodes the JSON response, whatever its status.
+func (p *provider) do(req *http.Request, v any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("resposta inválida: %w", err)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,135 @@
+package oidc
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/oidc/oidctest"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testVerifier = "verificador-pkce-de-teste-com-entropia-suficiente"
+	testNonce    = "nonce-de-teste"
+)
+
+func newTestProvider(t *testing.T) (Provider, *oidctest.Provider) {
+	t.Helper()
+	idp := oidctest.NewProvider(t)
+	p := New(config.OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/login/oidc",
+		Scopes:       []string{"openid", "profile"},
+		HTTPTimeout:  time.Second,
+	}, clock.New())
+	return p, idp
+}
+
+func login(t *testing.T, p Provider, idp *oidctest.Provider, claims map[string]any) (*IDToken, error) {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), "estado", testNonce, CodeChallenge(testVerifier))
+	if err != nil {
+		t.Fatalf("erro ao montar URL de autorização: %v", err)
+	}
+	code, _ := idp.Login(t, authURL, claims)
+	return p.Exchange(context.Background(), code, testVerifier, testNonce)
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "estado", testNonce, CodeChallenge(testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if !strings.HasPrefix(authURL, idp.URL+"/authorize?") {
+		t.Errorf("endpoint de autorização inesperado: %s", authURL)
+	}
+	if q.Get("scope") != "openid profile" || q.Get("state") != "estado" || q.Get("redirect_uri") != "http://localhost:5173/login/oidc" {
+		t.Errorf("parâmetros inesperados: %v", q)
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	token, err := login(t, p, idp, map[string]any{"sub": "abc", "cpf": "12345678901", "name": "Maria"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if token.Subject != "abc" || token.String("cpf") != "12345678901" || token.String("name") != "Maria" {
+		t.Errorf("claims inesperadas: %+v", token)
+	}
+}
+
+func TestProvider_ExchangeNumericClaim(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	token, err := login(t, p, idp, map[string]any{"sub": "abc", "cpf": 12345678901})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if token.String("cpf") != "12345678901" {
+		t.Errorf("esperava CPF numérico como texto, obteve %q", token.String("cpf"))
+	}
+}
+
+func TestProvider_RejectsInvalidIDTokens(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims map[string]any
+	}{
+		{"wrong issuer", map[string]any{"sub": "abc", "iss": "https://outro.exemplo"}},
+		{"wrong audience", map[string]any{"sub": "abc", "aud": "outro-cliente"}},
+		{"expired", map[string]any{"sub": "abc", "exp": time.Now().Add(-time.Hour).Unix()}},
+		{"wrong nonce", map[string]any{"sub": "abc", "nonce": "outro"}},
+		{"missing subject", map[string]any{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, idp := newTestProvider(t)
+
+			_, err := login(t, p, idp, tc.claims)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("esperava ErrInvalidIDToken, obteve %v", err)
+			}
+		})
+	}
+}
+
+func TestProvider_ExchangeRequiresPKCEVerifier(t *testing.T) {
+	p, idp := newTestProvider(t)
+
+	authURL, err := p.AuthCodeURL(context.Background(), "estado", testNonce, CodeChallenge(testVerifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := idp.Login(t, authURL, map[string]any{"sub": "abc"})
+	if _, err := p.Exchange(context.Background(), code, "outro-verificador", testNonce); err == nil {
+		t.Fatal("esperava recusa do provedor com verificador errado")
+	}
+	if _, err := p.Exchange(context.Background(), code, testVerifier, testNonce); err == nil {
+		t.Error("o código não deveria ser aceito duas vezes")
+	}
+}
+
+func TestProvider_RejectsIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewProvider(t)
+	// Same server under another name: the discovery document names 127.0.0.1.
+	issuer := strings.Replace(idp.URL, "127.0.0.1", "localhost", 1)
+	p := New(config.OIDCConfig{IssuerURL: issuer, ClientID: oidctest.ClientID, HTTPTimeout: time.Second}, clock.New())
+
+	_, err := p.AuthCodeURL(context.Background(), "estado", testNonce, "desafio")
+	if err == nil || !strings.Contains(err.Error(), "emissor") {
+		t.Errorf("esperava erro de emissor diferente do configurado, obteve %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+// Package passwords holds the strength policy applied whenever a password is
+// set, kept apart so the configuration can share its limits.
+package passwords
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxLength is the most bcrypt can hash; longer passwords are rejected
+// instead of silently truncated.
+const MaxLength = 72
+
+// Policy is checked whenever a password is set: on registration, on
+// change and on reset.
+type Policy struct {
+	MinLength     int
+	RequireLetter bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// PolicyError lists every rule the password breaks.
+type PolicyError struct {
+	Violations []string
+}
+
+func (e *PolicyError) Error() string {
+	return "senha fraca: " + strings.Join(e.Violations, ", ")
+}
+
+func (p Policy) Check(password string) error {
+	var letter, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			letter = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var violations []string
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("deve ter pelo menos %d caracteres", p.MinLength))
+	}
+	if len(password) > MaxLength {
+		violations = append(violations, fmt.Sprintf("deve ter no máximo %d bytes", MaxLength))
+	}
+	if p.RequireLetter && !letter {
+		violations = append(violations, "deve conter uma letra")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "deve conter um número")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "deve conter um símbolo")
+	}
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
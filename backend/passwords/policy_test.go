@@ -0,0 +1,46 @@
+package passwords
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	policy := Policy{MinLength: 8, RequireLetter: true, RequireDigit: true, RequireSymbol: true}
+
+	testCases := []struct {
+		name     string
+		password string
+		want     []string
+	}{
+		{"valid", "senha-123", nil},
+		{"unicode letters", "ação#2024", nil},
+		{"too short", "a1!", []string{"deve ter pelo menos 8 caracteres"}},
+		{"too long for bcrypt", strings.Repeat("a1!", 25), []string{"deve ter no máximo 72 bytes"}},
+		{"missing everything", "        ", []string{"deve conter uma letra", "deve conter um número", "deve conter um símbolo"}},
+		{"missing symbol", "senha1234", []string{"deve conter um símbolo"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := policy.Check(tc.password)
+			if tc.want == nil {
+				if err != nil {
+					t.Errorf("esperava senha válida, obteve: %v", err)
+				}
+				return
+			}
+			var weak *PolicyError
+			if !errors.As(err, &weak) || strings.Join(weak.Violations, "|") != strings.Join(tc.want, "|") {
+				t.Errorf("esperava violações %v, obteve: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_OptionalRules(t *testing.T) {
+	if err := (Policy{MinLength: 4}).Check("1234"); err != nil {
+		t.Errorf("sem regras de composição qualquer senha longa o bastante vale: %v", err)
+	}
+}
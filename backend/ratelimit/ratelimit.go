@@ -0,0 +1,61 @@
+// Package ratelimit implements token-bucket rate limiting over a pluggable
+// Store, so the buckets can live in memory or in a store shared by several
+// API instances.
+package ratelimit
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"math"
+	"time"
+)
+
+// Limit allows Requests per Window. Tokens are refilled continuously, so a
+// client that waits Window/Requests gets one more request.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until the next request would be allowed; zero
+	// when Allowed.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// RetryAfterSeconds rounds RetryAfter up to whole seconds, as used by the
+// Retry-After header.
+func (r Result) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// Store keeps the buckets. Take must be atomic per key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+type limiter struct {
+	store  Store
+	prefix string
+	limit  Limit
+	clock  clock.Clock
+}
+
+// NewLimiter returns a Limiter whose keys are namespaced by prefix, so several
+// limiters can share a Store.
+func NewLimiter(store Store, prefix string, limit Limit, clock clock.Clock) Limiter {
+	return &limiter{store: store, prefix: prefix, limit: limit, clock: clock}
+}
+
+func (l *limiter) Allow(ctx context.Context, key string) (Result, error) {
+	return l.store.Take(ctx, l.prefix+":"+key, l.limit, l.clock.Now())
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"testing"
+	"time"
+)
+
+func TestLimiter_AllowsBurstThenDenies(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), "login", Limit{Requests: 3, Window: time.Minute}, clk)
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := limiter.Allow(ctx, "1.2.3.4")
+		if err != nil || !result.Allowed {
+			t.Fatalf("esperava requisição permitida, obteve %+v (erro: %v)", result, err)
+		}
+		if result.Remaining != i || result.Limit != 3 {
+			t.Errorf("esperava %d restantes, obteve %+v", i, result)
+		}
+	}
+
+	result, _ := limiter.Allow(ctx, "1.2.3.4")
+	if result.Allowed {
+		t.Fatal("esperava requisição negada")
+	}
+	if result.RetryAfter != 20*time.Second || result.RetryAfterSeconds() != 20 {
+		t.Errorf("esperava nova tentativa em 20s, obteve %v", result.RetryAfter)
+	}
+	if result.ResetAfter != time.Minute {
+		t.Errorf("esperava bucket cheio em 1m, obteve %v", result.ResetAfter)
+	}
+}
+
+func TestLimiter_RefillsOverTime(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	limiter := NewLimiter(NewMemoryStore(), "login", Limit{Requests: 2, Window: time.Minute}, clk)
+	ctx := context.Background()
+
+	limiter.Allow(ctx, "a")
+	limiter.Allow(ctx, "a")
+	if result, _ := limiter.Allow(ctx, "a"); result.Allowed {
+		t.Fatal("esperava requisição negada")
+	}
+
+	clk.Advance(30 * time.Second)
+	if result, _ := limiter.Allow(ctx, "a"); !result.Allowed {
+		t.Fatal("esperava um token após 30s")
+	}
+	if result, _ := limiter.Allow(ctx, "a"); result.Allowed {
+		t.Fatal("esperava apenas um token após 30s")
+	}
+}
+
+func TestLimiter_KeysAndPrefixesAreIndependent(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	store := NewMemoryStore()
+	byIP := NewLimiter(store, "ip", Limit{Requests: 1, Window: time.Minute}, clk)
+	byCPF := NewLimiter(store, "cpf", Limit{Requests: 1, Window: time.Minute}, clk)
+	ctx := context.Background()
+
+	byIP.Allow(ctx, "x")
+	if result, _ := byIP.Allow(ctx, "y"); !result.Allowed {
+		t.Error("chaves diferentes não deveriam compartilhar o limite")
+	}
+	if result, _ := byCPF.Allow(ctx, "x"); !result.Allowed {
+		t.Error("limitadores com prefixos diferentes não deveriam compartilhar o limite")
+	}
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	store := NewMemoryStore().(*memoryStore)
+	now := time.Unix(1000, 0)
+	limit := Limit{Requests: 1, Window: time.Second}
+
+	store.Take(context.Background(), "antigo", limit, now)
+	store.Take(context.Background(), "novo", limit, now.Add(2*time.Minute))
+
+	if _, ok := store.buckets["antigo"]; ok {
+		t.Error("esperava remoção do bucket já recarregado")
+	}
+	if _, ok := store.buckets["novo"]; !ok {
+		t.Error("bucket recente não deveria ser removido")
+	}
+}
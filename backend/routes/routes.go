@@ -2,32 +2,99 @@ package routes
 
 import (
 	"desafio-tecnico-fullstack/backend/handlers/auth"
+	healthhandler "desafio-tecnico-fullstack/backend/handlers/health"
 	sessionhandler "desafio-tecnico-fullstack/backend/handlers/session"
 	topichandler "desafio-tecnico-fullstack/backend/handlers/topic"
+	userhandler "desafio-tecnico-fullstack/backend/handlers/user"
 	votehandler "desafio-tecnico-fullstack/backend/handlers/vote"
 	"desafio-tecnico-fullstack/backend/middleware"
+	"desafio-tecnico-fullstack/backend/ratelimit"
+	"desafio-tecnico-fullstack/backend/services/health"
 	"desafio-tecnico-fullstack/backend/services/session"
 	"desafio-tecnico-fullstack/backend/services/topic"
 	"desafio-tecnico-fullstack/backend/services/user"
 	"desafio-tecnico-fullstack/backend/services/vote"
+	"desafio-tecnico-fullstack/backend/tokens"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Services struct {
-	UserService    user.UserService
+	UserService user.UserService
+	// SSOService is nil when single sign-on is disabled.
+	SSOService     user.SSOService
 	TopicService   topic.TopicService
 	SessionService session.SessionService
 	VoteService    vote.VoteService
+	HealthService  health.HealthService
+	Tokens         tokens.Manager
+	LoginLimiters  auth.LoginLimiters
+	RateLimiters   RateLimiters
+	// SecureCookies marks the cookies the API sets Secure, for deployments
+	// served over HTTPS.
+	SecureCookies bool
+}
+
+// RateLimiters limit each route group; a nil limiter leaves its group
+// unlimited. Public routes are limited per client IP, the others per user.
+type RateLimiters struct {
+	Public        ratelimit.Limiter
+	Authenticated ratelimit.Limiter
+	Votes         ratelimit.Limiter
 }
 
 func RegisterRoutes(router *gin.Engine, deps *Services) {
-	router.POST("/api/auth/register", auth.RegisterHandler(deps.UserService))
-	router.POST("/api/auth/login", auth.LoginHandler(deps.UserService))
-
-	router.POST("/api/topics", middleware.AuthMiddleware(), topichandler.CreateTopicHandler(deps.TopicService))
-	router.GET("/api/topics", topichandler.ListTopicsHandler(deps.TopicService))
-	router.POST("/api/topics/:topic_id/session", middleware.AuthMiddleware(), sessionhandler.OpenSessionHandler(deps.SessionService))
-	router.POST("/api/topics/:topic_id/vote", middleware.AuthMiddleware(), votehandler.VoteHandler(deps.VoteService))
-	router.GET("/api/topics/:topic_id/result", votehandler.ResultHandler(deps.VoteService))
+	router.GET("/healthz", healthhandler.HealthzHandler())
+	router.GET("/readyz", healthhandler.ReadyzHandler(deps.HealthService))
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	router.GET("/.well-known/jwks.json", auth.JWKSHandler(deps.Tokens))
+
+	router.POST("/api/auth/login", auth.LoginHandler(deps.UserService, deps.LoginLimiters))
+	router.POST("/api/auth/login/2fa", auth.TwoFactorLoginHandler(deps.UserService, deps.LoginLimiters))
+
+	public := router.Group("/api", rateLimit("public", deps.RateLimiters.Public))
+	public.POST("/auth/register", auth.RegisterHandler(deps.UserService))
+	public.POST("/auth/password/reset-request", auth.RequestPasswordResetHandler(deps.UserService))
+	public.POST("/auth/password/reset", auth.ResetPasswordHandler(deps.UserService))
+	if deps.SSOService != nil {
+		public.GET("/auth/oidc/login", auth.SSOLoginHandler(deps.SSOService, deps.SecureCookies))
+		public.POST("/auth/oidc/callback", auth.SSOCallbackHandler(deps.SSOService, deps.SecureCookies))
+	}
+	public.GET("/topics", topichandler.ListTopicsHandler(deps.TopicService))
+	public.GET("/topics/:topic_id/session", sessionhandler.GetSessionHandler(deps.SessionService))
+	public.GET("/topics/:topic_id/result", votehandler.ResultHandler(deps.VoteService))
+
+	authenticated := router.Group("/api", middleware.TokenMiddleware(deps.Tokens), rateLimit("authenticated", deps.RateLimiters.Authenticated), middleware.AuthorizeMiddleware(deps.UserService))
+	authenticated.POST("/auth/password", auth.ChangePasswordHandler(deps.UserService))
+	authenticated.POST("/auth/2fa/enroll", auth.EnrollTOTPHandler(deps.UserService))
+	authenticated.POST("/auth/2fa/confirm", auth.ConfirmTOTPHandler(deps.UserService))
+	authenticated.GET("/me", userhandler.GetMeHandler(deps.UserService, deps.VoteService))
+	authenticated.PATCH("/me", userhandler.UpdateMeHandler(deps.UserService))
+	authenticated.POST("/topics", topichandler.CreateTopicHandler(deps.TopicService))
+
+	votes := router.Group("/api", middleware.TokenMiddleware(deps.Tokens), rateLimit("votes", deps.RateLimiters.Votes), middleware.AuthorizeMiddleware(deps.UserService))
+	votes.POST("/topics/:topic_id/vote", votehandler.VoteHandler(deps.VoteService))
+
+	admin := authenticated.Group("", middleware.AdminMiddleware(), middleware.TwoFactorMiddleware())
+	admin.POST("/topics/:topic_id/session", sessionhandler.OpenSessionHandler(deps.SessionService))
+	admin.POST("/topics/:topic_id/session/extend", sessionhandler.ExtendSessionHandler(deps.SessionService))
+	admin.POST("/topics/:topic_id/session/close", sessionhandler.CloseSessionHandler(deps.SessionService))
+	admin.POST("/topics/:topic_id/session/pause", sessionhandler.PauseSessionHandler(deps.SessionService))
+	admin.POST("/topics/:topic_id/session/resume", sessionhandler.ResumeSessionHandler(deps.SessionService))
+	admin.GET("/topics/:topic_id/session/events", sessionhandler.ListSessionEventsHandler(deps.SessionService))
+	admin.GET("/users", userhandler.ListUsersHandler(deps.UserService))
+	admin.POST("/users/import", userhandler.ImportUsersHandler(deps.UserService))
+	admin.POST("/users/:user_id/unlock", userhandler.UnlockUserHandler(deps.UserService))
+	admin.POST("/users/:user_id/deactivate", userhandler.SetUserActiveHandler(deps.UserService, false))
+	admin.POST("/users/:user_id/activate", userhandler.SetUserActiveHandler(deps.UserService, true))
+	admin.PUT("/users/:user_id/role", userhandler.ChangeRoleHandler(deps.UserService))
+	admin.POST("/users/:user_id/password-reset", userhandler.ResetUserPasswordHandler(deps.UserService))
+}
+
+func rateLimit(name string, limiter ratelimit.Limiter) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.RateLimitMiddleware(name, limiter)
 }
@@ -0,0 +1,41 @@
+package health
+
+import "context"
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthService interface {
+	Readiness(ctx context.Context) (bool, map[string]CheckResult)
+}
+
+type healthService struct {
+	checks map[string]Check
+}
+
+func NewHealthService(checks map[string]Check) HealthService {
+	return &healthService{checks: checks}
+}
+
+func (s *healthService) Readiness(ctx context.Context) (bool, map[string]CheckResult) {
+	ready := true
+	results := make(map[string]CheckResult, len(s.checks))
+	for name, check := range s.checks {
+		if err := check(ctx); err != nil {
+			ready = false
+			results[name] = CheckResult{Status: StatusDown, Error: err.Error()}
+			continue
+		}
+		results[name] = CheckResult{Status: StatusUp}
+	}
+	return ready, results
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHealthService_Readiness_AllUp(t *testing.T) {
+	service := NewHealthService(map[string]Check{
+		"database": func(context.Context) error { return nil },
+		"workers":  func(context.Context) error { return nil },
+	})
+
+	ready, results := service.Readiness(context.Background())
+	if !ready {
+		t.Errorf("esperava serviço pronto, obteve %+v", results)
+	}
+	if len(results) != 2 || results["database"].Status != StatusUp {
+		t.Errorf("resultados incorretos: %+v", results)
+	}
+}
+
+func TestHealthService_Readiness_CheckDown(t *testing.T) {
+	service := NewHealthService(map[string]Check{
+		"database":   func(context.Context) error { return errors.New("connection refused") },
+		"migrations": func(context.Context) error { return nil },
+	})
+
+	ready, results := service.Readiness(context.Background())
+	if ready {
+		t.Error("esperava serviço indisponível")
+	}
+	if results["database"].Status != StatusDown || results["database"].Error != "connection refused" {
+		t.Errorf("esperava banco indisponível, obteve %+v", results["database"])
+	}
+	if results["migrations"].Status != StatusUp {
+		t.Errorf("esperava migrações ok, obteve %+v", results["migrations"])
+	}
+}
@@ -1,39 +1,280 @@
 package session
 
 import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
 	sessionrepo "desafio-tecnico-fullstack/backend/storage/repository/session"
-	"time"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("desafio-tecnico-fullstack/backend/services/session")
+
+var (
+	ErrSessionNotFound   = errors.New("sessão não encontrada para a pauta")
+	ErrSessionClosed     = errors.New("sessão de votação já encerrada")
+	ErrSessionPaused     = errors.New("sessão de votação está pausada")
+	ErrSessionNotPaused  = errors.New("sessão de votação não está pausada")
+	ErrInvalidExtension  = errors.New("prorrogação deve ser de pelo menos 1 minuto")
+	ErrSessionInProgress = errors.New("já existe uma rodada de votação em andamento para a pauta")
 )
 
 type SessionService interface {
-	OpenSession(topicID int, durationMinutes int) error
-	GetSessionByTopic(topicID int) (*models.Session, error)
-	UpdateExpiredSessions() error
+	OpenSession(ctx context.Context, topicID int, durationMinutes int) error
+	GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error)
+	GetSessionDetails(ctx context.Context, topicID int) (*models.SessionDetails, error)
+	UpdateExpiredSessions(ctx context.Context) error
+	ExtendSession(ctx context.Context, topicID int, userID int, minutes int) (*models.Session, error)
+	CloseSession(ctx context.Context, topicID int, userID int) (*models.Session, error)
+	PauseSession(ctx context.Context, topicID int, userID int) (*models.Session, error)
+	ResumeSession(ctx context.Context, topicID int, userID int) (*models.Session, error)
+	ListSessionEvents(ctx context.Context, topicID int) ([]models.SessionEvent, error)
 }
 
 type sessionService struct {
-	repo sessionrepo.SessionRepository
+	repo  sessionrepo.SessionRepository
+	uow   storage.UnitOfWork
+	clock clock.Clock
 }
 
-func NewSessionService(repo sessionrepo.SessionRepository) SessionService {
-	return &sessionService{repo: repo}
+func NewSessionService(repo sessionrepo.SessionRepository, uow storage.UnitOfWork, clock clock.Clock) SessionService {
+	return &sessionService{repo: repo, uow: uow, clock: clock}
 }
 
-func (s *sessionService) OpenSession(topicID int, durationMinutes int) error {
+func (s *sessionService) OpenSession(ctx context.Context, topicID int, durationMinutes int) (err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.OpenSession")
+	defer func() { tracing.End(span, err) }()
+
 	if durationMinutes <= 0 {
 		durationMinutes = 1
 	}
 
-	now := time.Now().Unix()
+	now := s.clock.Now().Unix()
 	closeAt := now + int64(durationMinutes*60)
-	return s.repo.OpenSession(topicID, now, closeAt)
+	// The check gives the usual answer; the database keeps a single active
+	// round per topic when two requests pass it at the same time.
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		current, err := s.repo.GetSessionByTopic(ctx, topicID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if current != nil && inProgress(current, now) {
+			return ErrSessionInProgress
+		}
+		return s.repo.OpenSession(ctx, topicID, now, closeAt)
+	})
+	if err != nil && strings.Contains(err.Error(), "duplicate key") {
+		return ErrSessionInProgress
+	}
+	if err != nil {
+		return err
+	}
+
+	metrics.SessionsOpened.Inc()
+	logger.FromContext(ctx).Info("sessão de votação aberta", "topic_id", topicID, "close_at", closeAt)
+	return nil
+}
+
+func (s *sessionService) GetSessionByTopic(ctx context.Context, topicID int) (_ *models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.GetSessionByTopic")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repo.GetSessionByTopic(ctx, topicID)
+}
+
+func (s *sessionService) GetSessionDetails(ctx context.Context, topicID int) (_ *models.SessionDetails, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.GetSessionDetails")
+	defer func() { tracing.End(span, err) }()
+
+	session, err := s.sessionByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().Unix()
+	details := &models.SessionDetails{Session: *session, ServerTime: now}
+	switch {
+	case !inProgress(session, now):
+		details.State = models.SessionStatusClosed
+	case session.Status == models.SessionStatusPaused && session.PausedAt != nil:
+		details.State = models.SessionStatusPaused
+		details.RemainingSeconds = max(session.CloseAt-*session.PausedAt, 0)
+	default:
+		details.State = models.SessionStatusOpen
+		details.RemainingSeconds = session.CloseAt - now
+	}
+	return details, nil
+}
+
+func (s *sessionService) UpdateExpiredSessions(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.UpdateExpiredSessions")
+	defer func() { tracing.End(span, err) }()
+
+	closed, err := s.repo.UpdateExpiredSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	metrics.SessionsClosed.WithLabelValues(metrics.SessionClosedExpired).Add(float64(closed))
+	if closed > 0 {
+		logger.FromContext(ctx).Info("sessões expiradas encerradas", "count", closed)
+	}
+	return nil
+}
+
+func (s *sessionService) ExtendSession(ctx context.Context, topicID int, userID int, minutes int) (_ *models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.ExtendSession")
+	defer func() { tracing.End(span, err) }()
+
+	if minutes <= 0 {
+		return nil, ErrInvalidExtension
+	}
+	session, now, err := s.activeSession(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.CloseAt += int64(minutes * 60)
+	topicStatus := models.TopicStatusOpen
+	if session.Status == models.SessionStatusPaused {
+		topicStatus = models.TopicStatusPaused
+	}
+	return s.apply(ctx, session, topicStatus, models.SessionEventExtended, userID, now)
+}
+
+func (s *sessionService) CloseSession(ctx context.Context, topicID int, userID int) (_ *models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.CloseSession")
+	defer func() { tracing.End(span, err) }()
+
+	session, now, err := s.activeSession(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.CloseAt = now
+	session.Status = models.SessionStatusClosed
+	session.PausedAt = nil
+	session, err = s.apply(ctx, session, models.TopicStatusClosed, models.SessionEventClosed, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics.SessionsClosed.WithLabelValues(metrics.SessionClosedManual).Inc()
+	return session, nil
 }
 
-func (s *sessionService) GetSessionByTopic(topicID int) (*models.Session, error) {
-	return s.repo.GetSessionByTopic(topicID)
+func (s *sessionService) PauseSession(ctx context.Context, topicID int, userID int) (_ *models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.PauseSession")
+	defer func() { tracing.End(span, err) }()
+
+	session, now, err := s.activeSession(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.SessionStatusPaused {
+		return nil, ErrSessionPaused
+	}
+
+	session.Status = models.SessionStatusPaused
+	session.PausedAt = &now
+	return s.apply(ctx, session, models.TopicStatusPaused, models.SessionEventPaused, userID, now)
 }
 
-func (s *sessionService) UpdateExpiredSessions() error {
-	return s.repo.UpdateExpiredSessions()
+func (s *sessionService) ResumeSession(ctx context.Context, topicID int, userID int) (_ *models.Session, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.ResumeSession")
+	defer func() { tracing.End(span, err) }()
+
+	session, now, err := s.activeSession(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.SessionStatusPaused || session.PausedAt == nil {
+		return nil, ErrSessionNotPaused
+	}
+
+	session.CloseAt = now + (session.CloseAt - *session.PausedAt)
+	session.Status = models.SessionStatusOpen
+	session.PausedAt = nil
+	return s.apply(ctx, session, models.TopicStatusOpen, models.SessionEventResumed, userID, now)
+}
+
+func (s *sessionService) ListSessionEvents(ctx context.Context, topicID int) (_ []models.SessionEvent, err error) {
+	ctx, span := tracer.Start(ctx, "SessionService.ListSessionEvents")
+	defer func() { tracing.End(span, err) }()
+
+	session, err := s.sessionByTopic(ctx, topicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListSessionEvents(ctx, session.ID)
+}
+
+// sessionByTopic reports a topic without sessions as ErrSessionNotFound and
+// passes any other repository error on.
+func (s *sessionService) sessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
+	session, err := s.repo.GetSessionByTopic(ctx, topicID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && session == nil) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// activeSession returns the topic's session if it can still be changed: a
+// paused session keeps its deadline frozen, so it never counts as expired.
+func (s *sessionService) activeSession(ctx context.Context, topicID int) (*models.Session, int64, error) {
+	session, err := s.sessionByTopic(ctx, topicID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := s.clock.Now().Unix()
+	if !inProgress(session, now) {
+		return nil, 0, ErrSessionClosed
+	}
+	return session, now, nil
+}
+
+func inProgress(session *models.Session, now int64) bool {
+	switch session.Status {
+	case models.SessionStatusClosed:
+		return false
+	case models.SessionStatusPaused:
+		return true
+	default:
+		return now <= session.CloseAt
+	}
+}
+
+func (s *sessionService) apply(ctx context.Context, session *models.Session, topicStatus, eventType string, userID int, now int64) (*models.Session, error) {
+	event := models.SessionEvent{
+		SessionID: session.ID,
+		UserID:    userID,
+		Type:      eventType,
+		CloseAt:   session.CloseAt,
+		CreatedAt: now,
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateSession(ctx, *session, topicStatus); err != nil {
+			return err
+		}
+		return s.repo.AddSessionEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("sessão de votação alterada",
+		"topic_id", session.TopicID, "session_id", session.ID, "event", eventType, "user_id", userID, "close_at", session.CloseAt)
+	return session, nil
 }
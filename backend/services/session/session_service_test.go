@@ -1,18 +1,35 @@
 package session
 
 import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
+var testNow = time.Unix(1750000000, 0)
+
 type mockSessionRepo struct {
 	openErr     error
 	getSession  *models.Session
 	getErr      error
 	updateErr   error
+	eventErr    error
 	openedCalls []openSessionCall
+	updated     []updateSessionCall
+	events      []models.SessionEvent
+	expired     int64
+}
+
+type updateSessionCall struct {
+	session     models.Session
+	topicStatus string
 }
 
 type openSessionCall struct {
@@ -21,7 +38,7 @@ type openSessionCall struct {
 	closeAt int64
 }
 
-func (m *mockSessionRepo) OpenSession(topicID int, openAt, closeAt int64) error {
+func (m *mockSessionRepo) OpenSession(ctx context.Context, topicID int, openAt, closeAt int64) error {
 	if m.openErr != nil {
 		return m.openErr
 	}
@@ -33,24 +50,61 @@ func (m *mockSessionRepo) OpenSession(topicID int, openAt, closeAt int64) error
 	return nil
 }
 
-func (m *mockSessionRepo) GetSessionByTopic(topicID int) (*models.Session, error) {
+func (m *mockSessionRepo) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
 	}
 	return m.getSession, nil
 }
 
-func (m *mockSessionRepo) UpdateExpiredSessions() error {
-	return m.updateErr
+func (m *mockSessionRepo) UpdateExpiredSessions(ctx context.Context) (int64, error) {
+	if m.updateErr != nil {
+		return 0, m.updateErr
+	}
+	return m.expired, nil
+}
+
+func (m *mockSessionRepo) UpdateSession(ctx context.Context, session models.Session, topicStatus string) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.updated = append(m.updated, updateSessionCall{session: session, topicStatus: topicStatus})
+	return nil
+}
+
+func (m *mockSessionRepo) AddSessionEvent(ctx context.Context, event models.SessionEvent) error {
+	if m.eventErr != nil {
+		return m.eventErr
+	}
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockSessionRepo) ListSessionEvents(ctx context.Context, sessionID int) ([]models.SessionEvent, error) {
+	return m.events, nil
+}
+
+type mockUnitOfWork struct {
+	calls      int
+	rolledBack int
+}
+
+func (m *mockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
+	return nil
 }
 
 func TestSessionService_OpenSession_Success(t *testing.T) {
 	repo := &mockSessionRepo{}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	now := time.Now().Unix()
+	now := testNow.Unix()
 
-	err := service.OpenSession(1, 5)
+	err := service.OpenSession(context.Background(), 1, 5)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -77,9 +131,9 @@ func TestSessionService_OpenSession_Success(t *testing.T) {
 
 func TestSessionService_OpenSession_ZeroDuration(t *testing.T) {
 	repo := &mockSessionRepo{}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.OpenSession(1, 0)
+	err := service.OpenSession(context.Background(), 1, 0)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -97,9 +151,9 @@ func TestSessionService_OpenSession_ZeroDuration(t *testing.T) {
 
 func TestSessionService_OpenSession_NegativeDuration(t *testing.T) {
 	repo := &mockSessionRepo{}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.OpenSession(1, -10)
+	err := service.OpenSession(context.Background(), 1, -10)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -119,9 +173,9 @@ func TestSessionService_OpenSession_RepoError(t *testing.T) {
 	repo := &mockSessionRepo{
 		openErr: errors.New("database error"),
 	}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.OpenSession(1, 5)
+	err := service.OpenSession(context.Background(), 1, 5)
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -138,9 +192,9 @@ func TestSessionService_GetSessionByTopic_Success(t *testing.T) {
 	repo := &mockSessionRepo{
 		getSession: expectedSession,
 	}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	session, err := service.GetSessionByTopic(123)
+	session, err := service.GetSessionByTopic(context.Background(), 123)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -162,9 +216,9 @@ func TestSessionService_GetSessionByTopic_NotFound(t *testing.T) {
 		getSession: nil,
 		getErr:     nil,
 	}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	session, err := service.GetSessionByTopic(123)
+	session, err := service.GetSessionByTopic(context.Background(), 123)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -178,9 +232,9 @@ func TestSessionService_GetSessionByTopic_RepoError(t *testing.T) {
 	repo := &mockSessionRepo{
 		getErr: errors.New("database error"),
 	}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	session, err := service.GetSessionByTopic(123)
+	session, err := service.GetSessionByTopic(context.Background(), 123)
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -192,21 +246,36 @@ func TestSessionService_GetSessionByTopic_RepoError(t *testing.T) {
 
 func TestSessionService_UpdateExpiredSessions_Success(t *testing.T) {
 	repo := &mockSessionRepo{}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.UpdateExpiredSessions()
+	err := service.UpdateExpiredSessions(context.Background())
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
 }
 
+func TestSessionService_UpdateExpiredSessions_CountsClosedSessions(t *testing.T) {
+	repo := &mockSessionRepo{expired: 3}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+	counter := metrics.SessionsClosed.WithLabelValues(metrics.SessionClosedExpired)
+	before := testutil.ToFloat64(counter)
+
+	if err := service.UpdateExpiredSessions(context.Background()); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if got := testutil.ToFloat64(counter); got != before+3 {
+		t.Errorf("esperava %v sessões encerradas por expiração, obteve %v", before+3, got)
+	}
+}
+
 func TestSessionService_UpdateExpiredSessions_RepoError(t *testing.T) {
 	repo := &mockSessionRepo{
 		updateErr: errors.New("database error"),
 	}
-	service := NewSessionService(repo)
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.UpdateExpiredSessions()
+	err := service.UpdateExpiredSessions(context.Background())
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -228,9 +297,9 @@ func TestSessionService_OpenSession_DurationCalculation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &mockSessionRepo{}
-			service := NewSessionService(repo)
+			service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-			err := service.OpenSession(1, tt.inputDuration)
+			err := service.OpenSession(context.Background(), 1, tt.inputDuration)
 			if err != nil {
 				t.Errorf("esperava sucesso, obteve erro: %v", err)
 			}
@@ -248,6 +317,377 @@ func TestSessionService_OpenSession_DurationCalculation(t *testing.T) {
 	}
 }
 
+func TestSessionService_ExtendSession_Success(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 60, CloseAt: now + 60, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	session, err := service.ExtendSession(context.Background(), 1, 42, 5)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if session.CloseAt != now+60+300 {
+		t.Errorf("esperava closeAt %d, obteve %d", now+60+300, session.CloseAt)
+	}
+	if len(repo.updated) != 1 || repo.updated[0].topicStatus != models.TopicStatusOpen {
+		t.Errorf("esperava atualização com status 'Sessão Aberta', obteve %+v", repo.updated)
+	}
+	if len(repo.events) != 1 || repo.events[0].Type != models.SessionEventExtended || repo.events[0].UserID != 42 {
+		t.Errorf("evento de prorrogação não registrado corretamente: %+v", repo.events)
+	}
+}
+
+func TestSessionService_ExtendSession_InvalidMinutes(t *testing.T) {
+	repo := &mockSessionRepo{}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.ExtendSession(context.Background(), 1, 42, 0)
+	if !errors.Is(err, ErrInvalidExtension) {
+		t.Errorf("esperava erro de prorrogação inválida, obteve: %v", err)
+	}
+}
+
+func TestSessionService_ExtendSession_Expired(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 120, CloseAt: now - 60, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.ExtendSession(context.Background(), 1, 42, 5)
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("esperava erro de sessão encerrada, obteve: %v", err)
+	}
+	if len(repo.updated) != 0 || len(repo.events) != 0 {
+		t.Errorf("não deveria alterar uma sessão expirada")
+	}
+}
+
+func TestSessionService_ExtendSession_NotFound(t *testing.T) {
+	repo := &mockSessionRepo{getErr: sql.ErrNoRows}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.ExtendSession(context.Background(), 1, 42, 5)
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("esperava erro de sessão não encontrada, obteve: %v", err)
+	}
+}
+
+func TestSessionService_CloseSession_Success(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 60, CloseAt: now + 600, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	session, err := service.CloseSession(context.Background(), 1, 42)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if session.Status != models.SessionStatusClosed {
+		t.Errorf("esperava status %q, obteve %q", models.SessionStatusClosed, session.Status)
+	}
+	if abs(session.CloseAt-now) > 5 {
+		t.Errorf("closeAt deveria ser o momento do encerramento, obteve %d", session.CloseAt)
+	}
+	if repo.updated[0].topicStatus != models.TopicStatusClosed {
+		t.Errorf("esperava status da pauta 'Votação Encerrada', obteve %q", repo.updated[0].topicStatus)
+	}
+	if repo.events[0].Type != models.SessionEventClosed {
+		t.Errorf("esperava evento %q, obteve %q", models.SessionEventClosed, repo.events[0].Type)
+	}
+}
+
+func TestSessionService_CloseSession_AlreadyClosed(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 60, CloseAt: now - 10, Status: models.SessionStatusClosed},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.CloseSession(context.Background(), 1, 42)
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Errorf("esperava erro de sessão encerrada, obteve: %v", err)
+	}
+}
+
+func TestSessionService_CloseSession_EventErrorRollsBack(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 60, CloseAt: now + 600, Status: models.SessionStatusOpen},
+		eventErr:   errors.New("database error"),
+	}
+	uow := &mockUnitOfWork{}
+	service := NewSessionService(repo, uow, clock.NewFake(testNow))
+
+	_, err := service.CloseSession(context.Background(), 1, 42)
+	if err == nil || err.Error() != "database error" {
+		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
+	}
+	if len(repo.updated) != 1 || uow.calls != 1 || uow.rolledBack != 1 {
+		t.Errorf("esperava atualização e evento na mesma transação desfeita, obteve %d chamadas e %d rollbacks", uow.calls, uow.rolledBack)
+	}
+}
+
+func TestSessionService_PauseAndResume_PreservesRemainingTime(t *testing.T) {
+	now := testNow.Unix()
+	pausedAt := now - 30
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 120, CloseAt: now + 60, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	paused, err := service.PauseSession(context.Background(), 1, 42)
+	if err != nil {
+		t.Fatalf("esperava sucesso ao pausar, obteve erro: %v", err)
+	}
+	if paused.Status != models.SessionStatusPaused || paused.PausedAt == nil {
+		t.Fatalf("sessão deveria estar pausada: %+v", paused)
+	}
+	if repo.updated[0].topicStatus != models.TopicStatusPaused {
+		t.Errorf("esperava status da pauta 'Sessão Pausada', obteve %q", repo.updated[0].topicStatus)
+	}
+
+	// Simula uma pausa iniciada há 30 segundos, restando 90 segundos de votação.
+	repo.getSession = &models.Session{ID: 7, TopicID: 1, OpenAt: now - 120, CloseAt: pausedAt + 90, Status: models.SessionStatusPaused, PausedAt: &pausedAt}
+
+	resumed, err := service.ResumeSession(context.Background(), 1, 42)
+	if err != nil {
+		t.Fatalf("esperava sucesso ao retomar, obteve erro: %v", err)
+	}
+	if resumed.Status != models.SessionStatusOpen || resumed.PausedAt != nil {
+		t.Errorf("sessão deveria estar aberta: %+v", resumed)
+	}
+	if remaining := resumed.CloseAt - now; abs(remaining-90) > 5 {
+		t.Errorf("esperava ~90 segundos restantes após retomar, obteve %d", remaining)
+	}
+	if len(repo.events) != 2 || repo.events[1].Type != models.SessionEventResumed {
+		t.Errorf("eventos de pausa/retomada não registrados corretamente: %+v", repo.events)
+	}
+}
+
+func TestSessionService_PauseSession_AlreadyPaused(t *testing.T) {
+	now := testNow.Unix()
+	pausedAt := now - 500
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 600, CloseAt: now - 400, Status: models.SessionStatusPaused, PausedAt: &pausedAt},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.PauseSession(context.Background(), 1, 42)
+	if !errors.Is(err, ErrSessionPaused) {
+		t.Errorf("esperava erro de sessão pausada, obteve: %v", err)
+	}
+}
+
+func TestSessionService_ResumeSession_NotPaused(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, OpenAt: now - 60, CloseAt: now + 60, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.ResumeSession(context.Background(), 1, 42)
+	if !errors.Is(err, ErrSessionNotPaused) {
+		t.Errorf("esperava erro de sessão não pausada, obteve: %v", err)
+	}
+}
+
+func TestSessionService_OpenSession_RoundInProgress(t *testing.T) {
+	now := testNow.Unix()
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, Round: 1, OpenAt: now - 60, CloseAt: now + 60, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	err := service.OpenSession(context.Background(), 1, 5)
+	if !errors.Is(err, ErrSessionInProgress) {
+		t.Errorf("esperava erro de rodada em andamento, obteve: %v", err)
+	}
+	if len(repo.openedCalls) != 0 {
+		t.Errorf("não deveria abrir nova rodada com outra em andamento")
+	}
+}
+
+func TestSessionService_OpenSession_NewRoundAfterPreviousEnded(t *testing.T) {
+	now := testNow.Unix()
+	tests := []struct {
+		name    string
+		session *models.Session
+	}{
+		{"expired", &models.Session{ID: 7, TopicID: 1, Round: 1, OpenAt: now - 120, CloseAt: now - 60, Status: models.SessionStatusOpen}},
+		{"closed early", &models.Session{ID: 7, TopicID: 1, Round: 1, OpenAt: now - 120, CloseAt: now + 60, Status: models.SessionStatusClosed}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSessionRepo{getSession: tt.session}
+			service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+			if err := service.OpenSession(context.Background(), 1, 5); err != nil {
+				t.Errorf("esperava sucesso, obteve erro: %v", err)
+			}
+			if len(repo.openedCalls) != 1 {
+				t.Errorf("esperava 1 chamada para OpenSession, obteve %d", len(repo.openedCalls))
+			}
+		})
+	}
+}
+
+func TestSessionService_GetSessionDetails(t *testing.T) {
+	now := testNow.Unix()
+	pausedAt := now - 30
+	tests := []struct {
+		name              string
+		session           *models.Session
+		expectedState     string
+		expectedRemaining int64
+	}{
+		{"open", &models.Session{ID: 1, OpenAt: now - 60, CloseAt: now + 120, Status: models.SessionStatusOpen}, models.SessionStatusOpen, 120},
+		{"paused", &models.Session{ID: 1, OpenAt: now - 60, CloseAt: pausedAt + 45, Status: models.SessionStatusPaused, PausedAt: &pausedAt}, models.SessionStatusPaused, 45},
+		{"expired", &models.Session{ID: 1, OpenAt: now - 120, CloseAt: now - 60, Status: models.SessionStatusOpen}, models.SessionStatusClosed, 0},
+		{"closed early", &models.Session{ID: 1, OpenAt: now - 120, CloseAt: now + 60, Status: models.SessionStatusClosed}, models.SessionStatusClosed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSessionRepo{getSession: tt.session}
+			service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+			details, err := service.GetSessionDetails(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+			if details.State != tt.expectedState {
+				t.Errorf("esperava estado %q, obteve %q", tt.expectedState, details.State)
+			}
+			if abs(details.RemainingSeconds-tt.expectedRemaining) > 5 {
+				t.Errorf("esperava ~%d segundos restantes, obteve %d", tt.expectedRemaining, details.RemainingSeconds)
+			}
+			if abs(details.ServerTime-now) > 5 {
+				t.Errorf("server_time muito diferente do tempo atual: %d", details.ServerTime)
+			}
+		})
+	}
+}
+
+func TestSessionService_GetSessionDetails_NotFound(t *testing.T) {
+	repo := &mockSessionRepo{getErr: sql.ErrNoRows}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	_, err := service.GetSessionDetails(context.Background(), 1)
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("esperava erro de sessão não encontrada, obteve: %v", err)
+	}
+}
+
+func TestSessionService_RepoErrorsAreNotNotFound(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	repo := &mockSessionRepo{getErr: dbErr}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	if _, err := service.GetSessionDetails(context.Background(), 1); !errors.Is(err, dbErr) {
+		t.Errorf("GetSessionDetails: esperava o erro do repositório, obteve: %v", err)
+	}
+	if _, err := service.CloseSession(context.Background(), 1, 42); !errors.Is(err, dbErr) {
+		t.Errorf("CloseSession: esperava o erro do repositório, obteve: %v", err)
+	}
+	if _, err := service.ListSessionEvents(context.Background(), 1); !errors.Is(err, dbErr) {
+		t.Errorf("ListSessionEvents: esperava o erro do repositório, obteve: %v", err)
+	}
+	if err := service.OpenSession(context.Background(), 1, 5); !errors.Is(err, dbErr) || len(repo.openedCalls) != 0 {
+		t.Errorf("OpenSession: esperava o erro do repositório, obteve: %v", err)
+	}
+}
+
+func TestSessionService_OpenSession_ConcurrentRound(t *testing.T) {
+	repo := &mockSessionRepo{
+		getErr:  sql.ErrNoRows,
+		openErr: errors.New(`duplicate key value violates unique constraint "sessions_one_active_round_key"`),
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	err := service.OpenSession(context.Background(), 1, 5)
+	if !errors.Is(err, ErrSessionInProgress) {
+		t.Errorf("esperava erro de rodada em andamento, obteve: %v", err)
+	}
+}
+
+func TestSessionService_ExpiryBoundaries(t *testing.T) {
+	openAt := testNow.Unix()
+	closeAt := openAt + 60
+
+	tests := []struct {
+		name         string
+		at           int64
+		inProgress   bool
+		expectedLeft int64
+	}{
+		{"exactly at open_at", openAt, true, 60},
+		{"one second before close_at", closeAt - 1, true, 1},
+		{"exactly at close_at", closeAt, true, 0},
+		{"one second after close_at", closeAt + 1, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Unix(tt.at, 0))
+			repo := &mockSessionRepo{
+				getSession: &models.Session{ID: 7, TopicID: 1, Round: 1, OpenAt: openAt, CloseAt: closeAt, Status: models.SessionStatusOpen},
+			}
+			service := NewSessionService(repo, &mockUnitOfWork{}, clk)
+
+			details, err := service.GetSessionDetails(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+			if (details.State == models.SessionStatusOpen) != tt.inProgress {
+				t.Errorf("estado inesperado %q", details.State)
+			}
+			if details.RemainingSeconds != tt.expectedLeft {
+				t.Errorf("esperava %d segundos restantes, obteve %d", tt.expectedLeft, details.RemainingSeconds)
+			}
+			if details.ServerTime != tt.at {
+				t.Errorf("esperava server_time %d, obteve %d", tt.at, details.ServerTime)
+			}
+
+			_, err = service.ExtendSession(context.Background(), 1, 42, 1)
+			if tt.inProgress && err != nil {
+				t.Errorf("esperava prorrogação permitida, obteve erro: %v", err)
+			}
+			if !tt.inProgress && !errors.Is(err, ErrSessionClosed) {
+				t.Errorf("esperava erro de sessão encerrada, obteve: %v", err)
+			}
+		})
+	}
+}
+
+func TestSessionService_OpenSession_NextRoundAfterExpiry(t *testing.T) {
+	clk := clock.NewFake(testNow)
+	closeAt := testNow.Unix() + 60
+	repo := &mockSessionRepo{
+		getSession: &models.Session{ID: 7, TopicID: 1, Round: 1, OpenAt: testNow.Unix(), CloseAt: closeAt, Status: models.SessionStatusOpen},
+	}
+	service := NewSessionService(repo, &mockUnitOfWork{}, clk)
+
+	clk.Advance(60 * time.Second)
+	if err := service.OpenSession(context.Background(), 1, 1); !errors.Is(err, ErrSessionInProgress) {
+		t.Errorf("em close_at a rodada ainda está em andamento, obteve: %v", err)
+	}
+
+	clk.Advance(time.Second)
+	if err := service.OpenSession(context.Background(), 1, 1); err != nil {
+		t.Fatalf("esperava sucesso após close_at, obteve erro: %v", err)
+	}
+	if repo.openedCalls[0].openAt != closeAt+1 {
+		t.Errorf("esperava openAt %d, obteve %d", closeAt+1, repo.openedCalls[0].openAt)
+	}
+}
+
 // Helper function to calculate absolute value
 func abs(x int64) int64 {
 	if x < 0 {
@@ -1,14 +1,20 @@
 package topic
 
 import (
+	"context"
 	"desafio-tecnico-fullstack/backend/models"
 	"desafio-tecnico-fullstack/backend/services/session"
 	topicrepo "desafio-tecnico-fullstack/backend/storage/repository/topic"
+	"desafio-tecnico-fullstack/backend/tracing"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("desafio-tecnico-fullstack/backend/services/topic")
+
 type TopicService interface {
-	CreateTopic(name string, status string) error
-	ListTopics() ([]models.Topic, error)
+	CreateTopic(ctx context.Context, name string, status string) error
+	ListTopics(ctx context.Context) ([]models.Topic, error)
 }
 
 type topicService struct {
@@ -23,20 +29,25 @@ func NewTopicService(repo topicrepo.TopicRepository, sessionService session.Sess
 	}
 }
 
-func (s *topicService) CreateTopic(name string, status string) error {
+func (s *topicService) CreateTopic(ctx context.Context, name string, status string) (err error) {
+	ctx, span := tracer.Start(ctx, "TopicService.CreateTopic")
+	defer func() { tracing.End(span, err) }()
 
 	if status == "" {
 		status = "Aguardando Abertura"
 	}
 
 	topic := models.Topic{Name: name, Status: status}
-	return s.repo.CreateTopic(topic)
+	return s.repo.CreateTopic(ctx, topic)
 }
 
-func (s *topicService) ListTopics() ([]models.Topic, error) {
-	if err := s.sessionService.UpdateExpiredSessions(); err != nil {
+func (s *topicService) ListTopics(ctx context.Context) (_ []models.Topic, err error) {
+	ctx, span := tracer.Start(ctx, "TopicService.ListTopics")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.sessionService.UpdateExpiredSessions(ctx); err != nil {
 		return nil, err
 	}
 
-	return s.repo.ListTopics()
+	return s.repo.ListTopics(ctx)
 }
@@ -1,6 +1,7 @@
 package topic
 
 import (
+	"context"
 	"desafio-tecnico-fullstack/backend/models"
 	"errors"
 	"testing"
@@ -12,7 +13,7 @@ type mockTopicRepo struct {
 	listErr   error
 }
 
-func (m *mockTopicRepo) CreateTopic(topic models.Topic) error {
+func (m *mockTopicRepo) CreateTopic(ctx context.Context, topic models.Topic) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
@@ -20,7 +21,7 @@ func (m *mockTopicRepo) CreateTopic(topic models.Topic) error {
 	return nil
 }
 
-func (m *mockTopicRepo) ListTopics() ([]models.Topic, error) {
+func (m *mockTopicRepo) ListTopics(ctx context.Context) ([]models.Topic, error) {
 	if m.listErr != nil {
 		return nil, m.listErr
 	}
@@ -31,25 +32,49 @@ type mockSessionService struct {
 	updateErr error
 }
 
-func (m *mockSessionService) OpenSession(topicID int, durationMinutes int) error {
+func (m *mockSessionService) OpenSession(ctx context.Context, topicID int, durationMinutes int) error {
 	return nil
 }
 
-func (m *mockSessionService) GetSessionByTopic(topicID int) (*models.Session, error) {
+func (m *mockSessionService) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
 	return nil, nil
 }
 
-func (m *mockSessionService) UpdateExpiredSessions() error {
+func (m *mockSessionService) GetSessionDetails(ctx context.Context, topicID int) (*models.SessionDetails, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) UpdateExpiredSessions(ctx context.Context) error {
 	return m.updateErr
 }
 
+func (m *mockSessionService) ExtendSession(ctx context.Context, topicID int, userID int, minutes int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) CloseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) PauseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) ResumeSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) ListSessionEvents(ctx context.Context, topicID int) ([]models.SessionEvent, error) {
+	return nil, nil
+}
+
 func TestTopicService_CreateTopic_Success(t *testing.T) {
 	repo := &mockTopicRepo{}
 	sessionService := &mockSessionService{}
 
 	service := NewTopicService(repo, sessionService)
 
-	err := service.CreateTopic("Nova Pauta", "Ativa")
+	err := service.CreateTopic(context.Background(), "Nova Pauta", "Ativa")
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -70,7 +95,7 @@ func TestTopicService_CreateTopic_EmptyStatus(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	err := service.CreateTopic("Nova Pauta", "")
+	err := service.CreateTopic(context.Background(), "Nova Pauta", "")
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -93,7 +118,7 @@ func TestTopicService_CreateTopic_RepoError(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	err := service.CreateTopic("Nova Pauta", "Ativa")
+	err := service.CreateTopic(context.Background(), "Nova Pauta", "Ativa")
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -112,7 +137,7 @@ func TestTopicService_ListTopics_Success(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	topics, err := service.ListTopics()
+	topics, err := service.ListTopics(context.Background())
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -140,7 +165,7 @@ func TestTopicService_ListTopics_UpdateSessionError(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	topics, err := service.ListTopics()
+	topics, err := service.ListTopics(context.Background())
 	if err == nil || err.Error() != "session update error" {
 		t.Errorf("esperava erro de atualização de sessão, obteve: %v", err)
 	}
@@ -158,7 +183,7 @@ func TestTopicService_ListTopics_RepoError(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	topics, err := service.ListTopics()
+	topics, err := service.ListTopics(context.Background())
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -176,7 +201,7 @@ func TestTopicService_ListTopics_EmptyList(t *testing.T) {
 
 	service := NewTopicService(repo, sessionService)
 
-	topics, err := service.ListTopics()
+	topics, err := service.ListTopics(context.Background())
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
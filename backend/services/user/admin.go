@@ -0,0 +1,174 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// MaxPage keeps the offset of ListUsers far from overflowing; no listing
+// goes that deep.
+const MaxPage = 100000
+
+// UserPage is a page of ListUsers; Total counts every matching user.
+type UserPage struct {
+	Users    []models.User `json:"users"`
+	Total    int           `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+// PasswordResetTicket is the reset token an administrator issued, to be
+// handed to the user when the notifier cannot reach them.
+type PasswordResetTicket struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ListUsers pages through the users whose name contains search or whose CPF
+// starts with it; formatted CPFs like 123.456 are searched by their digits.
+// page starts at 1 and pageSize defaults to 20, up to 100.
+func (s *userService) ListUsers(ctx context.Context, search string, page, pageSize int) (_ *UserPage, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer func() { tracing.End(span, err) }()
+
+	page = min(max(page, 1), MaxPage)
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+	search = strings.TrimSpace(search)
+	if digits := digitsOnly(search); digits != "" && strings.Trim(search, "0123456789.-") == "" {
+		search = digits
+	}
+
+	users, total, err := s.repo.ListUsers(ctx, user.ListFilter{Search: search, Limit: pageSize, Offset: (page - 1) * pageSize})
+	if err != nil {
+		return nil, err
+	}
+	return &UserPage{Users: users, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// SetUserActive deactivates or reactivates a user. Deactivated users cannot
+// log in and the tokens they hold are refused, which also keeps them from
+// voting. Administrators cannot deactivate themselves.
+func (s *userService) SetUserActive(ctx context.Context, adminID, userID int, active bool) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.SetUserActive")
+	defer func() { tracing.End(span, err) }()
+
+	var deactivatedAt *int64
+	if !active {
+		if userID == adminID {
+			return errors.New("não é possível desativar a própria conta")
+		}
+		now := s.clock.Now().Unix()
+		deactivatedAt = &now
+	}
+	err = s.repo.SetDeactivated(ctx, userID, deactivatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("usuário não encontrado")
+	}
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("situação do usuário alterada", "user_id", userID, "active", active, "admin_id", adminID)
+	return nil
+}
+
+// ChangeRole takes effect on the user's next login: tokens carrying the old
+// role are refused by AuthorizeToken.
+func (s *userService) ChangeRole(ctx context.Context, adminID, userID int, role string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangeRole")
+	defer func() { tracing.End(span, err) }()
+
+	if role != models.RoleAssociate && role != models.RoleAdmin {
+		return errors.New("papel inválido")
+	}
+	if userID == adminID {
+		return errors.New("não é possível alterar o próprio papel")
+	}
+	err = s.repo.UpdateRole(ctx, userID, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("usuário não encontrado")
+	}
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("papel alterado", "user_id", userID, "role", role, "admin_id", adminID)
+	return nil
+}
+
+// AdminResetPassword replaces the user's password with an unknown one, which
+// revokes their tokens, and issues a reset token. The token is sent through
+// the notifier and also returned, so the administrator can hand it over.
+func (s *userService) AdminResetPassword(ctx context.Context, adminID, userID int) (_ *PasswordResetTicket, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.AdminResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	u := s.repo.GetUserByID(ctx, userID)
+	if u == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	password, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var ticket PasswordResetTicket
+	now := s.clock.Now()
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		err := s.repo.UpdatePassword(ctx, u.ID, string(hash), now.Unix())
+		if err != nil {
+			return err
+		}
+		ticket.Token, ticket.ExpiresAt, err = s.createResetToken(ctx, u.ID, now, s.resetTokenTTL)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.notifier.Notify(ctx, notify.Notification{
+		Type:      notify.TypePasswordReset,
+		UserID:    u.ID,
+		Name:      u.Name,
+		CPF:       u.CPF,
+		Token:     ticket.Token,
+		ExpiresAt: ticket.ExpiresAt,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("erro ao enviar token de redefinição de senha", "user_id", u.ID, "error", err)
+	}
+	logger.FromContext(ctx).Info("senha redefinida por administrador", "user_id", u.ID, "admin_id", adminID)
+	return &ticket, nil
+}
+
+// checkActive refuses logins of deactivated users. It runs after the
+// credentials are verified, so it does not reveal whether a CPF has an account.
+func checkActive(ctx context.Context, u *models.User) error {
+	if u.DeactivatedAt == nil {
+		return nil
+	}
+	metrics.LoginFailures.WithLabelValues(metrics.LoginFailureDeactivated).Inc()
+	logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureDeactivated, "user_id", u.ID)
+	return errors.New("conta desativada")
+}
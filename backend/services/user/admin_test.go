@@ -0,0 +1,153 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/tokens"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestListUsers_Pagination(t *testing.T) {
+	testCases := []struct {
+		name             string
+		search           string
+		page, pageSize   int
+		want             user.ListFilter
+		wantPage, wantPS int
+	}{
+		{"defaults", "", 0, 0, user.ListFilter{Limit: 20}, 1, 20},
+		{"third page", "Maria", 3, 10, user.ListFilter{Search: "Maria", Limit: 10, Offset: 20}, 3, 10},
+		{"page size capped", "", 1, 1000, user.ListFilter{Limit: 100}, 1, 100},
+		{"page capped", "", math.MaxInt, 100, user.ListFilter{Limit: 100, Offset: (MaxPage - 1) * 100}, MaxPage, 100},
+		{"formatted cpf", " 123.456-7 ", 1, 10, user.ListFilter{Search: "1234567", Limit: 10}, 1, 10},
+		{"name with digits", "Lote 12", 1, 10, user.ListFilter{Search: "Lote 12", Limit: 10}, 1, 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mockUserRepo{user: &models.User{ID: 1, Name: "Maria"}}
+			service := newLockoutService(repo, clock.New())
+
+			page, err := service.ListUsers(context.Background(), tc.search, tc.page, tc.pageSize)
+			if err != nil {
+				t.Fatalf("esperava sucesso, obteve erro: %v", err)
+			}
+			if repo.listFilter != tc.want {
+				t.Errorf("esperava filtro %+v, obteve %+v", tc.want, repo.listFilter)
+			}
+			if page.Page != tc.wantPage || page.PageSize != tc.wantPS || page.Total != 1 || len(page.Users) != 1 {
+				t.Errorf("página inesperada: %+v", page)
+			}
+		})
+	}
+}
+
+func TestSetUserActive(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo := &mockUserRepo{user: &models.User{ID: 2, CPF: "12345678901", Password: string(hash), Role: models.RoleAssociate}}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	service := newLockoutService(repo, clk)
+	claims := tokens.Claims{UserID: 2, Role: models.RoleAssociate, IssuedAt: time.Unix(900, 0)}
+
+	if err := service.SetUserActive(context.Background(), 1, 2, false); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if repo.user.DeactivatedAt == nil || *repo.user.DeactivatedAt != 1000 {
+		t.Errorf("esperava usuário desativado em 1000, obteve %v", repo.user.DeactivatedAt)
+	}
+	if _, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123"); err == nil || err.Error() != "conta desativada" {
+		t.Errorf("usuário desativado não deveria entrar, obteve: %v", err)
+	}
+	if err := service.AuthorizeToken(context.Background(), claims); !errors.Is(err, tokens.ErrInvalidToken) {
+		t.Errorf("token de usuário desativado deveria ser recusado, obteve: %v", err)
+	}
+
+	if err := service.SetUserActive(context.Background(), 1, 2, true); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if _, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123"); err != nil {
+		t.Errorf("usuário reativado deveria entrar: %v", err)
+	}
+	if err := service.AuthorizeToken(context.Background(), claims); err != nil {
+		t.Errorf("token de usuário reativado deveria ser aceito: %v", err)
+	}
+}
+
+func TestSetUserActive_Errors(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 1, Role: models.RoleAdmin}}
+	service := newLockoutService(repo, clock.New())
+
+	if err := service.SetUserActive(context.Background(), 1, 1, false); err == nil || err.Error() != "não é possível desativar a própria conta" {
+		t.Errorf("esperava recusa ao desativar a própria conta, obteve: %v", err)
+	}
+	if err := service.SetUserActive(context.Background(), 1, 5, false); err == nil || err.Error() != "usuário não encontrado" {
+		t.Errorf("esperava usuário não encontrado, obteve: %v", err)
+	}
+}
+
+func TestChangeRole(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 2, Role: models.RoleAssociate}}
+	service := newLockoutService(repo, clock.New())
+	oldClaims := tokens.Claims{UserID: 2, Role: models.RoleAssociate, IssuedAt: time.Now()}
+
+	if err := service.ChangeRole(context.Background(), 1, 2, models.RoleAdmin); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if repo.user.Role != models.RoleAdmin {
+		t.Errorf("esperava papel admin, obteve %q", repo.user.Role)
+	}
+	if err := service.AuthorizeToken(context.Background(), oldClaims); !errors.Is(err, tokens.ErrInvalidToken) {
+		t.Errorf("token com o papel antigo deveria ser recusado, obteve: %v", err)
+	}
+
+	testCases := []struct {
+		adminID, userID int
+		role, want      string
+	}{
+		{1, 2, "superusuario", "papel inválido"},
+		{2, 2, models.RoleAssociate, "não é possível alterar o próprio papel"},
+		{1, 5, models.RoleAdmin, "usuário não encontrado"},
+	}
+	for _, tc := range testCases {
+		if err := service.ChangeRole(context.Background(), tc.adminID, tc.userID, tc.role); err == nil || err.Error() != tc.want {
+			t.Errorf("esperava %q, obteve: %v", tc.want, err)
+		}
+	}
+}
+
+func TestAdminResetPassword(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 2, Name: "Maria", CPF: "12345678901"}}
+	resets := &mockResetRepo{}
+	notifier := &mockNotifier{}
+	service := newPasswordService(repo, resets, notifier, clock.NewFake(time.Unix(1000, 0)))
+
+	ticket, err := service.AdminResetPassword(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if repo.changedAt != 1000 || repo.newHash == "" {
+		t.Errorf("a senha atual deveria ser substituída, revogando os tokens: %+v", repo)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Token != ticket.Token || !ticket.ExpiresAt.Equal(time.Unix(2800, 0)) {
+		t.Errorf("token não notificado: %+v %+v", notifier.sent, ticket)
+	}
+
+	if err := service.ResetPassword(context.Background(), ticket.Token, "novaSenha1"); err != nil {
+		t.Fatalf("o token emitido deveria redefinir a senha: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(repo.newHash), []byte("novaSenha1")) != nil {
+		t.Error("senha não redefinida")
+	}
+
+	if _, err := service.AdminResetPassword(context.Background(), 1, 5); err == nil || err.Error() != "usuário não encontrado" {
+		t.Errorf("esperava usuário não encontrado, obteve: %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package user
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Credentials issued to the associates an import creates: a generated
+// initial password, returned in the report, or an activation token only sent
+// through the notifier and redeemed like a password reset token.
+const (
+	CredentialsPassword   = "password"
+	CredentialsActivation = "activation"
+)
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportInvalid = "invalid"
+)
+
+const maxImportRows = 10000
+
+// initialPasswordCost hashes the generated passwords. They are random with
+// about 60 bits of entropy, which a slower hash would not make harder to
+// guess, and a large import would otherwise take minutes.
+const initialPasswordCost = bcrypt.MinCost
+
+// passwordAlphabet leaves out characters easily confused when read aloud or
+// copied by hand, such as 0/o and 1/l/i.
+const passwordAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+type ImportOptions struct {
+	DryRun      bool
+	Credentials string
+}
+
+// ImportReport describes every data row of the file; Line counts the header
+// as line 1, like a spreadsheet does.
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Invalid int         `json:"invalid"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+type ImportRow struct {
+	Line     int    `json:"line"`
+	Name     string `json:"name"`
+	CPF      string `json:"cpf"`
+	Email    string `json:"email,omitempty"`
+	Weight   int    `json:"weight"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// ImportError is returned when rows are invalid; nothing was imported and
+// Report tells which rows to fix.
+type ImportError struct {
+	Report *ImportReport
+}
+
+func (e *ImportError) Error() string {
+	return "arquivo com linhas inválidas"
+}
+
+// importColumns maps the accepted header names, in Portuguese or English,
+// to the fields they fill.
+var importColumns = map[string]string{
+	"nome":   "name",
+	"name":   "name",
+	"cpf":    "cpf",
+	"email":  "email",
+	"e-mail": "email",
+	"peso":   "weight",
+	"weight": "weight",
+	"cota":   "weight",
+	"quota":  "weight",
+}
+
+// ImportUsers registers the associates listed in a CSV file with the
+// columns nome, cpf and, optionally, email and peso (or cota). CPFs already
+// registered have their name, email and weight updated; their password and
+// role are kept. The import is all or nothing: any invalid row yields an
+// ImportError and no change. A dry run only reports what would be done,
+// invalid rows included.
+func (s *userService) ImportUsers(ctx context.Context, adminID int, file io.Reader, opts ImportOptions) (_ *ImportReport, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ImportUsers")
+	defer func() { tracing.End(span, err) }()
+
+	if opts.Credentials == "" {
+		opts.Credentials = CredentialsActivation
+	}
+	if opts.Credentials != CredentialsPassword && opts.Credentials != CredentialsActivation {
+		return nil, errors.New("credentials deve ser password ou activation")
+	}
+
+	rows, err := parseImportFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Rows: rows}
+	existing := map[string]int{}
+	seen := map[string]int{}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.Status == ImportInvalid {
+			report.Invalid++
+			continue
+		}
+		if line, ok := seen[row.CPF]; ok {
+			row.Status, row.Error = ImportInvalid, fmt.Sprintf("CPF repetido na linha %d", line)
+			report.Invalid++
+			continue
+		}
+		seen[row.CPF] = row.Line
+		if u := s.repo.GetUserByCPF(ctx, row.CPF); u != nil {
+			existing[row.CPF] = u.ID
+			row.Status = ImportUpdated
+			report.Updated++
+		} else {
+			row.Status = ImportCreated
+			report.Created++
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	if report.Invalid > 0 {
+		return nil, &ImportError{Report: report}
+	}
+
+	now := s.clock.Now()
+	var activations []notify.Notification
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		activations = nil
+		for i := range report.Rows {
+			row := &report.Rows[i]
+			if row.Status == ImportUpdated {
+				if err := s.repo.UpdateMembership(ctx, existing[row.CPF], row.Name, row.Email, row.Weight); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Activated accounts have no password until the token is
+			// redeemed: an empty hash never matches.
+			var hash []byte
+			if opts.Credentials == CredentialsPassword {
+				password, err := s.initialPassword()
+				if err != nil {
+					return err
+				}
+				if hash, err = bcrypt.GenerateFromPassword([]byte(password), initialPasswordCost); err != nil {
+					return err
+				}
+				row.Password = password
+			}
+			err := s.repo.AddUser(ctx, models.User{Name: row.Name, CPF: row.CPF, Email: row.Email, Weight: row.Weight, Password: string(hash), Role: models.RoleAssociate})
+			if err != nil {
+				return err
+			}
+			if opts.Credentials == CredentialsActivation {
+				u := s.repo.GetUserByCPF(ctx, row.CPF)
+				if u == nil {
+					return errors.New("usuário importado não encontrado")
+				}
+				token, expiresAt, err := s.createResetToken(ctx, u.ID, now, s.activationTTL)
+				if err != nil {
+					return err
+				}
+				activations = append(activations, notify.Notification{
+					Type:      notify.TypeActivation,
+					UserID:    u.ID,
+					Name:      u.Name,
+					CPF:       u.CPF,
+					Email:     u.Email,
+					Token:     token,
+					ExpiresAt: expiresAt,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range activations {
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			logger.FromContext(ctx).Error("erro ao enviar token de ativação", "user_id", n.UserID, "error", err)
+		}
+	}
+	logger.FromContext(ctx).Info("associados importados", "created", report.Created, "updated", report.Updated, "credentials", opts.Credentials, "admin_id", adminID)
+	return report, nil
+}
+
+// parseImportFile reads the rows of the file, marking the ones with invalid
+// fields. Spreadsheets often export with ";" as separator, so it is accepted
+// when the header has no ",".
+func parseImportFile(file io.Reader) ([]ImportRow, error) {
+	buf := bufio.NewReader(file)
+	header, err := buf.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	header = strings.TrimPrefix(header, "\ufeff")
+	reader := csv.NewReader(io.MultiReader(strings.NewReader(header), buf))
+	if strings.Contains(header, ";") && !strings.Contains(header, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	names, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("arquivo vazio")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CSV inválido: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range names {
+		field, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("coluna desconhecida: %q", name)
+		}
+		if _, dup := columns[field]; dup {
+			return nil, fmt.Errorf("coluna repetida: %q", name)
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("coluna nome é obrigatória")
+	}
+	if _, ok := columns["cpf"]; !ok {
+		return nil, errors.New("coluna cpf é obrigatória")
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV inválido: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("o arquivo deve ter no máximo %d associados", maxImportRows)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, parseImportRow(line, field("name"), field("cpf"), field("email"), field("weight")))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("arquivo sem associados")
+	}
+	return rows, nil
+}
+
+func parseImportRow(line int, name, cpf, email, weight string) ImportRow {
+	row := ImportRow{Line: line, Name: name, CPF: cpf, Email: email, Weight: models.DefaultWeight, Status: ImportInvalid}
+	if digits := digitsOnly(cpf); strings.Trim(cpf, "0123456789.- ") == "" && isValidCPF(digits) {
+		row.CPF = digits
+	} else {
+		row.Error = "cpf inválido"
+		return row
+	}
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		row.Error = "nome deve ter entre 1 e 100 caracteres"
+		return row
+	}
+	if email != "" {
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			row.Error = "email inválido"
+			return row
+		}
+	}
+	if weight != "" {
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 1 {
+			row.Error = "peso deve ser um número inteiro positivo"
+			return row
+		}
+		row.Weight = w
+	}
+	row.Status = ""
+	return row
+}
+
+// initialPassword generates a password like "k3ft-9qwe-hn2a", as long as the
+// policy's minimum; the dashes satisfy RequireSymbol.
+func (s *userService) initialPassword() (string, error) {
+	length := max(14, s.passwords.MinLength)
+	for range 100 {
+		password := make([]byte, 0, length)
+		for len(password) < length {
+			if len(password)%5 == 4 {
+				password = append(password, '-')
+				continue
+			}
+			c, err := randomIndex(len(passwordAlphabet))
+			if err != nil {
+				return "", err
+			}
+			password = append(password, passwordAlphabet[c])
+		}
+		if s.passwords.Check(string(password)) == nil {
+			return string(password), nil
+		}
+	}
+	return "", errors.New("não foi possível gerar uma senha que atenda à política")
+}
+
+// randomIndex returns a uniform random number below n, which must be at most
+// 256, discarding the bytes that would bias the result.
+func randomIndex(n int) (int, error) {
+	limit := 256 - 256%n
+	b := make([]byte, 1)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return 0, err
+		}
+		if int(b[0]) < limit {
+			return int(b[0]) % n, nil
+		}
+	}
+}
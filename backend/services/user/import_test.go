@@ -0,0 +1,199 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// importUserRepo keeps several users; the methods an import does not use
+// are left to the nil embedded interface.
+type importUserRepo struct {
+	user.UserRepository
+	users []models.User
+}
+
+func (m *importUserRepo) GetUserByCPF(ctx context.Context, cpf string) *models.User {
+	i := slices.IndexFunc(m.users, func(u models.User) bool { return u.CPF == cpf })
+	if i < 0 {
+		return nil
+	}
+	u := m.users[i]
+	return &u
+}
+
+func (m *importUserRepo) AddUser(ctx context.Context, u models.User) error {
+	u.ID = len(m.users) + 1
+	m.users = append(m.users, u)
+	return nil
+}
+
+func (m *importUserRepo) UpdateMembership(ctx context.Context, userID int, name, email string, weight int) error {
+	u := &m.users[userID-1]
+	u.Name, u.Email, u.Weight = name, email, weight
+	return nil
+}
+
+func newImportService(repo *importUserRepo, resets *mockResetRepo, notifier *mockNotifier) *userService {
+	s := newPasswordService(nil, resets, notifier, clock.NewFake(time.Unix(1000, 0)))
+	s.repo = repo
+	s.activationTTL = 7 * 24 * time.Hour
+	return s
+}
+
+const importFile = `nome,cpf,email,peso
+Ana Souza,111.111.111-11,ana@exemplo.com,3
+Bruno Lima,22222222222,,
+`
+
+func TestImportUsers_Activation(t *testing.T) {
+	repo := &importUserRepo{users: []models.User{{ID: 1, Name: "Bruno", CPF: "22222222222", Weight: 1, Password: "hash", Role: models.RoleAdmin}}}
+	resets := &mockResetRepo{}
+	notifier := &mockNotifier{}
+	service := newImportService(repo, resets, notifier)
+
+	report, err := service.ImportUsers(context.Background(), 1, strings.NewReader(importFile), ImportOptions{})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if report.Created != 1 || report.Updated != 1 || report.Invalid != 0 {
+		t.Errorf("relatório inesperado: %+v", report)
+	}
+
+	ana := repo.GetUserByCPF(context.Background(), "11111111111")
+	if ana == nil || ana.Name != "Ana Souza" || ana.Email != "ana@exemplo.com" || ana.Weight != 3 || ana.Password != "" || ana.Role != models.RoleAssociate {
+		t.Fatalf("associado importado incorreto: %+v", ana)
+	}
+	bruno := repo.GetUserByCPF(context.Background(), "22222222222")
+	if bruno.Name != "Bruno Lima" || bruno.Weight != models.DefaultWeight || bruno.Password != "hash" || bruno.Role != models.RoleAdmin {
+		t.Errorf("a atualização deveria manter senha e papel: %+v", bruno)
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("esperava uma notificação de ativação, obteve %+v", notifier.sent)
+	}
+	sent := notifier.sent[0]
+	if sent.Type != notify.TypeActivation || sent.UserID != ana.ID || sent.Email != "ana@exemplo.com" || !sent.ExpiresAt.Equal(time.Unix(1000, 0).Add(7*24*time.Hour)) {
+		t.Errorf("notificação inesperada: %+v", sent)
+	}
+	if data, _ := json.Marshal(report); strings.Contains(string(data), sent.Token) {
+		t.Errorf("o token de ativação só deveria seguir pelo notificador: %s", data)
+	}
+	if len(resets.tokens) != 1 || resets.tokens[0].TokenHash != hashToken(sent.Token) {
+		t.Errorf("token de ativação não registrado: %+v", resets.tokens)
+	}
+}
+
+func TestImportUsers_InitialPasswords(t *testing.T) {
+	repo := &importUserRepo{}
+	notifier := &mockNotifier{}
+	service := newImportService(repo, &mockResetRepo{}, notifier)
+	service.passwords.RequireSymbol = true
+
+	file := "CPF;Nome\n11111111111;Ana\n22222222222;Bruno\n"
+	report, err := service.ImportUsers(context.Background(), 1, strings.NewReader(file), ImportOptions{Credentials: CredentialsPassword})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if report.Created != 2 || len(notifier.sent) != 0 {
+		t.Errorf("relatório inesperado: %+v (notificações: %d)", report, len(notifier.sent))
+	}
+	for i, row := range report.Rows {
+		if err := service.passwords.Check(row.Password); err != nil {
+			t.Errorf("senha inicial %q fora da política: %v", row.Password, err)
+		}
+		if bcrypt.CompareHashAndPassword([]byte(repo.users[i].Password), []byte(row.Password)) != nil {
+			t.Errorf("senha inicial da linha %d não confere com o hash", row.Line)
+		}
+	}
+	if report.Rows[0].Password == report.Rows[1].Password {
+		t.Error("as senhas iniciais deveriam ser diferentes")
+	}
+}
+
+func TestImportUsers_InvalidRows(t *testing.T) {
+	file := strings.Join([]string{
+		"nome,cpf,email,peso",
+		"Ana,11111111111,ana@exemplo.com,1",
+		"Sem CPF,,,",
+		"CPF com letras,1111111111a,,",
+		",33333333333,,",
+		"Email,44444444444,nao-e-email,",
+		"Peso,55555555555,,zero",
+		"Repetido,111.111.111-11,,",
+	}, "\n")
+	wantErrors := []string{"", "cpf inválido", "cpf inválido", "nome deve ter entre 1 e 100 caracteres", "email inválido", "peso deve ser um número inteiro positivo", "CPF repetido na linha 2"}
+
+	repo := &importUserRepo{}
+	service := newImportService(repo, &mockResetRepo{}, &mockNotifier{})
+
+	_, err := service.ImportUsers(context.Background(), 1, strings.NewReader(file), ImportOptions{})
+	var importErr *ImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("esperava ImportError, obteve %v", err)
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("nenhum associado deveria ser importado: %+v", repo.users)
+	}
+	report := importErr.Report
+	if report.Invalid != 6 || report.Created != 1 {
+		t.Errorf("relatório inesperado: %+v", report)
+	}
+	for i, row := range report.Rows {
+		if row.Line != i+2 || row.Error != wantErrors[i] {
+			t.Errorf("linha %d: esperava erro %q, obteve %q", row.Line, wantErrors[i], row.Error)
+		}
+	}
+}
+
+func TestImportUsers_DryRun(t *testing.T) {
+	repo := &importUserRepo{users: []models.User{{ID: 1, Name: "Bruno", CPF: "22222222222"}}}
+	notifier := &mockNotifier{}
+	service := newImportService(repo, &mockResetRepo{}, notifier)
+
+	file := importFile + "Inválido,123,,\n"
+	report, err := service.ImportUsers(context.Background(), 1, strings.NewReader(file), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if !report.DryRun || report.Created != 1 || report.Updated != 1 || report.Invalid != 1 {
+		t.Errorf("relatório inesperado: %+v", report)
+	}
+	if len(repo.users) != 1 || repo.users[0].Name != "Bruno" || len(notifier.sent) != 0 {
+		t.Errorf("a simulação não deveria alterar nada: %+v", repo.users)
+	}
+}
+
+func TestImportUsers_InvalidFile(t *testing.T) {
+	testCases := []struct {
+		name, file, opts, want string
+	}{
+		{"empty", "", "", "arquivo vazio"},
+		{"header only", "nome,cpf\n", "", "arquivo sem associados"},
+		{"missing cpf column", "nome,email\nAna,ana@exemplo.com\n", "", "coluna cpf é obrigatória"},
+		{"unknown column", "nome,cpf,telefone\n", "", `coluna desconhecida: "telefone"`},
+		{"unknown credentials", "nome,cpf\nAna,11111111111\n", "sms", "credentials deve ser password ou activation"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := newImportService(&importUserRepo{}, &mockResetRepo{}, &mockNotifier{})
+
+			_, err := service.ImportUsers(context.Background(), 1, strings.NewReader(tc.file), ImportOptions{Credentials: tc.opts})
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("esperava %q, obteve %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxNameLength = 100
+
+// Profile is what users see about their own account.
+type Profile struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	CPF              string `json:"cpf"`
+	Role             string `json:"role"`
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+}
+
+func (s *userService) GetProfile(ctx context.Context, userID int) (_ *Profile, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetProfile")
+	defer func() { tracing.End(span, err) }()
+
+	u := s.repo.GetUserByID(ctx, userID)
+	if u == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	enrollment, err := s.twoFactor.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return &Profile{
+		ID:               u.ID,
+		Name:             u.Name,
+		CPF:              u.CPF,
+		Role:             u.Role,
+		TwoFactorEnabled: enrollment != nil && enrollment.EnabledAt != nil,
+	}, nil
+}
+
+func (s *userService) UpdateName(ctx context.Context, userID int, name string) (_ *Profile, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateName")
+	defer func() { tracing.End(span, err) }()
+
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return nil, errors.New("nome deve ter entre 1 e 100 caracteres")
+	}
+	err = s.repo.UpdateName(ctx, userID, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("usuário não encontrado")
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("nome alterado", "user_id", userID)
+	return s.GetProfile(ctx, userID)
+}
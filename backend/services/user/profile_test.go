@@ -0,0 +1,52 @@
+package user
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+)
+
+func TestGetProfile(t *testing.T) {
+	repo, twoFactor := enrolledUser(t)
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+
+	profile, err := service.GetProfile(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if profile.CPF != "12345678901" || profile.Role != models.RoleAdmin || !profile.TwoFactorEnabled {
+		t.Errorf("perfil inesperado: %+v", profile)
+	}
+
+	if _, err := service.GetProfile(context.Background(), 2); err == nil || err.Error() != "usuário não encontrado" {
+		t.Errorf("esperava erro de usuário não encontrado, obteve %v", err)
+	}
+}
+
+func TestUpdateName(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 1, Name: "Maria", CPF: "12345678901"}}
+	service := newTwoFactorService(repo, &mockTwoFactorRepo{}, clock.NewFake(twoFactorNow))
+
+	profile, err := service.UpdateName(context.Background(), 1, "  Maria Souza ")
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if profile.Name != "Maria Souza" || repo.user.Name != "Maria Souza" {
+		t.Errorf("esperava nome sem espaços nas pontas, obteve %q", profile.Name)
+	}
+	if profile.TwoFactorEnabled {
+		t.Error("usuário não ativou a verificação em duas etapas")
+	}
+
+	for _, name := range []string{"", "   ", strings.Repeat("á", maxNameLength+1)} {
+		if _, err := service.UpdateName(context.Background(), 1, name); err == nil || err.Error() != "nome deve ter entre 1 e 100 caracteres" {
+			t.Errorf("esperava recusa do nome %q, obteve %v", name, err)
+		}
+	}
+	if _, err := service.UpdateName(context.Background(), 2, "Outro"); err == nil || err.Error() != "usuário não encontrado" {
+		t.Errorf("esperava erro de usuário não encontrado, obteve %v", err)
+	}
+}
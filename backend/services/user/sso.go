@@ -0,0 +1,185 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/oidc"
+	"desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/tokens"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SSOService logs users in through the cooperative's OpenID Connect
+// provider. The provider is trusted with every factor: password lockout and
+// two-factor authentication only apply to logins with a password.
+type SSOService interface {
+	// StartLogin returns the provider URL the browser must be sent to and
+	// the state it carries, which the caller binds to the browser.
+	StartLogin(ctx context.Context) (authURL, state string, err error)
+	// CompleteLogin takes the code and state the provider sent back and
+	// returns an access token like AuthenticateUser.
+	CompleteLogin(ctx context.Context, code, state string) (string, *models.User, error)
+}
+
+// SSOOptions.CPFClaim is the ID token claim holding the CPF; "sub" uses the
+// subject. AutoProvision registers unknown CPFs as associates instead of
+// refusing them.
+type SSOOptions struct {
+	CPFClaim      string
+	AutoProvision bool
+	StateTTL      time.Duration
+}
+
+type ssoService struct {
+	provider    oidc.Provider
+	states      oidcstate.OIDCStateRepository
+	repo        user.UserRepository
+	clock       clock.Clock
+	opts        SSOOptions
+	generateJWT func(userID int, role string, twoFactor bool) (string, error)
+}
+
+func NewSSOService(provider oidc.Provider, states oidcstate.OIDCStateRepository, repo user.UserRepository, tokens tokens.Manager, clock clock.Clock, opts SSOOptions) SSOService {
+	return &ssoService{
+		provider:    provider,
+		states:      states,
+		repo:        repo,
+		clock:       clock,
+		opts:        opts,
+		generateJWT: tokens.Generate,
+	}
+}
+
+func (s *ssoService) StartLogin(ctx context.Context) (_, _ string, err error) {
+	ctx, span := tracer.Start(ctx, "SSOService.StartLogin")
+	defer func() { tracing.End(span, err) }()
+
+	var state, nonce, verifier string
+	for _, v := range []*string{&state, &nonce, &verifier} {
+		if *v, err = newRandomToken(); err != nil {
+			return "", "", err
+		}
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		logger.FromContext(ctx).Error("erro ao iniciar login SSO", "error", err)
+		return "", "", errors.New("provedor de identidade indisponível")
+	}
+
+	now := s.clock.Now()
+	if err := s.states.DeleteExpiredLoginStates(ctx, now.Unix()); err != nil {
+		return "", "", err
+	}
+	err = s.states.CreateLoginState(ctx, models.OIDCLoginState{
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    now.Add(s.opts.StateTTL).Unix(),
+		CreatedAt:    now.Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+func (s *ssoService) CompleteLogin(ctx context.Context, code, state string) (_ string, _ *models.User, err error) {
+	ctx, span := tracer.Start(ctx, "SSOService.CompleteLogin")
+	defer func() { tracing.End(span, err) }()
+
+	pending, err := s.states.UseLoginState(ctx, hashToken(state), s.clock.Now().Unix())
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil, errors.New("login SSO inválido ou expirado")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	idToken, err := s.provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginFailureSSO).Inc()
+		logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureSSO, "error", err)
+		return "", nil, errors.New("falha na autenticação com o provedor de identidade")
+	}
+
+	cpf := idToken.String(s.opts.CPFClaim)
+	if s.opts.CPFClaim == "sub" {
+		cpf = idToken.Subject
+	}
+	cpf = digitsOnly(cpf)
+	if !isValidCPF(cpf) {
+		logger.FromContext(ctx).Warn("provedor de identidade sem CPF válido", "claim", s.opts.CPFClaim, "subject", idToken.Subject)
+		return "", nil, errors.New("provedor de identidade não informou um CPF válido")
+	}
+
+	u := s.repo.GetUserByCPF(ctx, cpf)
+	if u == nil {
+		if !s.opts.AutoProvision {
+			metrics.LoginFailures.WithLabelValues(metrics.LoginFailureUnknownUser).Inc()
+			logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureUnknownUser, "subject", idToken.Subject)
+			return "", nil, errors.New("usuário não cadastrado")
+		}
+		if u, err = s.provision(ctx, cpf, idToken.String("name")); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := checkActive(ctx, u); err != nil {
+		return "", nil, err
+	}
+
+	token, err := s.generateJWT(u.ID, u.Role, true)
+	if err != nil {
+		return "", nil, err
+	}
+	logger.FromContext(ctx).Info("login SSO", "user_id", u.ID)
+	return token, u, nil
+}
+
+// provision registers a user seen for the first time at the provider. The
+// password is random and never revealed, so the account is only reachable
+// through SSO until the user resets it.
+func (s *ssoService) provision(ctx context.Context, cpf, name string) (*models.User, error) {
+	if name == "" {
+		name = cpf
+	}
+	password, err := newRandomToken()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.AddUser(ctx, models.User{Name: name, CPF: cpf, Weight: models.DefaultWeight, Password: string(hash), Role: models.RoleAssociate})
+	// A concurrent login may have registered the user first.
+	if err != nil && !strings.Contains(err.Error(), "duplicate key") {
+		return nil, err
+	}
+	u := s.repo.GetUserByCPF(ctx, cpf)
+	if u == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	logger.FromContext(ctx).Info("usuário cadastrado via SSO", "user_id", u.ID)
+	return u, nil
+}
+
+// digitsOnly accepts CPFs formatted like 123.456.789-01.
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, s)
+}
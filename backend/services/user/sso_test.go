@@ -0,0 +1,180 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/oidc"
+	"desafio-tecnico-fullstack/backend/oidc/oidctest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockOIDCStateRepo struct {
+	states map[string]models.OIDCLoginState
+}
+
+func (m *mockOIDCStateRepo) CreateLoginState(ctx context.Context, s models.OIDCLoginState) error {
+	m.states[s.StateHash] = s
+	return nil
+}
+
+func (m *mockOIDCStateRepo) UseLoginState(ctx context.Context, stateHash string, now int64) (*models.OIDCLoginState, error) {
+	s, ok := m.states[stateHash]
+	delete(m.states, stateHash)
+	if !ok || s.ExpiresAt <= now {
+		return nil, sql.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (m *mockOIDCStateRepo) DeleteExpiredLoginStates(ctx context.Context, now int64) error {
+	return nil
+}
+
+// newSSOService talks to a mock OpenID Connect provider, so the whole flow,
+// PKCE and ID token checks included, runs as in production.
+func newSSOService(t *testing.T, repo *mockUserRepo, clk clock.Clock, opts SSOOptions) (*ssoService, *oidctest.Provider) {
+	t.Helper()
+	idp := oidctest.NewProvider(t)
+	provider := oidc.New(config.OIDCConfig{
+		IssuerURL:    idp.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/login/oidc",
+		Scopes:       []string{"openid", "profile"},
+		HTTPTimeout:  time.Second,
+	}, clock.New())
+	if opts.CPFClaim == "" {
+		opts.CPFClaim = "cpf"
+	}
+	opts.StateTTL = 10 * time.Minute
+	return &ssoService{
+		provider:    provider,
+		states:      &mockOIDCStateRepo{states: map[string]models.OIDCLoginState{}},
+		repo:        repo,
+		clock:       clk,
+		opts:        opts,
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "jwt", nil },
+	}, idp
+}
+
+// ssoLogin goes through the provider and returns the callback parameters.
+func ssoLogin(t *testing.T, s *ssoService, idp *oidctest.Provider, claims map[string]any) (code, state string) {
+	t.Helper()
+	authURL, _, err := s.StartLogin(context.Background())
+	if err != nil {
+		t.Fatalf("erro ao iniciar login SSO: %v", err)
+	}
+	return idp.Login(t, authURL, claims)
+}
+
+func TestSSO_LoginMapsCPFClaimToUser(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 7, Name: "Maria", CPF: "12345678901", Role: models.RoleAdmin}}
+	s, idp := newSSOService(t, repo, clock.New(), SSOOptions{})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "maria", "cpf": "123.456.789-01"})
+	token, u, err := s.CompleteLogin(context.Background(), code, state)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if token != "jwt" || u.ID != 7 || u.Role != models.RoleAdmin {
+		t.Errorf("login inesperado: %q %+v", token, u)
+	}
+
+	if _, _, err := s.CompleteLogin(context.Background(), code, state); err == nil || err.Error() != "login SSO inválido ou expirado" {
+		t.Errorf("o mesmo retorno do provedor não deveria ser aceito duas vezes, obteve: %v", err)
+	}
+}
+
+func TestSSO_DeactivatedUser(t *testing.T) {
+	deactivatedAt := int64(1000)
+	repo := &mockUserRepo{user: &models.User{ID: 7, CPF: "12345678901", Role: models.RoleAssociate, DeactivatedAt: &deactivatedAt}}
+	s, idp := newSSOService(t, repo, clock.New(), SSOOptions{})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "maria", "cpf": "12345678901"})
+	if _, _, err := s.CompleteLogin(context.Background(), code, state); err == nil || err.Error() != "conta desativada" {
+		t.Errorf("esperava conta desativada, obteve: %v", err)
+	}
+}
+
+func TestSSO_SubjectAsCPF(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 7, CPF: "12345678901", Role: models.RoleAssociate}}
+	s, idp := newSSOService(t, repo, clock.New(), SSOOptions{CPFClaim: "sub"})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "12345678901"})
+	if _, _, err := s.CompleteLogin(context.Background(), code, state); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+}
+
+func TestSSO_UnknownUser(t *testing.T) {
+	s, idp := newSSOService(t, &mockUserRepo{}, clock.New(), SSOOptions{})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "joao", "cpf": "10987654321"})
+	if _, _, err := s.CompleteLogin(context.Background(), code, state); err == nil || err.Error() != "usuário não cadastrado" {
+		t.Errorf("esperava usuário não cadastrado, obteve: %v", err)
+	}
+}
+
+func TestSSO_AutoProvision(t *testing.T) {
+	repo := &mockUserRepo{}
+	s, idp := newSSOService(t, repo, clock.New(), SSOOptions{AutoProvision: true})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "joao", "cpf": "10987654321", "name": "João"})
+	_, u, err := s.CompleteLogin(context.Background(), code, state)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if u.CPF != "10987654321" || u.Name != "João" || u.Role != models.RoleAssociate {
+		t.Errorf("usuário criado inesperado: %+v", u)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("")) == nil {
+		t.Error("usuário criado via SSO não deveria ter senha vazia")
+	}
+}
+
+func TestSSO_Rejections(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims map[string]any
+		code   func(code string) string
+		want   string
+	}{
+		{"invalid CPF claim", map[string]any{"sub": "x", "cpf": "123"}, nil, "provedor de identidade não informou um CPF válido"},
+		{"missing CPF claim", map[string]any{"sub": "x"}, nil, "provedor de identidade não informou um CPF válido"},
+		{"code refused by the provider", map[string]any{"sub": "x", "cpf": "12345678901"}, func(string) string { return "forjado" }, "falha na autenticação com o provedor de identidade"},
+		{"token for another client", map[string]any{"sub": "x", "cpf": "12345678901", "aud": "outro"}, nil, "falha na autenticação com o provedor de identidade"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mockUserRepo{user: &models.User{ID: 7, CPF: "12345678901"}}
+			s, idp := newSSOService(t, repo, clock.New(), SSOOptions{})
+
+			code, state := ssoLogin(t, s, idp, tc.claims)
+			if tc.code != nil {
+				code = tc.code(code)
+			}
+			if _, _, err := s.CompleteLogin(context.Background(), code, state); err == nil || err.Error() != tc.want {
+				t.Errorf("esperava %q, obteve: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSSO_StateExpires(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	repo := &mockUserRepo{user: &models.User{ID: 7, CPF: "12345678901"}}
+	s, idp := newSSOService(t, repo, clk, SSOOptions{})
+
+	code, state := ssoLogin(t, s, idp, map[string]any{"sub": "x", "cpf": "12345678901"})
+	clk.Advance(11 * time.Minute)
+	if _, _, err := s.CompleteLogin(context.Background(), code, state); err == nil || err.Error() != "login SSO inválido ou expirado" {
+		t.Errorf("esperava login expirado, obteve: %v", err)
+	}
+}
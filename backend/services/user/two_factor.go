@@ -0,0 +1,207 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/totp"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+// totpSkew also accepts the codes of the previous and next steps, tolerating
+// some drift between the server and the authenticator clocks.
+const totpSkew = 1
+
+type TwoFactorOptions struct {
+	Issuer       string
+	ChallengeTTL time.Duration
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorRequiredError is returned by AuthenticateUser, instead of a token,
+// when the password is right but the user has two-factor authentication
+// enabled. The challenge token is exchanged for an access token by
+// VerifyTwoFactor.
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresIn      time.Duration
+}
+
+func (e *TwoFactorRequiredError) Error() string {
+	return "verificação em duas etapas necessária"
+}
+
+// EnrollTOTP starts an enrollment with a new secret. It only protects the
+// login after ConfirmTOTP, so an abandoned enrollment locks nobody out.
+func (s *userService) EnrollTOTP(ctx context.Context, userID int) (_ *TOTPEnrollment, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.EnrollTOTP")
+	defer func() { tracing.End(span, err) }()
+
+	u := s.repo.GetUserByID(ctx, userID)
+	if u == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	current, err := s.twoFactor.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if current != nil && current.EnabledAt != nil {
+		return nil, errors.New("verificação em duas etapas já está ativada")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.SaveTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollment{Secret: secret, URI: totp.URI(secret, s.twoFactorOpts.Issuer, u.CPF)}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user shows a code
+// from the enrolled authenticator, and returns the recovery codes. They are
+// shown only this once; just their hashes are kept.
+func (s *userService) ConfirmTOTP(ctx context.Context, userID int, code string) (_ []string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ConfirmTOTP")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.twoFactor.GetTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("verificação em duas etapas não iniciada")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.EnabledAt != nil {
+		return nil, errors.New("verificação em duas etapas já está ativada")
+	}
+	now := s.clock.Now()
+	step, ok := totp.Validate(current.Secret, code, now, totpSkew)
+	if !ok {
+		return nil, errors.New("código inválido")
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.twoFactor.UseTOTPStep(ctx, userID, step); err != nil {
+			return err
+		}
+		if err := s.twoFactor.EnableTOTP(ctx, userID, now.Unix()); err != nil {
+			return err
+		}
+		return s.twoFactor.ReplaceRecoveryCodes(ctx, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx).Info("verificação em duas etapas ativada", "user_id", userID)
+	return codes, nil
+}
+
+// VerifyTwoFactor completes a login started by AuthenticateUser. code is
+// either a TOTP code or one of the recovery codes. Wrong codes count as login
+// failures, so the lockout also bounds guessing.
+func (s *userService) VerifyTwoFactor(ctx context.Context, challengeToken, code string) (_ string, _ *models.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.VerifyTwoFactor")
+	defer func() { tracing.End(span, err) }()
+
+	userID, err := s.validateChallenge(challengeToken)
+	if err != nil {
+		return "", nil, errors.New("desafio inválido ou expirado")
+	}
+	u := s.repo.GetUserByID(ctx, userID)
+	if u == nil {
+		return "", nil, errors.New("desafio inválido ou expirado")
+	}
+	if err := s.checkLocked(ctx, u); err != nil {
+		return "", nil, err
+	}
+	if err := checkActive(ctx, u); err != nil {
+		return "", nil, err
+	}
+	current, err := s.twoFactor.GetTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && current.EnabledAt == nil) {
+		return "", nil, errors.New("desafio inválido ou expirado")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = s.checkSecondFactor(ctx, current, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginFailureWrongCode).Inc()
+		logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureWrongCode, "user_id", u.ID)
+		if err := s.recordFailure(ctx, u.ID); err != nil {
+			return "", nil, err
+		}
+		return "", nil, errors.New("código inválido")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return s.completeLogin(ctx, u, true)
+}
+
+// checkSecondFactor consumes the TOTP step or recovery code, yielding
+// sql.ErrNoRows when the code is wrong or was already used.
+func (s *userService) checkSecondFactor(ctx context.Context, current *models.UserTOTP, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(current.Secret, code, s.clock.Now(), totpSkew)
+		if !ok {
+			return sql.ErrNoRows
+		}
+		return s.twoFactor.UseTOTPStep(ctx, current.UserID, step)
+	}
+	err := s.twoFactor.UseRecoveryCode(ctx, current.UserID, hashRecoveryCode(code), s.clock.Now().Unix())
+	if err == nil {
+		logger.FromContext(ctx).Warn("código de recuperação usado", "user_id", current.UserID)
+	}
+	return err
+}
+
+// newRecoveryCodes returns codes formatted for display, like "ab3de-fg7hi",
+// and their hashes for storage.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which users often get
+// wrong when copying the codes.
+func hashRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+	return hashToken(normalized)
+}
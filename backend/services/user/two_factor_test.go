@@ -0,0 +1,219 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/totp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockTwoFactorRepo struct {
+	totp          *models.UserTOTP
+	recoveryCodes []string
+	usedCodes     []string
+}
+
+func (m *mockTwoFactorRepo) GetTOTP(ctx context.Context, userID int) (*models.UserTOTP, error) {
+	if m.totp == nil {
+		return nil, sql.ErrNoRows
+	}
+	t := *m.totp
+	return &t, nil
+}
+
+func (m *mockTwoFactorRepo) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	m.totp = &models.UserTOTP{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (m *mockTwoFactorRepo) EnableTOTP(ctx context.Context, userID int, enabledAt int64) error {
+	m.totp.EnabledAt = &enabledAt
+	return nil
+}
+
+func (m *mockTwoFactorRepo) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	if m.totp.LastUsedStep >= step {
+		return sql.ErrNoRows
+	}
+	m.totp.LastUsedStep = step
+	return nil
+}
+
+func (m *mockTwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	m.recoveryCodes = codeHashes
+	return nil
+}
+
+func (m *mockTwoFactorRepo) UseRecoveryCode(ctx context.Context, userID int, codeHash string, now int64) error {
+	if !slices.Contains(m.recoveryCodes, codeHash) || slices.Contains(m.usedCodes, codeHash) {
+		return sql.ErrNoRows
+	}
+	m.usedCodes = append(m.usedCodes, codeHash)
+	return nil
+}
+
+var twoFactorNow = time.Unix(1750000000, 0)
+
+func newTwoFactorService(repo *mockUserRepo, twoFactor *mockTwoFactorRepo, clk clock.Clock) *userService {
+	s := newLockoutService(repo, clk)
+	s.twoFactor = twoFactor
+	s.uow = &mockUnitOfWork{}
+	s.twoFactorOpts = TwoFactorOptions{Issuer: "Votação", ChallengeTTL: 5 * time.Minute}
+	s.generateChallenge = func(userID int, ttl time.Duration) (string, error) { return "challenge", nil }
+	s.validateChallenge = func(token string) (int, error) {
+		if token != "challenge" {
+			return 0, errors.New("token inválido")
+		}
+		return 1, nil
+	}
+	return s
+}
+
+// enrolledUser returns a user with two-factor authentication enabled.
+func enrolledUser(t *testing.T) (*mockUserRepo, *mockTwoFactorRepo) {
+	t.Helper()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enabledAt := twoFactorNow.Unix()
+	return &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901", Password: string(hash), Role: models.RoleAdmin}},
+		&mockTwoFactorRepo{totp: &models.UserTOTP{UserID: 1, Secret: secret, EnabledAt: &enabledAt}}
+}
+
+func TestEnrollAndConfirmTOTP(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901"}}
+	twoFactor := &mockTwoFactorRepo{}
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+
+	enrollment, err := service.EnrollTOTP(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if enrollment.Secret == "" || enrollment.URI != totp.URI(enrollment.Secret, "Votação", "12345678901") {
+		t.Errorf("cadastro inesperado: %+v", enrollment)
+	}
+
+	if _, err := service.ConfirmTOTP(context.Background(), 1, "000000"); err == nil || err.Error() != "código inválido" {
+		t.Errorf("esperava código inválido, obteve: %v", err)
+	}
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(twoFactorNow))
+	recovery, err := service.ConfirmTOTP(context.Background(), 1, code)
+	if err != nil {
+		t.Fatalf("esperava ativação, obteve erro: %v", err)
+	}
+	if len(recovery) != recoveryCodeCount || len(twoFactor.recoveryCodes) != recoveryCodeCount {
+		t.Errorf("esperava %d códigos de recuperação, obteve %v", recoveryCodeCount, recovery)
+	}
+	if slices.Contains(twoFactor.recoveryCodes, recovery[0]) {
+		t.Error("códigos de recuperação não deveriam ser guardados em texto")
+	}
+	if twoFactor.totp.EnabledAt == nil {
+		t.Error("verificação em duas etapas deveria estar ativa")
+	}
+
+	if _, err := service.EnrollTOTP(context.Background(), 1); err == nil || err.Error() != "verificação em duas etapas já está ativada" {
+		t.Errorf("não deveria recadastrar com a verificação ativa, obteve: %v", err)
+	}
+}
+
+func TestConfirmTOTP_NotEnrolled(t *testing.T) {
+	service := newTwoFactorService(&mockUserRepo{}, &mockTwoFactorRepo{}, clock.NewFake(twoFactorNow))
+
+	_, err := service.ConfirmTOTP(context.Background(), 1, "123456")
+	if err == nil || err.Error() != "verificação em duas etapas não iniciada" {
+		t.Errorf("esperava erro de cadastro não iniciado, obteve: %v", err)
+	}
+}
+
+func TestAuthenticateUser_RequiresSecondFactor(t *testing.T) {
+	repo, twoFactor := enrolledUser(t)
+	repo.user.FailedLoginAttempts = 2
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+	var verified bool
+	service.generateJWT = func(userID int, role string, twoFactor bool) (string, error) {
+		verified = twoFactor
+		return "token123", nil
+	}
+
+	_, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123")
+	var required *TwoFactorRequiredError
+	if !errors.As(err, &required) || required.ChallengeToken != "challenge" || required.ExpiresIn != 5*time.Minute {
+		t.Fatalf("esperava desafio de segunda etapa, obteve: %v", err)
+	}
+	if repo.resets != 0 {
+		t.Error("falhas só deveriam ser zeradas após a segunda etapa")
+	}
+
+	code, _ := totp.Code(twoFactor.totp.Secret, totp.Step(twoFactorNow))
+	token, u, err := service.VerifyTwoFactor(context.Background(), "challenge", code)
+	if err != nil || token != "token123" || u.ID != 1 {
+		t.Fatalf("esperava login completo, obteve %q %v (erro: %v)", token, u, err)
+	}
+	if !verified {
+		t.Error("o token deveria registrar a verificação em duas etapas")
+	}
+	if repo.resets != 1 {
+		t.Errorf("esperava falhas zeradas após login, obteve %d resets", repo.resets)
+	}
+
+	if _, _, err := service.VerifyTwoFactor(context.Background(), "challenge", code); err == nil || err.Error() != "código inválido" {
+		t.Errorf("código já usado não deveria valer de novo, obteve: %v", err)
+	}
+}
+
+func TestVerifyTwoFactor_RecoveryCode(t *testing.T) {
+	repo, twoFactor := enrolledUser(t)
+	twoFactor.recoveryCodes = []string{hashRecoveryCode("abcde-fghij")}
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+
+	if _, _, err := service.VerifyTwoFactor(context.Background(), "challenge", "ABCDE FGHIJ"); err != nil {
+		t.Fatalf("código de recuperação deveria valer, obteve: %v", err)
+	}
+	if _, _, err := service.VerifyTwoFactor(context.Background(), "challenge", "abcde-fghij"); err == nil || err.Error() != "código inválido" {
+		t.Errorf("código de recuperação é de uso único, obteve: %v", err)
+	}
+}
+
+func TestVerifyTwoFactor_WrongCodesLockAccount(t *testing.T) {
+	repo, twoFactor := enrolledUser(t)
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+
+	for i := 0; i < 3; i++ {
+		_, _, err := service.VerifyTwoFactor(context.Background(), "challenge", "000000")
+		if err == nil || err.Error() != "código inválido" {
+			t.Fatalf("esperava código inválido, obteve: %v", err)
+		}
+	}
+	if repo.lockedUntil == nil {
+		t.Fatal("códigos errados deveriam bloquear a conta")
+	}
+
+	repo.user.LockedUntil = repo.lockedUntil
+	code, _ := totp.Code(twoFactor.totp.Secret, totp.Step(twoFactorNow))
+	_, _, err := service.VerifyTwoFactor(context.Background(), "challenge", code)
+	var locked *AccountLockedError
+	if !errors.As(err, &locked) {
+		t.Errorf("esperava conta bloqueada, obteve: %v", err)
+	}
+}
+
+func TestVerifyTwoFactor_InvalidChallenge(t *testing.T) {
+	repo, twoFactor := enrolledUser(t)
+	service := newTwoFactorService(repo, twoFactor, clock.NewFake(twoFactorNow))
+
+	_, _, err := service.VerifyTwoFactor(context.Background(), "forjado", "123456")
+	if err == nil || err.Error() != "desafio inválido ou expirado" {
+		t.Errorf("esperava desafio inválido, obteve: %v", err)
+	}
+}
@@ -1,45 +1,149 @@
 package user
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/passwords"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	"desafio-tecnico-fullstack/backend/storage/repository/twofactor"
 	"desafio-tecnico-fullstack/backend/storage/repository/user"
-	"desafio-tecnico-fullstack/backend/utils"
+	"desafio-tecnico-fullstack/backend/tokens"
+	"desafio-tecnico-fullstack/backend/tracing"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var tracer = otel.Tracer("desafio-tecnico-fullstack/backend/services/user")
+
 type UserService interface {
-	RegisterUser(name, cpf, password string) error
-	AuthenticateUser(cpf, password string) (string, *models.User, error)
+	RegisterUser(ctx context.Context, name, cpf, password string) error
+	AuthenticateUser(ctx context.Context, cpf, password string) (string, *models.User, error)
+	UnlockUser(ctx context.Context, userID int) error
+	// AuthorizeToken rejects valid tokens that must no longer be honoured: the
+	// user is gone or deactivated, changed the password after the token was
+	// issued, or no longer has the role the token carries.
+	AuthorizeToken(ctx context.Context, claims tokens.Claims) error
+	// ChangePassword returns a fresh token, since the change revokes the
+	// user's previous ones. twoFactor carries over from the token used for
+	// the request.
+	ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string, twoFactor bool) (string, error)
+	RequestPasswordReset(ctx context.Context, cpf string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	EnrollTOTP(ctx context.Context, userID int) (*TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int, code string) ([]string, error)
+	VerifyTwoFactor(ctx context.Context, challengeToken, code string) (string, *models.User, error)
+	GetProfile(ctx context.Context, userID int) (*Profile, error)
+	UpdateName(ctx context.Context, userID int, name string) (*Profile, error)
+	ListUsers(ctx context.Context, search string, page, pageSize int) (*UserPage, error)
+	SetUserActive(ctx context.Context, adminID, userID int, active bool) error
+	ChangeRole(ctx context.Context, adminID, userID int, role string) error
+	AdminResetPassword(ctx context.Context, adminID, userID int) (*PasswordResetTicket, error)
+	ImportUsers(ctx context.Context, adminID int, file io.Reader, opts ImportOptions) (*ImportReport, error)
+}
+
+type Options struct {
+	Lockout            LockoutPolicy
+	Password           passwords.Policy
+	ResetTokenTTL      time.Duration
+	ActivationTokenTTL time.Duration
+	TwoFactor          TwoFactorOptions
+}
+
+// LockoutPolicy locks an account for Duration once it reaches MaxFailures
+// consecutive wrong passwords, doubling the lock for every further failure up
+// to MaxDuration. A zero MaxFailures disables lockout.
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+func (p LockoutPolicy) lockFor(failures int) time.Duration {
+	d := p.Duration
+	for i := p.MaxFailures; i < failures && d < p.MaxDuration; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxDuration)
+}
+
+// AccountLockedError is returned by AuthenticateUser while the account is
+// locked, whatever the password.
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return "conta bloqueada temporariamente"
 }
 
 type userService struct {
-	repo        user.UserRepository
-	generateJWT func(userID int) (string, error)
+	repo              user.UserRepository
+	resets            passwordreset.PasswordResetRepository
+	twoFactor         twofactor.TwoFactorRepository
+	uow               storage.UnitOfWork
+	notifier          notify.Notifier
+	clock             clock.Clock
+	lockout           LockoutPolicy
+	passwords         passwords.Policy
+	resetTokenTTL     time.Duration
+	activationTTL     time.Duration
+	twoFactorOpts     TwoFactorOptions
+	generateJWT       func(userID int, role string, twoFactor bool) (string, error)
+	generateChallenge func(userID int, ttl time.Duration) (string, error)
+	validateChallenge func(token string) (int, error)
 }
 
-func NewUserService(repo user.UserRepository) UserService {
+func NewUserService(repo user.UserRepository, resets passwordreset.PasswordResetRepository, twoFactor twofactor.TwoFactorRepository, uow storage.UnitOfWork, tokens tokens.Manager, notifier notify.Notifier, clock clock.Clock, opts Options) UserService {
 	return &userService{
-		repo:        repo,
-		generateJWT: utils.GenerateJWT,
+		repo:              repo,
+		resets:            resets,
+		twoFactor:         twoFactor,
+		uow:               uow,
+		notifier:          notifier,
+		clock:             clock,
+		lockout:           opts.Lockout,
+		passwords:         opts.Password,
+		resetTokenTTL:     opts.ResetTokenTTL,
+		activationTTL:     opts.ActivationTokenTTL,
+		twoFactorOpts:     opts.TwoFactor,
+		generateJWT:       tokens.Generate,
+		generateChallenge: tokens.GenerateChallenge,
+		validateChallenge: tokens.ValidateChallenge,
 	}
 }
 
-func (s *userService) RegisterUser(name, cpf, password string) error {
+func (s *userService) RegisterUser(ctx context.Context, name, cpf, password string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RegisterUser")
+	defer func() { tracing.End(span, err) }()
+
 	if !isValidCPF(cpf) {
 		return errors.New("cpf inválido")
 	}
-	if len(password) < 6 {
-		return errors.New("senha muito curta")
+	if err := s.passwords.Check(password); err != nil {
+		return err
 	}
-	if existing := s.repo.GetUserByCPF(cpf); existing != nil {
+	if existing := s.repo.GetUserByCPF(ctx, cpf); existing != nil {
 		return errors.New("usuário já existe")
 	}
 	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	user := models.User{Name: name, CPF: cpf, Password: string(hash)}
-	err := s.repo.AddUser(user)
+	user := models.User{Name: name, CPF: cpf, Weight: models.DefaultWeight, Password: string(hash), Role: models.RoleAssociate}
+	err = s.repo.AddUser(ctx, user)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
 			return errors.New("usuário já existe")
@@ -49,19 +153,268 @@ func (s *userService) RegisterUser(name, cpf, password string) error {
 	return nil
 }
 
-func (s *userService) AuthenticateUser(cpf, password string) (string, *models.User, error) {
-	user := s.repo.GetUserByCPF(cpf)
+func (s *userService) AuthenticateUser(ctx context.Context, cpf, password string) (_ string, _ *models.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.AuthenticateUser")
+	defer func() { tracing.End(span, err) }()
+
+	user := s.repo.GetUserByCPF(ctx, cpf)
 	if user == nil {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginFailureUnknownUser).Inc()
+		logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureUnknownUser)
 		return "", nil, errors.New("usuário ou senha inválidos")
 	}
+	if err := s.checkLocked(ctx, user); err != nil {
+		return "", nil, err
+	}
 	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginFailureWrongPassword).Inc()
+		logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureWrongPassword, "user_id", user.ID)
+		if err := s.recordFailure(ctx, user.ID); err != nil {
+			return "", nil, err
+		}
 		return "", nil, errors.New("usuário ou senha inválidos")
 	}
-	token, err := s.generateJWT(user.ID)
+	if err := checkActive(ctx, user); err != nil {
+		return "", nil, err
+	}
+
+	// The failures are only reset once every factor is verified; otherwise the
+	// password alone would allow unlimited guesses of the second factor.
+	enrollment, err := s.twoFactor.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", nil, err
+	}
+	if enrollment != nil && enrollment.EnabledAt != nil {
+		challenge, err := s.generateChallenge(user.ID, s.twoFactorOpts.ChallengeTTL)
+		if err != nil {
+			return "", nil, err
+		}
+		return "", nil, &TwoFactorRequiredError{ChallengeToken: challenge, ExpiresIn: s.twoFactorOpts.ChallengeTTL}
+	}
+	return s.completeLogin(ctx, user, false)
+}
+
+func (s *userService) checkLocked(ctx context.Context, u *models.User) error {
+	if u.LockedUntil == nil {
+		return nil
+	}
+	if remaining := time.Unix(*u.LockedUntil, 0).Sub(s.clock.Now()); remaining > 0 {
+		metrics.LoginFailures.WithLabelValues(metrics.LoginFailureLocked).Inc()
+		logger.FromContext(ctx).Warn("falha de login", "reason", metrics.LoginFailureLocked, "user_id", u.ID)
+		return &AccountLockedError{RetryAfter: remaining}
+	}
+	return nil
+}
+
+func (s *userService) completeLogin(ctx context.Context, u *models.User, twoFactor bool) (string, *models.User, error) {
+	if u.FailedLoginAttempts > 0 || u.LockedUntil != nil {
+		if err := s.repo.ResetLoginFailures(ctx, u.ID); err != nil {
+			return "", nil, err
+		}
+	}
+	token, err := s.generateJWT(u.ID, u.Role, twoFactor)
 	if err != nil {
 		return "", nil, err
 	}
-	return token, user, nil
+	return token, u, nil
+}
+
+func (s *userService) recordFailure(ctx context.Context, userID int) error {
+	if s.lockout.MaxFailures == 0 {
+		return nil
+	}
+	failures, err := s.repo.RecordLoginFailure(ctx, userID)
+	if err != nil || failures < s.lockout.MaxFailures {
+		return err
+	}
+	lockFor := s.lockout.lockFor(failures)
+	logger.FromContext(ctx).Warn("conta bloqueada", "user_id", userID, "failures", failures, "duration", lockFor)
+	return s.repo.LockUser(ctx, userID, s.clock.Now().Add(lockFor).Unix())
+}
+
+func (s *userService) UnlockUser(ctx context.Context, userID int) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.UnlockUser")
+	defer func() { tracing.End(span, err) }()
+
+	err = s.repo.ResetLoginFailures(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("usuário não encontrado")
+	}
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("conta desbloqueada", "user_id", userID)
+	return nil
+}
+
+// AuthorizeToken compares whole seconds, like the iat claim, so a token issued
+// in the same second as a password change is still accepted.
+func (s *userService) AuthorizeToken(ctx context.Context, claims tokens.Claims) error {
+	u := s.repo.GetUserByID(ctx, claims.UserID)
+	if u == nil {
+		return fmt.Errorf("%w: usuário não encontrado", tokens.ErrInvalidToken)
+	}
+	if u.DeactivatedAt != nil {
+		return fmt.Errorf("%w: conta desativada", tokens.ErrInvalidToken)
+	}
+	if u.PasswordChangedAt != nil && claims.IssuedAt.Unix() < *u.PasswordChangedAt {
+		return fmt.Errorf("%w: emitido antes da troca de senha", tokens.ErrInvalidToken)
+	}
+	if claims.Role != u.Role {
+		return fmt.Errorf("%w: papel alterado", tokens.ErrInvalidToken)
+	}
+	return nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string, twoFactor bool) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ChangePassword")
+	defer func() { tracing.End(span, err) }()
+
+	u := s.repo.GetUserByID(ctx, userID)
+	if u == nil {
+		return "", errors.New("usuário não encontrado")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(currentPassword)) != nil {
+		return "", errors.New("senha atual incorreta")
+	}
+	if newPassword == currentPassword {
+		return "", errors.New("nova senha deve ser diferente da atual")
+	}
+	if err := s.setPassword(ctx, userID, newPassword); err != nil {
+		return "", err
+	}
+	logger.FromContext(ctx).Info("senha alterada", "user_id", userID)
+	return s.generateJWT(u.ID, u.Role, twoFactor)
+}
+
+// RequestPasswordReset succeeds whether or not the CPF is registered, so the
+// endpoint cannot be used to find out which CPFs have accounts. Only the hash
+// of the token is stored; the token itself goes to the notifier.
+func (s *userService) RequestPasswordReset(ctx context.Context, cpf string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RequestPasswordReset")
+	defer func() { tracing.End(span, err) }()
+
+	u := s.repo.GetUserByCPF(ctx, cpf)
+	if u == nil {
+		logger.FromContext(ctx).Info("redefinição de senha para CPF desconhecido")
+		return nil
+	}
+
+	var token string
+	var expiresAt time.Time
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		token, expiresAt, err = s.createResetToken(ctx, u.ID, s.clock.Now(), s.resetTokenTTL)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.notifier.Notify(ctx, notify.Notification{
+		Type:      notify.TypePasswordReset,
+		UserID:    u.ID,
+		Name:      u.Name,
+		CPF:       u.CPF,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		// Answering with an error would reveal that the CPF exists.
+		logger.FromContext(ctx).Error("erro ao enviar token de redefinição de senha", "user_id", u.ID, "error", err)
+		return nil
+	}
+	logger.FromContext(ctx).Info("redefinição de senha solicitada", "user_id", u.ID)
+	return nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, token, newPassword string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ResetPassword")
+	defer func() { tracing.End(span, err) }()
+
+	if err := s.passwords.Check(newPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	var userID int
+	now := s.clock.Now().Unix()
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		userID, err = s.resets.UseResetToken(ctx, hashToken(token), now)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("token de redefinição inválido ou expirado")
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.repo.UpdatePassword(ctx, userID, string(hash), now); err != nil {
+			return err
+		}
+		return s.resets.InvalidateResetTokens(ctx, userID, now)
+	})
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("senha redefinida", "user_id", userID)
+	return nil
+}
+
+// createResetToken replaces the user's pending reset tokens with a new one
+// valid for ttl. Only its hash is stored.
+func (s *userService) createResetToken(ctx context.Context, userID int, now time.Time, ttl time.Duration) (string, time.Time, error) {
+	token, err := newRandomToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := now.Add(ttl)
+	if err := s.resets.InvalidateResetTokens(ctx, userID, now.Unix()); err != nil {
+		return "", time.Time{}, err
+	}
+	err = s.resets.CreateResetToken(ctx, models.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt.Unix(),
+		CreatedAt: now.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// setPassword stores a new password, which revokes the user's tokens and
+// pending reset links.
+func (s *userService) setPassword(ctx context.Context, userID int, password string) error {
+	if err := s.passwords.Check(password); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	now := s.clock.Now().Unix()
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdatePassword(ctx, userID, string(hash), now); err != nil {
+			return err
+		}
+		return s.resets.InvalidateResetTokens(ctx, userID, now)
+	})
+}
+
+func newRandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func isValidCPF(cpf string) bool {
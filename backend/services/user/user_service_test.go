@@ -1,35 +1,201 @@
 package user
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/notify"
+	"desafio-tecnico-fullstack/backend/passwords"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/tokens"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type mockUserRepo struct {
-	user *models.User
+	user        *models.User
+	failures    int
+	lockedUntil *int64
+	resets      int
+	resetErr    error
+	newHash     string
+	changedAt   int64
+	listFilter  user.ListFilter
 }
 
-func (m *mockUserRepo) GetUserByCPF(cpf string) *models.User {
+func (m *mockUserRepo) GetUserByCPF(ctx context.Context, cpf string) *models.User {
 	return m.user
 }
 
-func (m *mockUserRepo) AddUser(u models.User) error {
+func (m *mockUserRepo) AddUser(ctx context.Context, u models.User) error {
+	if m.user == nil {
+		u.ID = 1
+		m.user = &u
+	}
+	return nil
+}
+
+func (m *mockUserRepo) RecordLoginFailure(ctx context.Context, userID int) (int, error) {
+	m.failures++
+	return m.failures, nil
+}
+
+func (m *mockUserRepo) LockUser(ctx context.Context, userID int, until int64) error {
+	m.lockedUntil = &until
+	return nil
+}
+
+func (m *mockUserRepo) ResetLoginFailures(ctx context.Context, userID int) error {
+	m.resets++
+	return m.resetErr
+}
+
+func (m *mockUserRepo) GetUserByID(ctx context.Context, id int) *models.User {
+	if m.user == nil || m.user.ID != id {
+		return nil
+	}
+	return m.user
+}
+
+func (m *mockUserRepo) UpdateName(ctx context.Context, userID int, name string) error {
+	if m.user == nil || m.user.ID != userID {
+		return sql.ErrNoRows
+	}
+	m.user.Name = name
+	return nil
+}
+
+func (m *mockUserRepo) ListUsers(ctx context.Context, filter user.ListFilter) ([]models.User, int, error) {
+	m.listFilter = filter
+	if m.user == nil {
+		return []models.User{}, 0, nil
+	}
+	return []models.User{*m.user}, 1, nil
+}
+
+func (m *mockUserRepo) SetDeactivated(ctx context.Context, userID int, deactivatedAt *int64) error {
+	if m.user == nil || m.user.ID != userID {
+		return sql.ErrNoRows
+	}
+	m.user.DeactivatedAt = deactivatedAt
+	return nil
+}
+
+func (m *mockUserRepo) UpdateMembership(ctx context.Context, userID int, name, email string, weight int) error {
+	if m.user == nil || m.user.ID != userID {
+		return sql.ErrNoRows
+	}
+	m.user.Name, m.user.Email, m.user.Weight = name, email, weight
+	return nil
+}
+
+func (m *mockUserRepo) UpdateRole(ctx context.Context, userID int, role string) error {
+	if m.user == nil || m.user.ID != userID {
+		return sql.ErrNoRows
+	}
+	m.user.Role = role
+	return nil
+}
+
+func (m *mockUserRepo) UpdatePassword(ctx context.Context, userID int, hash string, changedAt int64) error {
+	m.newHash, m.changedAt = hash, changedAt
+	return nil
+}
+
+type mockResetRepo struct {
+	tokens      []models.PasswordResetToken
+	used        map[string]bool
+	invalidated []int
+	createErr   error
+}
+
+func (m *mockResetRepo) CreateResetToken(ctx context.Context, t models.PasswordResetToken) error {
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.tokens = append(m.tokens, t)
+	return nil
+}
+
+func (m *mockResetRepo) UseResetToken(ctx context.Context, tokenHash string, now int64) (int, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash && !m.used[tokenHash] && t.ExpiresAt > now {
+			if m.used == nil {
+				m.used = map[string]bool{}
+			}
+			m.used[tokenHash] = true
+			return t.UserID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (m *mockResetRepo) InvalidateResetTokens(ctx context.Context, userID int, now int64) error {
+	m.invalidated = append(m.invalidated, userID)
 	return nil
 }
 
+type mockUnitOfWork struct {
+	calls int
+}
+
+func (m *mockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+type mockNotifier struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	m.sent = append(m.sent, n)
+	return m.err
+}
+
+func newPasswordService(repo *mockUserRepo, resets *mockResetRepo, notifier *mockNotifier, clk clock.Clock) *userService {
+	return &userService{
+		repo:          repo,
+		resets:        resets,
+		twoFactor:     &mockTwoFactorRepo{},
+		uow:           &mockUnitOfWork{},
+		notifier:      notifier,
+		clock:         clk,
+		passwords:     passwords.Policy{MinLength: 8, RequireLetter: true, RequireDigit: true},
+		resetTokenTTL: 30 * time.Minute,
+		generateJWT:   func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
+	}
+}
+
+func newLockoutService(repo *mockUserRepo, clk clock.Clock) *userService {
+	return &userService{
+		repo:        repo,
+		twoFactor:   &mockTwoFactorRepo{},
+		clock:       clk,
+		lockout:     LockoutPolicy{MaxFailures: 3, Duration: time.Minute, MaxDuration: 3 * time.Minute},
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
+	}
+}
+
 func TestAuthenticateUser_Success(t *testing.T) {
 	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.DefaultCost)
 	user := &models.User{ID: 1, CPF: "12345678901", Password: string(hash)}
 	repo := &mockUserRepo{user: user}
 	service := &userService{
 		repo:        repo,
-		generateJWT: func(userID int) (string, error) { return "token123", nil },
+		twoFactor:   &mockTwoFactorRepo{},
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
 	}
 
-	token, _, err := service.AuthenticateUser("12345678901", "senha123")
+	token, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123")
 	if err != nil {
 		t.Fatalf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -42,10 +208,11 @@ func TestAuthenticateUser_UserNotFound(t *testing.T) {
 	repo := &mockUserRepo{user: nil}
 	service := &userService{
 		repo:        repo,
-		generateJWT: func(userID int) (string, error) { return "token123", nil },
+		twoFactor:   &mockTwoFactorRepo{},
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
 	}
 
-	_, _, err := service.AuthenticateUser("00000000000", "senha123")
+	_, _, err := service.AuthenticateUser(context.Background(), "00000000000", "senha123")
 	if err == nil || err.Error() != "usuário ou senha inválidos" {
 		t.Errorf("esperava erro de usuário ou senha inválidos, obteve: %v", err)
 	}
@@ -57,11 +224,270 @@ func TestAuthenticateUser_WrongPassword(t *testing.T) {
 	repo := &mockUserRepo{user: user}
 	service := &userService{
 		repo:        repo,
-		generateJWT: func(userID int) (string, error) { return "token123", nil },
+		twoFactor:   &mockTwoFactorRepo{},
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
 	}
 
-	_, _, err := service.AuthenticateUser("12345678901", "errada")
+	_, _, err := service.AuthenticateUser(context.Background(), "12345678901", "errada")
 	if err == nil || err.Error() != "usuário ou senha inválidos" {
 		t.Errorf("esperava erro de usuário ou senha inválidos, obteve: %v", err)
 	}
 }
+
+func TestAuthenticateUser_CountsLoginFailures(t *testing.T) {
+	service := &userService{
+		repo:        &mockUserRepo{user: nil},
+		twoFactor:   &mockTwoFactorRepo{},
+		generateJWT: func(userID int, role string, twoFactor bool) (string, error) { return "token123", nil },
+	}
+	counter := metrics.LoginFailures.WithLabelValues(metrics.LoginFailureUnknownUser)
+	before := testutil.ToFloat64(counter)
+
+	service.AuthenticateUser(context.Background(), "00000000000", "senha123")
+
+	if got := testutil.ToFloat64(counter); got != before+1 {
+		t.Errorf("esperava %v falhas de login, obteve %v", before+1, got)
+	}
+}
+
+func TestAuthenticateUser_LocksAfterMaxFailures(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901", Password: string(hash)}}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	service := newLockoutService(repo, clk)
+
+	for i := 0; i < 2; i++ {
+		service.AuthenticateUser(context.Background(), "12345678901", "errada")
+	}
+	if repo.lockedUntil != nil {
+		t.Fatal("não deveria bloquear antes do limite de falhas")
+	}
+
+	service.AuthenticateUser(context.Background(), "12345678901", "errada")
+	if repo.lockedUntil == nil || *repo.lockedUntil != 1060 {
+		t.Fatalf("esperava bloqueio até 1060, obteve %v", repo.lockedUntil)
+	}
+
+	repo.user.LockedUntil = repo.lockedUntil
+	_, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123")
+	var locked *AccountLockedError
+	if !errors.As(err, &locked) || locked.RetryAfter != time.Minute {
+		t.Errorf("esperava conta bloqueada por 1m mesmo com a senha correta, obteve: %v", err)
+	}
+	if repo.failures != 3 {
+		t.Errorf("tentativas durante o bloqueio não deveriam contar, obteve %d falhas", repo.failures)
+	}
+}
+
+func TestAuthenticateUser_ProgressiveLockout(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901", Password: string(hash)}, failures: 3}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	service := newLockoutService(repo, clk)
+
+	for _, want := range []int64{1120, 1180, 1180} {
+		service.AuthenticateUser(context.Background(), "12345678901", "errada")
+		if repo.lockedUntil == nil || *repo.lockedUntil != want {
+			t.Errorf("esperava bloqueio até %d, obteve %v", want, repo.lockedUntil)
+		}
+	}
+}
+
+func TestAuthenticateUser_SuccessAfterLockExpiresResetsFailures(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	lockedUntil := int64(1060)
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901", Password: string(hash), FailedLoginAttempts: 3, LockedUntil: &lockedUntil}}
+	clk := clock.NewFake(time.Unix(1061, 0))
+	service := newLockoutService(repo, clk)
+
+	if _, _, err := service.AuthenticateUser(context.Background(), "12345678901", "senha123"); err != nil {
+		t.Fatalf("esperava sucesso após o fim do bloqueio, obteve: %v", err)
+	}
+	if repo.resets != 1 {
+		t.Errorf("esperava falhas zeradas após login, obteve %d resets", repo.resets)
+	}
+}
+
+func TestAuthenticateUser_LockoutDisabled(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901", Password: string(hash)}}
+	service := newLockoutService(repo, clock.NewFake(time.Unix(1000, 0)))
+	service.lockout = LockoutPolicy{}
+
+	for i := 0; i < 5; i++ {
+		service.AuthenticateUser(context.Background(), "12345678901", "errada")
+	}
+	if repo.failures != 0 || repo.lockedUntil != nil {
+		t.Errorf("não deveria registrar falhas com bloqueio desativado: %+v", repo)
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	repo := &mockUserRepo{}
+	service := newLockoutService(repo, clock.New())
+
+	if err := service.UnlockUser(context.Background(), 1); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if repo.resets != 1 {
+		t.Errorf("esperava 1 reset, obteve %d", repo.resets)
+	}
+
+	repo.resetErr = sql.ErrNoRows
+	err := service.UnlockUser(context.Background(), 99)
+	if err == nil || err.Error() != "usuário não encontrado" {
+		t.Errorf("esperava erro de usuário não encontrado, obteve: %v", err)
+	}
+}
+
+func TestRegisterUser_EnforcesPasswordPolicy(t *testing.T) {
+	service := newPasswordService(&mockUserRepo{}, &mockResetRepo{}, &mockNotifier{}, clock.New())
+
+	err := service.RegisterUser(context.Background(), "Maria", "12345678901", "curta")
+	var weak *passwords.PolicyError
+	if !errors.As(err, &weak) {
+		t.Errorf("esperava erro de política de senha, obteve: %v", err)
+	}
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	repo := &mockUserRepo{user: &models.User{ID: 1, Password: string(hash), Role: models.RoleAssociate}}
+	resets := &mockResetRepo{}
+	service := newPasswordService(repo, resets, &mockNotifier{}, clock.NewFake(time.Unix(1000, 0)))
+	var verified bool
+	service.generateJWT = func(userID int, role string, twoFactor bool) (string, error) {
+		verified = twoFactor
+		return "token123", nil
+	}
+
+	token, err := service.ChangePassword(context.Background(), 1, "senha123", "novaSenha1", true)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if !verified {
+		t.Error("o novo token deveria manter a verificação em duas etapas")
+	}
+	if token != "token123" {
+		t.Errorf("esperava novo token, obteve %q", token)
+	}
+	if repo.changedAt != 1000 || bcrypt.CompareHashAndPassword([]byte(repo.newHash), []byte("novaSenha1")) != nil {
+		t.Errorf("senha não atualizada: %+v", repo)
+	}
+	if len(resets.invalidated) != 1 {
+		t.Errorf("esperava tokens de redefinição invalidados, obteve %v", resets.invalidated)
+	}
+}
+
+func TestChangePassword_Errors(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	testCases := []struct {
+		name    string
+		userID  int
+		current string
+		new     string
+		wantErr string
+	}{
+		{"unknown user", 2, "senha123", "novaSenha1", "usuário não encontrado"},
+		{"wrong current password", 1, "errada", "novaSenha1", "senha atual incorreta"},
+		{"same password", 1, "senha123", "senha123", "nova senha deve ser diferente da atual"},
+		{"weak password", 1, "senha123", "semnumeros", "senha fraca: deve conter um número"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mockUserRepo{user: &models.User{ID: 1, Password: string(hash)}}
+			service := newPasswordService(repo, &mockResetRepo{}, &mockNotifier{}, clock.New())
+
+			_, err := service.ChangePassword(context.Background(), tc.userID, tc.current, tc.new, false)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("esperava erro %q, obteve: %v", tc.wantErr, err)
+			}
+			if repo.newHash != "" {
+				t.Error("senha não deveria ser atualizada")
+			}
+		})
+	}
+}
+
+func TestAuthorizeToken_RejectsTokensIssuedBeforePasswordChange(t *testing.T) {
+	changedAt := int64(1000)
+	repo := &mockUserRepo{user: &models.User{ID: 1, PasswordChangedAt: &changedAt}}
+	service := newPasswordService(repo, &mockResetRepo{}, &mockNotifier{}, clock.New())
+
+	old := tokens.Claims{UserID: 1, IssuedAt: time.Unix(999, 0)}
+	if err := service.AuthorizeToken(context.Background(), old); !errors.Is(err, tokens.ErrInvalidToken) {
+		t.Errorf("token anterior à troca deveria ser revogado, obteve: %v", err)
+	}
+	fresh := tokens.Claims{UserID: 1, IssuedAt: time.Unix(1000, 0)}
+	if err := service.AuthorizeToken(context.Background(), fresh); err != nil {
+		t.Errorf("token emitido na troca deveria ser aceito: %v", err)
+	}
+	missing := tokens.Claims{UserID: 2, IssuedAt: time.Unix(1000, 0)}
+	if err := service.AuthorizeToken(context.Background(), missing); !errors.Is(err, tokens.ErrInvalidToken) {
+		t.Errorf("token de usuário inexistente deveria ser recusado, obteve: %v", err)
+	}
+}
+
+func TestPasswordReset_Flow(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 1, Name: "Maria", CPF: "12345678901"}}
+	resets := &mockResetRepo{}
+	notifier := &mockNotifier{}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	service := newPasswordService(repo, resets, notifier, clk)
+
+	if err := service.RequestPasswordReset(context.Background(), "12345678901"); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if len(notifier.sent) != 1 || len(resets.tokens) != 1 {
+		t.Fatalf("esperava um token criado e notificado: %+v %+v", notifier.sent, resets.tokens)
+	}
+	sent, stored := notifier.sent[0], resets.tokens[0]
+	if sent.Type != notify.TypePasswordReset || sent.UserID != 1 || !sent.ExpiresAt.Equal(time.Unix(2800, 0)) {
+		t.Errorf("notificação inesperada: %+v", sent)
+	}
+	if stored.TokenHash == sent.Token || stored.TokenHash != hashToken(sent.Token) || stored.ExpiresAt != 2800 {
+		t.Errorf("deveria guardar apenas o hash do token: %+v", stored)
+	}
+
+	if err := service.ResetPassword(context.Background(), sent.Token, "novaSenha1"); err != nil {
+		t.Fatalf("esperava redefinição, obteve erro: %v", err)
+	}
+	if repo.changedAt != 1000 || bcrypt.CompareHashAndPassword([]byte(repo.newHash), []byte("novaSenha1")) != nil {
+		t.Errorf("senha não redefinida: %+v", repo)
+	}
+
+	err := service.ResetPassword(context.Background(), sent.Token, "outraSenha2")
+	if err == nil || err.Error() != "token de redefinição inválido ou expirado" {
+		t.Errorf("token não deveria ser reutilizável, obteve: %v", err)
+	}
+}
+
+func TestRequestPasswordReset_UnknownCPFDoesNotNotify(t *testing.T) {
+	notifier := &mockNotifier{}
+	service := newPasswordService(&mockUserRepo{}, &mockResetRepo{}, notifier, clock.New())
+
+	if err := service.RequestPasswordReset(context.Background(), "00000000000"); err != nil {
+		t.Fatalf("não deveria revelar CPFs inexistentes, obteve: %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("não deveria notificar: %+v", notifier.sent)
+	}
+}
+
+func TestResetPassword_ExpiredToken(t *testing.T) {
+	repo := &mockUserRepo{user: &models.User{ID: 1, CPF: "12345678901"}}
+	notifier := &mockNotifier{}
+	clk := clock.NewFake(time.Unix(1000, 0))
+	service := newPasswordService(repo, &mockResetRepo{}, notifier, clk)
+	service.RequestPasswordReset(context.Background(), "12345678901")
+
+	clk.Advance(30 * time.Minute)
+	err := service.ResetPassword(context.Background(), notifier.sent[0].Token, "novaSenha1")
+	if err == nil || err.Error() != "token de redefinição inválido ou expirado" {
+		t.Errorf("esperava token expirado, obteve: %v", err)
+	}
+	if repo.newHash != "" {
+		t.Error("senha não deveria ser alterada")
+	}
+}
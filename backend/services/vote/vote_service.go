@@ -1,50 +1,99 @@
 package vote
 
 import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/logger"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
 	sessionRepoPkg "desafio-tecnico-fullstack/backend/storage/repository/session"
 	voteRepoPkg "desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"desafio-tecnico-fullstack/backend/tracing"
 	"errors"
-	"time"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("desafio-tecnico-fullstack/backend/services/vote")
+
 type VoteService interface {
-	Vote(topicID int, userID int, choice string) error
-	GetResult(topicID int) (yes int, no int, err error)
+	Vote(ctx context.Context, topicID int, userID int, choice string) error
+	GetResult(ctx context.Context, topicID int) (yes int, no int, err error)
+	GetRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error)
+	GetVotingSummary(ctx context.Context, userID int) (models.VotingSummary, error)
 }
 
+// recentVotes is how many of the latest votes GetVotingSummary lists.
+const recentVotes = 5
+
 type voteService struct {
 	voteRepo    voteRepoPkg.VoteRepository
 	sessionRepo sessionRepoPkg.SessionRepository
+	uow         storage.UnitOfWork
+	clock       clock.Clock
 }
 
-func NewVoteService(voteRepo voteRepoPkg.VoteRepository, sessionRepo sessionRepoPkg.SessionRepository) VoteService {
-	return &voteService{voteRepo: voteRepo, sessionRepo: sessionRepo}
+func NewVoteService(voteRepo voteRepoPkg.VoteRepository, sessionRepo sessionRepoPkg.SessionRepository, uow storage.UnitOfWork, clock clock.Clock) VoteService {
+	return &voteService{voteRepo: voteRepo, sessionRepo: sessionRepo, uow: uow, clock: clock}
 }
 
-func (s *voteService) Vote(topicID int, userID int, choice string) error {
+func (s *voteService) Vote(ctx context.Context, topicID int, userID int, choice string) (err error) {
+	ctx, span := tracer.Start(ctx, "VoteService.Vote")
+	defer func() { tracing.End(span, err) }()
+
 	if choice != "Sim" && choice != "Não" {
 		return errors.New("voto deve ser 'Sim' ou 'Não'")
 	}
-	session, err := s.sessionRepo.GetSessionByTopic(topicID)
+	session, err := s.sessionRepo.GetSessionByTopic(ctx, topicID)
 	if err != nil {
 		return errors.New("sessão não encontrada para a pauta")
 	}
-	now := time.Now().Unix()
-	if now < session.OpenAt || now > session.CloseAt {
+	if session.Status == models.SessionStatusPaused {
+		return errors.New("sessão de votação está pausada")
+	}
+	now := s.clock.Now().Unix()
+	if session.Status == models.SessionStatusClosed || now < session.OpenAt || now > session.CloseAt {
 		return errors.New("sessão de votação não está aberta")
 	}
-	voted, err := s.voteRepo.HasUserVoted(topicID, userID)
+	vote := models.Vote{TopicID: topicID, SessionID: session.ID, UserID: userID, Choice: choice}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		voted, err := s.voteRepo.HasUserVoted(ctx, session.ID, userID)
+		if err != nil {
+			return err
+		}
+		if voted {
+			return errors.New("voto já registrado")
+		}
+		return s.voteRepo.RegisterVote(ctx, vote)
+	})
 	if err != nil {
 		return err
 	}
-	if voted {
-		return errors.New("voto já registrado")
-	}
-	vote := models.Vote{TopicID: topicID, UserID: userID, Choice: choice}
-	return s.voteRepo.RegisterVote(vote)
+
+	metrics.VotesCast.WithLabelValues(strconv.Itoa(topicID), choice).Inc()
+	logger.FromContext(ctx).Debug("voto registrado", "topic_id", topicID, "session_id", session.ID, "user_id", userID)
+	return nil
 }
 
-func (s *voteService) GetResult(topicID int) (yes int, no int, err error) {
-	return s.voteRepo.GetResult(topicID)
+func (s *voteService) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
+	ctx, span := tracer.Start(ctx, "VoteService.GetResult")
+	defer func() { tracing.End(span, err) }()
+
+	return s.voteRepo.GetResult(ctx, topicID)
+}
+
+func (s *voteService) GetRoundResults(ctx context.Context, topicID int) (_ []models.RoundResult, err error) {
+	ctx, span := tracer.Start(ctx, "VoteService.GetRoundResults")
+	defer func() { tracing.End(span, err) }()
+
+	return s.voteRepo.ListRoundResults(ctx, topicID)
+}
+
+func (s *voteService) GetVotingSummary(ctx context.Context, userID int) (_ models.VotingSummary, err error) {
+	ctx, span := tracer.Start(ctx, "VoteService.GetVotingSummary")
+	defer func() { tracing.End(span, err) }()
+
+	return s.voteRepo.GetVotingSummary(ctx, userID, recentVotes)
 }
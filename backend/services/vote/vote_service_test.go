@@ -1,23 +1,37 @@
 package vote
 
 import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/metrics"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/tracing"
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+var testNow = time.Unix(1750000000, 0)
+
 type mockVoteRepo struct {
 	votes       []models.Vote
+	votedIn     []int
+	rounds      []models.RoundResult
 	hasVoted    bool
 	hasVotedErr error
 	registerErr error
 	resultYes   int
 	resultNo    int
 	resultErr   error
+	summary     models.VotingSummary
 }
 
-func (m *mockVoteRepo) RegisterVote(vote models.Vote) error {
+func (m *mockVoteRepo) RegisterVote(ctx context.Context, vote models.Vote) error {
 	if m.registerErr != nil {
 		return m.registerErr
 	}
@@ -25,39 +39,74 @@ func (m *mockVoteRepo) RegisterVote(vote models.Vote) error {
 	return nil
 }
 
-func (m *mockVoteRepo) HasUserVoted(topicID int, userID int) (bool, error) {
+func (m *mockVoteRepo) HasUserVoted(ctx context.Context, sessionID int, userID int) (bool, error) {
+	m.votedIn = append(m.votedIn, sessionID)
 	if m.hasVotedErr != nil {
 		return false, m.hasVotedErr
 	}
 	return m.hasVoted, nil
 }
 
-func (m *mockVoteRepo) GetResult(topicID int) (yes int, no int, err error) {
+func (m *mockVoteRepo) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
 	return m.resultYes, m.resultNo, m.resultErr
 }
 
+func (m *mockVoteRepo) ListRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error) {
+	return m.rounds, m.resultErr
+}
+
+func (m *mockVoteRepo) GetVotingSummary(ctx context.Context, userID int, recent int) (models.VotingSummary, error) {
+	return m.summary, m.resultErr
+}
+
 type mockSessionRepo struct {
 	session    *models.Session
 	sessionErr error
 }
 
-func (m *mockSessionRepo) OpenSession(topicID int, openAt, closeAt int64) error {
+func (m *mockSessionRepo) OpenSession(ctx context.Context, topicID int, openAt, closeAt int64) error {
 	return nil
 }
 
-func (m *mockSessionRepo) GetSessionByTopic(topicID int) (*models.Session, error) {
+func (m *mockSessionRepo) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
 	if m.sessionErr != nil {
 		return nil, m.sessionErr
 	}
 	return m.session, nil
 }
 
-func (m *mockSessionRepo) UpdateExpiredSessions() error {
+func (m *mockSessionRepo) UpdateExpiredSessions(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockSessionRepo) UpdateSession(ctx context.Context, session models.Session, topicStatus string) error {
+	return nil
+}
+
+func (m *mockSessionRepo) AddSessionEvent(ctx context.Context, event models.SessionEvent) error {
+	return nil
+}
+
+func (m *mockSessionRepo) ListSessionEvents(ctx context.Context, sessionID int) ([]models.SessionEvent, error) {
+	return nil, nil
+}
+
+type mockUnitOfWork struct {
+	calls      int
+	rolledBack int
+}
+
+func (m *mockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	if err := fn(ctx); err != nil {
+		m.rolledBack++
+		return err
+	}
 	return nil
 }
 
 func TestVoteService_Vote_Success(t *testing.T) {
-	now := time.Now().Unix()
+	now := testNow.Unix()
 	voteRepo := &mockVoteRepo{hasVoted: false}
 	sessionRepo := &mockSessionRepo{
 		session: &models.Session{
@@ -68,9 +117,9 @@ func TestVoteService_Vote_Success(t *testing.T) {
 		},
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -85,13 +134,75 @@ func TestVoteService_Vote_Success(t *testing.T) {
 	}
 }
 
+func TestVoteService_Vote_TiedToCurrentRound(t *testing.T) {
+	now := testNow.Unix()
+	voteRepo := &mockVoteRepo{}
+	sessionRepo := &mockSessionRepo{
+		session: &models.Session{
+			ID:      8,
+			TopicID: 1,
+			Round:   2,
+			OpenAt:  now - 100,
+			CloseAt: now + 100,
+			Status:  models.SessionStatusOpen,
+		},
+	}
+
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	if err := service.Vote(context.Background(), 1, 123, "Não"); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if len(voteRepo.votedIn) != 1 || voteRepo.votedIn[0] != 8 {
+		t.Errorf("verificação de voto duplicado deveria usar a sessão 8, obteve %v", voteRepo.votedIn)
+	}
+	if voteRepo.votes[0].SessionID != 8 {
+		t.Errorf("esperava voto vinculado à sessão 8, obteve %d", voteRepo.votes[0].SessionID)
+	}
+}
+
+func TestVoteService_Vote_CountsVotesCast(t *testing.T) {
+	now := testNow.Unix()
+	sessionRepo := &mockSessionRepo{
+		session: &models.Session{ID: 1, TopicID: 77, OpenAt: now - 100, CloseAt: now + 100},
+	}
+	service := NewVoteService(&mockVoteRepo{}, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
+	counter := metrics.VotesCast.WithLabelValues("77", "Não")
+	before := testutil.ToFloat64(counter)
+
+	if err := service.Vote(context.Background(), 77, 123, "Não"); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if got := testutil.ToFloat64(counter); got != before+1 {
+		t.Errorf("esperava contador de votos %v, obteve %v", before+1, got)
+	}
+}
+
+func TestVoteService_Vote_RecordsSpan(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	service := NewVoteService(&mockVoteRepo{}, &mockSessionRepo{}, &mockUnitOfWork{}, clock.NewFake(testNow))
+	service.Vote(context.Background(), 1, 123, "Talvez")
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "VoteService.Vote" {
+		t.Fatalf("esperava span 'VoteService.Vote', obteve %+v", spans)
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("esperava span com status de erro, obteve %+v", spans[0].Status)
+	}
+}
+
 func TestVoteService_Vote_InvalidChoice(t *testing.T) {
 	voteRepo := &mockVoteRepo{}
 	sessionRepo := &mockSessionRepo{}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Talvez")
+	err := service.Vote(context.Background(), 1, 123, "Talvez")
 	if err == nil || err.Error() != "voto deve ser 'Sim' ou 'Não'" {
 		t.Errorf("esperava erro de escolha inválida, obteve: %v", err)
 	}
@@ -103,16 +214,16 @@ func TestVoteService_Vote_SessionNotFound(t *testing.T) {
 		sessionErr: errors.New("session not found"),
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err == nil || err.Error() != "sessão não encontrada para a pauta" {
 		t.Errorf("esperava erro de sessão não encontrada, obteve: %v", err)
 	}
 }
 
 func TestVoteService_Vote_SessionClosed(t *testing.T) {
-	now := time.Now().Unix()
+	now := testNow.Unix()
 	voteRepo := &mockVoteRepo{}
 	sessionRepo := &mockSessionRepo{
 		session: &models.Session{
@@ -123,16 +234,16 @@ func TestVoteService_Vote_SessionClosed(t *testing.T) {
 		},
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err == nil || err.Error() != "sessão de votação não está aberta" {
 		t.Errorf("esperava erro de sessão fechada, obteve: %v", err)
 	}
 }
 
 func TestVoteService_Vote_SessionNotOpen(t *testing.T) {
-	now := time.Now().Unix()
+	now := testNow.Unix()
 	voteRepo := &mockVoteRepo{}
 	sessionRepo := &mockSessionRepo{
 		session: &models.Session{
@@ -143,16 +254,94 @@ func TestVoteService_Vote_SessionNotOpen(t *testing.T) {
 		},
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err == nil || err.Error() != "sessão de votação não está aberta" {
 		t.Errorf("esperava erro de sessão não aberta, obteve: %v", err)
 	}
 }
 
+func TestVoteService_Vote_SessionPaused(t *testing.T) {
+	now := testNow.Unix()
+	pausedAt := now - 10
+	voteRepo := &mockVoteRepo{}
+	sessionRepo := &mockSessionRepo{
+		session: &models.Session{
+			ID:       1,
+			TopicID:  1,
+			OpenAt:   now - 100,
+			CloseAt:  now + 100,
+			Status:   models.SessionStatusPaused,
+			PausedAt: &pausedAt,
+		},
+	}
+
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	err := service.Vote(context.Background(), 1, 123, "Sim")
+	if err == nil || err.Error() != "sessão de votação está pausada" {
+		t.Errorf("esperava erro de sessão pausada, obteve: %v", err)
+	}
+}
+
+func TestVoteService_Vote_SessionClosedEarly(t *testing.T) {
+	now := testNow.Unix()
+	voteRepo := &mockVoteRepo{}
+	sessionRepo := &mockSessionRepo{
+		session: &models.Session{
+			ID:      1,
+			TopicID: 1,
+			OpenAt:  now - 100,
+			CloseAt: now + 100,
+			Status:  models.SessionStatusClosed,
+		},
+	}
+
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	err := service.Vote(context.Background(), 1, 123, "Sim")
+	if err == nil || err.Error() != "sessão de votação não está aberta" {
+		t.Errorf("esperava erro de sessão encerrada, obteve: %v", err)
+	}
+}
+
+func TestVoteService_Vote_WindowBoundaries(t *testing.T) {
+	openAt := testNow.Unix()
+	closeAt := openAt + 60
+
+	tests := []struct {
+		name    string
+		at      int64
+		allowed bool
+	}{
+		{"one second before open_at", openAt - 1, false},
+		{"exactly at open_at", openAt, true},
+		{"exactly at close_at", closeAt, true},
+		{"one second after close_at", closeAt + 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voteRepo := &mockVoteRepo{}
+			sessionRepo := &mockSessionRepo{
+				session: &models.Session{ID: 1, TopicID: 1, OpenAt: openAt, CloseAt: closeAt, Status: models.SessionStatusOpen},
+			}
+			service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(time.Unix(tt.at, 0)))
+
+			err := service.Vote(context.Background(), 1, 123, "Sim")
+			if tt.allowed && err != nil {
+				t.Errorf("esperava voto aceito, obteve erro: %v", err)
+			}
+			if !tt.allowed && (err == nil || err.Error() != "sessão de votação não está aberta") {
+				t.Errorf("esperava erro de sessão não aberta, obteve: %v", err)
+			}
+		})
+	}
+}
+
 func TestVoteService_Vote_AlreadyVoted(t *testing.T) {
-	now := time.Now().Unix()
+	now := testNow.Unix()
 	voteRepo := &mockVoteRepo{hasVoted: true}
 	sessionRepo := &mockSessionRepo{
 		session: &models.Session{
@@ -163,16 +352,16 @@ func TestVoteService_Vote_AlreadyVoted(t *testing.T) {
 		},
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err == nil || err.Error() != "voto já registrado" {
 		t.Errorf("esperava erro de voto já registrado, obteve: %v", err)
 	}
 }
 
 func TestVoteService_Vote_HasVotedError(t *testing.T) {
-	now := time.Now().Unix()
+	now := testNow.Unix()
 	voteRepo := &mockVoteRepo{
 		hasVotedErr: errors.New("database error"),
 	}
@@ -185,14 +374,33 @@ func TestVoteService_Vote_HasVotedError(t *testing.T) {
 		},
 	}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	err := service.Vote(1, 123, "Sim")
+	err := service.Vote(context.Background(), 1, 123, "Sim")
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
 }
 
+func TestVoteService_Vote_RegisterErrorRollsBack(t *testing.T) {
+	now := testNow.Unix()
+	voteRepo := &mockVoteRepo{registerErr: errors.New("duplicate key")}
+	sessionRepo := &mockSessionRepo{
+		session: &models.Session{ID: 1, TopicID: 1, OpenAt: now - 100, CloseAt: now + 100},
+	}
+	uow := &mockUnitOfWork{}
+
+	service := NewVoteService(voteRepo, sessionRepo, uow, clock.NewFake(testNow))
+
+	err := service.Vote(context.Background(), 1, 123, "Sim")
+	if err == nil || err.Error() != "duplicate key" {
+		t.Errorf("esperava erro do banco, obteve: %v", err)
+	}
+	if uow.calls != 1 || uow.rolledBack != 1 {
+		t.Errorf("esperava verificação e registro na mesma transação desfeita, obteve %d chamadas e %d rollbacks", uow.calls, uow.rolledBack)
+	}
+}
+
 func TestVoteService_GetResult_Success(t *testing.T) {
 	voteRepo := &mockVoteRepo{
 		resultYes: 10,
@@ -200,9 +408,9 @@ func TestVoteService_GetResult_Success(t *testing.T) {
 	}
 	sessionRepo := &mockSessionRepo{}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	yes, no, err := service.GetResult(1)
+	yes, no, err := service.GetResult(context.Background(), 1)
 	if err != nil {
 		t.Errorf("esperava sucesso, obteve erro: %v", err)
 	}
@@ -212,15 +420,33 @@ func TestVoteService_GetResult_Success(t *testing.T) {
 	}
 }
 
+func TestVoteService_GetRoundResults_Success(t *testing.T) {
+	voteRepo := &mockVoteRepo{
+		rounds: []models.RoundResult{
+			{SessionID: 1, Round: 1, Yes: 3, No: 3},
+			{SessionID: 2, Round: 2, Yes: 5, No: 1},
+		},
+	}
+	service := NewVoteService(voteRepo, &mockSessionRepo{}, &mockUnitOfWork{}, clock.NewFake(testNow))
+
+	rounds, err := service.GetRoundResults(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if len(rounds) != 2 || rounds[1].Round != 2 || rounds[1].Yes != 5 {
+		t.Errorf("resultados por rodada incorretos: %+v", rounds)
+	}
+}
+
 func TestVoteService_GetResult_Error(t *testing.T) {
 	voteRepo := &mockVoteRepo{
 		resultErr: errors.New("database error"),
 	}
 	sessionRepo := &mockSessionRepo{}
 
-	service := NewVoteService(voteRepo, sessionRepo)
+	service := NewVoteService(voteRepo, sessionRepo, &mockUnitOfWork{}, clock.NewFake(testNow))
 
-	_, _, err := service.GetResult(1)
+	_, _, err := service.GetResult(context.Background(), 1)
 	if err == nil || err.Error() != "database error" {
 		t.Errorf("esperava erro de banco de dados, obteve: %v", err)
 	}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/metrics"
+	healthService "desafio-tecnico-fullstack/backend/services/health"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+	"desafio-tecnico-fullstack/backend/storage/memory"
+	oidcStateRepo "desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+	passwordResetRepo "desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	sessionRepo "desafio-tecnico-fullstack/backend/storage/repository/session"
+	topicRepo "desafio-tecnico-fullstack/backend/storage/repository/topic"
+	twoFactorRepo "desafio-tecnico-fullstack/backend/storage/repository/twofactor"
+	userRepo "desafio-tecnico-fullstack/backend/storage/repository/user"
+	voteRepo "desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"desafio-tecnico-fullstack/backend/storage/sqlite"
+	"fmt"
+	"log/slog"
+)
+
+type repositories struct {
+	users          userRepo.UserRepository
+	passwordResets passwordResetRepo.PasswordResetRepository
+	twoFactor      twoFactorRepo.TwoFactorRepository
+	oidcStates     oidcStateRepo.OIDCStateRepository
+	topics         topicRepo.TopicRepository
+	sessions       sessionRepo.SessionRepository
+	votes          voteRepo.VoteRepository
+	unitOfWork     storage.UnitOfWork
+	checks         map[string]healthService.Check
+	close          func() error
+}
+
+func openStorage(ctx context.Context, driver string, clk clock.Clock) (*repositories, error) {
+	if driver == config.StorageDriverMemory {
+		slog.Warn("usando armazenamento em memória: os dados serão perdidos ao reiniciar")
+		store := memory.NewStore()
+		return &repositories{
+			users:          memory.NewUserRepository(store),
+			passwordResets: memory.NewPasswordResetRepository(store),
+			twoFactor:      memory.NewTwoFactorRepository(store),
+			oidcStates:     memory.NewOIDCStateRepository(store),
+			topics:         memory.NewTopicRepository(store),
+			sessions:       memory.NewSessionRepository(store, clk),
+			votes:          memory.NewVoteRepository(store),
+			unitOfWork:     memory.NewUnitOfWork(store),
+			checks:         map[string]healthService.Check{},
+			close:          func() error { return nil },
+		}, nil
+	}
+
+	db, err := openDB(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := connection.NewMigrator(db, driver)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if config.AppConfig.Database.AutoMigrate {
+		if err := migrateUp(ctx, migrator); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	metrics.RegisterDBStats(db)
+
+	repos := &repositories{
+		unitOfWork: storage.NewUnitOfWork(db),
+		checks: map[string]healthService.Check{
+			"database": db.PingContext,
+			"migrations": func(ctx context.Context) error {
+				return connection.PendingMigrations(ctx, migrator)
+			},
+		},
+		close: db.Close,
+	}
+	dialect := storage.Postgres
+	if driver == config.StorageDriverSQLite {
+		dialect = sqlite.Dialect
+	}
+	repos.users = userRepo.NewUserRepository(db, dialect)
+	repos.passwordResets = passwordResetRepo.NewPasswordResetRepository(db, dialect)
+	repos.twoFactor = twoFactorRepo.NewTwoFactorRepository(db, dialect)
+	repos.oidcStates = oidcStateRepo.NewOIDCStateRepository(db, dialect)
+	repos.topics = topicRepo.NewTopicRepository(db, dialect)
+	repos.sessions = sessionRepo.NewSessionRepository(db, clk, dialect)
+	repos.votes = voteRepo.NewVoteRepository(db, dialect)
+	return repos, nil
+}
+
+func openDB(driver string) (*sql.DB, error) {
+	switch driver {
+	case config.StorageDriverPostgres:
+		return connection.NewDB()
+	case config.StorageDriverSQLite:
+		return connection.NewSQLiteDB(config.AppConfig.Database.SQLitePath)
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER desconhecido: %q", driver)
+	}
+}
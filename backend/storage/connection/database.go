@@ -4,10 +4,16 @@ import (
 	"database/sql"
 	"desafio-tecnico-fullstack/backend/config"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
+const maxConnectBackoff = 10 * time.Second
+
 func NewDB() (*sql.DB, error) {
 	user := config.AppConfig.Database.User
 	password := config.AppConfig.Database.Password
@@ -16,10 +22,39 @@ func NewDB() (*sql.DB, error) {
 	port := config.AppConfig.Database.Port
 
 	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", user, password, host, port, dbname)
-	db, err := sql.Open("postgres", connStr)
+	db, err := otelsql.Open("postgres", connStr,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = retryWithBackoff(db.Ping, config.AppConfig.Database.ConnectAttempts, config.AppConfig.Database.ConnectBackoff, time.Sleep)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
 	return db, nil
 }
+
+func retryWithBackoff(ping func() error, attempts int, backoff time.Duration, sleep func(time.Duration)) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = ping(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		slog.Warn("banco indisponível, tentando novamente", "attempt", attempt, "attempts", attempts, "error", err, "backoff", backoff.String())
+		sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+	return fmt.Errorf("banco indisponível após %d tentativas: %w", attempts, err)
+}
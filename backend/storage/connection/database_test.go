@@ -0,0 +1,52 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryWithBackoff_SucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	ping := func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	var sleeps []time.Duration
+
+	err := retryWithBackoff(ping, 5, time.Second, func(d time.Duration) { sleeps = append(sleeps, d) })
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("esperava 3 tentativas, obteve %d", calls)
+	}
+	if len(sleeps) != 2 || sleeps[0] != time.Second || sleeps[1] != 2*time.Second {
+		t.Errorf("backoff incorreto: %v", sleeps)
+	}
+}
+
+func TestRetryWithBackoff_GivesUp(t *testing.T) {
+	calls := 0
+	ping := func() error {
+		calls++
+		return errors.New("connection refused")
+	}
+	var sleeps []time.Duration
+
+	err := retryWithBackoff(ping, 6, 4*time.Second, func(d time.Duration) { sleeps = append(sleeps, d) })
+	if err == nil {
+		t.Fatal("esperava erro após esgotar as tentativas")
+	}
+	if calls != 6 {
+		t.Errorf("esperava 6 tentativas, obteve %d", calls)
+	}
+	for _, d := range sleeps {
+		if d > maxConnectBackoff {
+			t.Errorf("backoff %s excede o máximo de %s", d, maxConnectBackoff)
+		}
+	}
+}
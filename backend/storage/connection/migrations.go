@@ -0,0 +1,42 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/migrations"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+)
+
+// NewMigrator returns a goose provider over the migrations embedded for the
+// given storage driver.
+func NewMigrator(db *sql.DB, driver string) (*goose.Provider, error) {
+	switch driver {
+	case config.StorageDriverPostgres:
+		return goose.NewProvider(goose.DialectPostgres, db, migrations.FS)
+	case config.StorageDriverSQLite:
+		fsys, err := fs.Sub(migrations.FS, "sqlite")
+		if err != nil {
+			return nil, err
+		}
+		return goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	default:
+		return nil, fmt.Errorf("armazenamento %q não usa migrações", driver)
+	}
+}
+
+// PendingMigrations reports an error when the database is behind the
+// migrations embedded in the binary.
+func PendingMigrations(ctx context.Context, migrator *goose.Provider) error {
+	current, target, err := migrator.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if current < target {
+		return fmt.Errorf("banco na versão %d, esperada %d", current, target)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package connection
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/config"
+)
+
+func TestNewMigrator_DialectsShareVersions(t *testing.T) {
+	// Listing sources never touches the database, so any handle will do.
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "votacao.db"))
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	defer db.Close()
+
+	postgres, err := NewMigrator(db, config.StorageDriverPostgres)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	sqlite, err := NewMigrator(db, config.StorageDriverSQLite)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	pgSources, sqliteSources := postgres.ListSources(), sqlite.ListSources()
+	if len(pgSources) == 0 || len(pgSources) != len(sqliteSources) {
+		t.Fatalf("esperava as mesmas migrações nos dois dialetos, obteve %d e %d", len(pgSources), len(sqliteSources))
+	}
+	for i := range pgSources {
+		if pgSources[i].Version != sqliteSources[i].Version {
+			t.Errorf("versão %d do Postgres sem equivalente no SQLite (%d)", pgSources[i].Version, sqliteSources[i].Version)
+		}
+	}
+}
+
+func TestNewMigrator_MemoryHasNoMigrations(t *testing.T) {
+	if _, err := NewMigrator(nil, config.StorageDriverMemory); err == nil {
+		t.Error("esperava erro para armazenamento em memória")
+	}
+}
+
+func TestMigrator_UpDownAndPending(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "votacao.db"))
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := NewMigrator(db, config.StorageDriverSQLite)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	if err := PendingMigrations(ctx, migrator); err == nil {
+		t.Error("esperava migrações pendentes em um banco vazio")
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+	if err := PendingMigrations(ctx, migrator); err != nil {
+		t.Errorf("esperava banco atualizado, obteve: %v", err)
+	}
+
+	if _, err := migrator.Down(ctx); err != nil {
+		t.Fatalf("erro ao reverter migração: %v", err)
+	}
+	if err := PendingMigrations(ctx, migrator); err == nil {
+		t.Error("esperava migração pendente após reverter a última")
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("erro ao reaplicar migrações: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package connection
+
+import (
+	"database/sql"
+	"net/url"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	_ "modernc.org/sqlite"
+)
+
+// NewSQLiteDB opens the database file at path with foreign keys enforced.
+// SQLite allows a single writer, so the pool is limited to one connection and
+// concurrent requests queue for it instead of failing with SQLITE_BUSY.
+func NewSQLiteDB(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+
+	db, err := otelsql.Open("sqlite", "file:"+path+"?"+params.Encode(),
+		otelsql.WithAttributes(semconv.DBSystemSqlite),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
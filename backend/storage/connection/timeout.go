@@ -0,0 +1,15 @@
+package connection
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/config"
+)
+
+// WithQueryTimeout bounds a single query by the configured DB_QUERY_TIMEOUT,
+// on top of whatever deadline the caller's context already carries.
+func WithQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if config.AppConfig == nil || config.AppConfig.Database.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, config.AppConfig.Database.QueryTimeout)
+}
@@ -0,0 +1,41 @@
+package connection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"desafio-tecnico-fullstack/backend/config"
+)
+
+func TestWithQueryTimeout_AppliesConfiguredDeadline(t *testing.T) {
+	previous := config.AppConfig
+	defer func() { config.AppConfig = previous }()
+	config.AppConfig = &config.Config{Database: config.DatabaseConfig{QueryTimeout: time.Second}}
+
+	ctx, cancel := WithQueryTimeout(context.Background())
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		t.Fatal("esperava prazo definido na consulta")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Second {
+		t.Errorf("prazo inesperado: %v", remaining)
+	}
+}
+
+func TestWithQueryTimeout_KeepsCallerCancellation(t *testing.T) {
+	previous := config.AppConfig
+	defer func() { config.AppConfig = previous }()
+	config.AppConfig = &config.Config{Database: config.DatabaseConfig{QueryTimeout: time.Minute}}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := WithQueryTimeout(parent)
+	defer cancel()
+
+	cancelParent()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("esperava consulta cancelada junto com a requisição, obteve %v", ctx.Err())
+	}
+}
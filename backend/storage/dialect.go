@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Dialect adapts the SQL repositories, whose queries are written for
+// Postgres, to another database. The zero value is Postgres itself.
+type Dialect struct {
+	// Rebind rewrites the $n placeholders of a query.
+	Rebind func(query string) string
+	// TranslateError rewords driver errors the way Postgres reports them,
+	// since services match on the message.
+	TranslateError func(table string, err error) error
+}
+
+var Postgres = Dialect{}
+
+// Conn is the package-level Conn with the queries rebound for the dialect.
+func (d Dialect) Conn(ctx context.Context, db DBTX) DBTX {
+	conn := Conn(ctx, db)
+	if d.Rebind == nil {
+		return conn
+	}
+	return rebinder{db: conn, rebind: d.Rebind}
+}
+
+// Error translates a failed write to table; nil stays nil.
+func (d Dialect) Error(table string, err error) error {
+	if err == nil || d.TranslateError == nil {
+		return err
+	}
+	return d.TranslateError(table, err)
+}
+
+type rebinder struct {
+	db     DBTX
+	rebind func(query string) string
+}
+
+func (r rebinder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.db.ExecContext(ctx, r.rebind(query), args...)
+}
+
+func (r rebinder) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.db.QueryContext(ctx, r.rebind(query), args...)
+}
+
+func (r rebinder) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.db.QueryRowContext(ctx, r.rebind(query), args...)
+}
@@ -0,0 +1,14 @@
+package storage
+
+import "fmt"
+
+// UniqueViolation and ForeignKeyViolation word constraint errors the way
+// Postgres does, so callers matching on the message behave the same with
+// every backend.
+func UniqueViolation(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
+
+func ForeignKeyViolation(table, constraint string) error {
+	return fmt.Errorf("insert or update on table %q violates foreign key constraint %q", table, constraint)
+}
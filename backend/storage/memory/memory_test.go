@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/storage/storagetest"
+)
+
+func TestMemoryStorage_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clk clock.Clock) storagetest.Repositories {
+		store := NewStore()
+		return storagetest.Repositories{
+			Users:          NewUserRepository(store),
+			Topics:         NewTopicRepository(store),
+			Sessions:       NewSessionRepository(store, clk),
+			Votes:          NewVoteRepository(store),
+			PasswordResets: NewPasswordResetRepository(store),
+			TwoFactor:      NewTwoFactorRepository(store),
+			OIDCStates:     NewOIDCStateRepository(store),
+			UnitOfWork:     NewUnitOfWork(store),
+		}
+	})
+}
+
+func TestUserRepository_ListNegativeOffset(t *testing.T) {
+	repo := NewUserRepository(NewStore())
+	if err := repo.AddUser(context.Background(), models.User{Name: "Maria", CPF: "12345678901", Role: models.RoleAssociate}); err != nil {
+		t.Fatal(err)
+	}
+
+	users, total, err := repo.ListUsers(context.Background(), user.ListFilter{Limit: 10, Offset: -10})
+	if err != nil || total != 1 || len(users) != 1 {
+		t.Errorf("esperava o primeiro usuário, obteve %v, %d, %v", users, total, err)
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+)
+
+type oidcStateRepository struct {
+	store *Store
+}
+
+func NewOIDCStateRepository(store *Store) oidcstate.OIDCStateRepository {
+	return &oidcStateRepository{store: store}
+}
+
+func (r *oidcStateRepository) CreateLoginState(ctx context.Context, s models.OIDCLoginState) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.state.oidcStates[s.StateHash]; ok {
+		return storage.UniqueViolation("oidc_login_states_pkey")
+	}
+	r.store.state.oidcStates[s.StateHash] = s
+	return nil
+}
+
+func (r *oidcStateRepository) UseLoginState(ctx context.Context, stateHash string, now int64) (*models.OIDCLoginState, error) {
+	defer r.store.lock(ctx)()
+
+	s, ok := r.store.state.oidcStates[stateHash]
+	delete(r.store.state.oidcStates, stateHash)
+	if !ok || s.ExpiresAt <= now {
+		return nil, sql.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (r *oidcStateRepository) DeleteExpiredLoginStates(ctx context.Context, now int64) error {
+	defer r.store.lock(ctx)()
+
+	for hash, s := range r.store.state.oidcStates {
+		if s.ExpiresAt <= now {
+			delete(r.store.state.oidcStates, hash)
+		}
+	}
+	return nil
+}
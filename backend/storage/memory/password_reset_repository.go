@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	"slices"
+)
+
+type passwordResetRepository struct {
+	store *Store
+}
+
+func NewPasswordResetRepository(store *Store) passwordreset.PasswordResetRepository {
+	return &passwordResetRepository{store: store}
+}
+
+func (r *passwordResetRepository) CreateResetToken(ctx context.Context, t models.PasswordResetToken) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userExists(t.UserID) {
+		return storage.ForeignKeyViolation("password_reset_tokens", "password_reset_tokens_user_id_fkey")
+	}
+	if slices.ContainsFunc(r.store.state.resetTokens, func(existing models.PasswordResetToken) bool { return existing.TokenHash == t.TokenHash }) {
+		return storage.UniqueViolation("password_reset_tokens_token_hash_key")
+	}
+	t.ID = r.store.nextID("password_reset_tokens")
+	t.UsedAt = nil
+	r.store.state.resetTokens = append(r.store.state.resetTokens, t)
+	return nil
+}
+
+func (r *passwordResetRepository) UseResetToken(ctx context.Context, tokenHash string, now int64) (int, error) {
+	defer r.store.lock(ctx)()
+
+	for i, t := range r.store.state.resetTokens {
+		if t.TokenHash == tokenHash && t.UsedAt == nil && t.ExpiresAt > now {
+			r.store.state.resetTokens[i].UsedAt = &now
+			return t.UserID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (r *passwordResetRepository) InvalidateResetTokens(ctx context.Context, userID int, now int64) error {
+	defer r.store.lock(ctx)()
+
+	for i, t := range r.store.state.resetTokens {
+		if t.UserID == userID && t.UsedAt == nil {
+			r.store.state.resetTokens[i].UsedAt = &now
+		}
+	}
+	return nil
+}
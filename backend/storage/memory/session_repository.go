@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/session"
+)
+
+type sessionRepository struct {
+	store *Store
+	clock clock.Clock
+}
+
+func NewSessionRepository(store *Store, clock clock.Clock) session.SessionRepository {
+	return &sessionRepository{store: store, clock: clock}
+}
+
+func (r *sessionRepository) OpenSession(ctx context.Context, topicID int, openAt, closeAt int64) error {
+	defer r.store.lock(ctx)()
+
+	ti := r.store.topicIndex(topicID)
+	if ti < 0 {
+		return storage.ForeignKeyViolation("sessions", "sessions_topic_id_fkey")
+	}
+
+	round := 1
+	if latest, ok := r.store.latestSession(topicID); ok {
+		stored := &r.store.state.sessions[r.store.sessionIndex(latest.ID)]
+		if stored.Status == models.SessionStatusOpen && stored.CloseAt < openAt {
+			stored.Status = models.SessionStatusClosed
+		}
+		if stored.Status != models.SessionStatusClosed {
+			return storage.UniqueViolation("sessions_one_active_round_key")
+		}
+		round = latest.Round + 1
+	}
+	r.store.state.sessions = append(r.store.state.sessions, models.Session{
+		ID:      r.store.nextID("sessions"),
+		TopicID: topicID,
+		Round:   round,
+		OpenAt:  openAt,
+		CloseAt: closeAt,
+		Status:  models.SessionStatusOpen,
+	})
+	r.store.state.topics[ti].Status = models.TopicStatusOpen
+	return nil
+}
+
+func (r *sessionRepository) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
+	defer r.store.lock(ctx)()
+
+	latest, ok := r.store.latestSession(topicID)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	latest.PausedAt = copyInt64(latest.PausedAt)
+	return &latest, nil
+}
+
+func (r *sessionRepository) UpdateExpiredSessions(ctx context.Context) (int64, error) {
+	defer r.store.lock(ctx)()
+
+	now := r.clock.Now().Unix()
+
+	var updated int64
+	for i, t := range r.store.state.topics {
+		if t.Status != models.TopicStatusOpen {
+			continue
+		}
+		latest, ok := r.store.latestSession(t.ID)
+		if ok && latest.CloseAt < now && latest.Status == models.SessionStatusOpen {
+			r.store.state.topics[i].Status = models.TopicStatusClosed
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *sessionRepository) UpdateSession(ctx context.Context, s models.Session, topicStatus string) error {
+	defer r.store.lock(ctx)()
+
+	if i := r.store.sessionIndex(s.ID); i >= 0 {
+		stored := &r.store.state.sessions[i]
+		stored.CloseAt = s.CloseAt
+		stored.Status = s.Status
+		stored.PausedAt = copyInt64(s.PausedAt)
+	}
+	if i := r.store.topicIndex(s.TopicID); i >= 0 {
+		r.store.state.topics[i].Status = topicStatus
+	}
+	return nil
+}
+
+func (r *sessionRepository) AddSessionEvent(ctx context.Context, event models.SessionEvent) error {
+	defer r.store.lock(ctx)()
+
+	if r.store.sessionIndex(event.SessionID) < 0 {
+		return storage.ForeignKeyViolation("session_events", "session_events_session_id_fkey")
+	}
+	if !r.store.userExists(event.UserID) {
+		return storage.ForeignKeyViolation("session_events", "session_events_user_id_fkey")
+	}
+	event.ID = r.store.nextID("session_events")
+	r.store.state.events = append(r.store.state.events, event)
+	return nil
+}
+
+func (r *sessionRepository) ListSessionEvents(ctx context.Context, sessionID int) ([]models.SessionEvent, error) {
+	defer r.store.lock(ctx)()
+
+	events := []models.SessionEvent{}
+	for _, e := range r.store.state.events {
+		if e.SessionID == sessionID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Store keeps every table in memory. Repositories created from the same Store
+// share its data, and a UnitOfWork over it holds the lock for the whole unit
+// so other callers never observe half-applied changes.
+type Store struct {
+	mu    sync.Mutex
+	state state
+}
+
+type state struct {
+	users         []models.User
+	topics        []models.Topic
+	sessions      []models.Session
+	events        []models.SessionEvent
+	votes         []models.Vote
+	resetTokens   []models.PasswordResetToken
+	totp          map[int]models.UserTOTP
+	recoveryCodes []recoveryCode
+	oidcStates    map[string]models.OIDCLoginState
+	lastID        map[string]int
+}
+
+func NewStore() *Store {
+	return &Store{state: state{totp: map[int]models.UserTOTP{}, oidcStates: map[string]models.OIDCLoginState{}, lastID: map[string]int{}}}
+}
+
+type txKey struct{}
+
+func (s *Store) lock(ctx context.Context) func() {
+	if ctx.Value(txKey{}) == s {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) nextID(table string) int {
+	s.state.lastID[table]++
+	return s.state.lastID[table]
+}
+
+func (st state) clone() state {
+	sessions := slices.Clone(st.sessions)
+	for i := range sessions {
+		sessions[i].PausedAt = copyInt64(sessions[i].PausedAt)
+	}
+	users := make([]models.User, len(st.users))
+	for i, u := range st.users {
+		users[i] = *copyUser(u)
+	}
+	resetTokens := slices.Clone(st.resetTokens)
+	for i := range resetTokens {
+		resetTokens[i].UsedAt = copyInt64(resetTokens[i].UsedAt)
+	}
+	totp := make(map[int]models.UserTOTP, len(st.totp))
+	for userID, t := range st.totp {
+		t.EnabledAt = copyInt64(t.EnabledAt)
+		totp[userID] = t
+	}
+	recoveryCodes := slices.Clone(st.recoveryCodes)
+	for i := range recoveryCodes {
+		recoveryCodes[i].usedAt = copyInt64(recoveryCodes[i].usedAt)
+	}
+	lastID := make(map[string]int, len(st.lastID))
+	for table, id := range st.lastID {
+		lastID[table] = id
+	}
+	return state{
+		users:         users,
+		topics:        slices.Clone(st.topics),
+		sessions:      sessions,
+		events:        slices.Clone(st.events),
+		votes:         slices.Clone(st.votes),
+		resetTokens:   resetTokens,
+		totp:          totp,
+		recoveryCodes: recoveryCodes,
+		oidcStates:    maps.Clone(st.oidcStates),
+		lastID:        lastID,
+	}
+}
+
+func (s *Store) topicIndex(id int) int {
+	return slices.IndexFunc(s.state.topics, func(t models.Topic) bool { return t.ID == id })
+}
+
+func (s *Store) sessionIndex(id int) int {
+	return slices.IndexFunc(s.state.sessions, func(sess models.Session) bool { return sess.ID == id })
+}
+
+func (s *Store) userIndex(id int) int {
+	return slices.IndexFunc(s.state.users, func(u models.User) bool { return u.ID == id })
+}
+
+func (s *Store) userExists(id int) bool {
+	return s.userIndex(id) >= 0
+}
+
+// latestSession mirrors "ORDER BY round DESC LIMIT 1" for a topic.
+func (s *Store) latestSession(topicID int) (models.Session, bool) {
+	var latest models.Session
+	found := false
+	for _, sess := range s.state.sessions {
+		if sess.TopicID == topicID && (!found || sess.Round > latest.Round) {
+			latest, found = sess, true
+		}
+	}
+	return latest, found
+}
+
+// copyUser detaches the returned user from the stored one.
+func copyUser(u models.User) *models.User {
+	u.LockedUntil = copyInt64(u.LockedUntil)
+	u.PasswordChangedAt = copyInt64(u.PasswordChangedAt)
+	u.DeactivatedAt = copyInt64(u.DeactivatedAt)
+	return &u
+}
+
+func copyInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+type unitOfWork struct {
+	store *Store
+}
+
+func NewUnitOfWork(store *Store) storage.UnitOfWork {
+	return &unitOfWork{store: store}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := u.store
+	if ctx.Value(txKey{}) == s {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.state.clone()
+	defer func() {
+		if p := recover(); p != nil {
+			s.state = snapshot
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, s)); err != nil {
+		s.state = snapshot
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage/repository/topic"
+	"slices"
+)
+
+type topicRepository struct {
+	store *Store
+}
+
+func NewTopicRepository(store *Store) topic.TopicRepository {
+	return &topicRepository{store: store}
+}
+
+func (r *topicRepository) CreateTopic(ctx context.Context, t models.Topic) error {
+	defer r.store.lock(ctx)()
+
+	t.ID = r.store.nextID("topics")
+	r.store.state.topics = append(r.store.state.topics, t)
+	return nil
+}
+
+func (r *topicRepository) ListTopics(ctx context.Context) ([]models.Topic, error) {
+	defer r.store.lock(ctx)()
+
+	topics := slices.Clone(r.store.state.topics)
+	if topics == nil {
+		topics = []models.Topic{}
+	}
+	return topics, nil
+}
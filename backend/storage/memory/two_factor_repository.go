@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/twofactor"
+	"slices"
+)
+
+type recoveryCode struct {
+	userID   int
+	codeHash string
+	usedAt   *int64
+}
+
+type twoFactorRepository struct {
+	store *Store
+}
+
+func NewTwoFactorRepository(store *Store) twofactor.TwoFactorRepository {
+	return &twoFactorRepository{store: store}
+}
+
+func (r *twoFactorRepository) GetTOTP(ctx context.Context, userID int) (*models.UserTOTP, error) {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.state.totp[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	t.EnabledAt = copyInt64(t.EnabledAt)
+	return &t, nil
+}
+
+func (r *twoFactorRepository) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userExists(userID) {
+		return storage.ForeignKeyViolation("user_totp", "user_totp_user_id_fkey")
+	}
+	r.store.state.totp[userID] = models.UserTOTP{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, userID int, enabledAt int64) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.state.totp[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	t.EnabledAt = &enabledAt
+	r.store.state.totp[userID] = t
+	return nil
+}
+
+func (r *twoFactorRepository) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.state.totp[userID]
+	if !ok || t.LastUsedStep >= step {
+		return sql.ErrNoRows
+	}
+	t.LastUsedStep = step
+	r.store.state.totp[userID] = t
+	return nil
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userExists(userID) {
+		return storage.ForeignKeyViolation("totp_recovery_codes", "totp_recovery_codes_user_id_fkey")
+	}
+	codes := slices.DeleteFunc(r.store.state.recoveryCodes, func(c recoveryCode) bool { return c.userID == userID })
+	for _, hash := range codeHashes {
+		codes = append(codes, recoveryCode{userID: userID, codeHash: hash})
+	}
+	r.store.state.recoveryCodes = codes
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, now int64) error {
+	defer r.store.lock(ctx)()
+
+	for i, c := range r.store.state.recoveryCodes {
+		if c.userID == userID && c.codeHash == codeHash && c.usedAt == nil {
+			r.store.state.recoveryCodes[i].usedAt = &now
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"slices"
+	"strings"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) user.UserRepository {
+	return &userRepository{store: store}
+}
+
+func (r *userRepository) AddUser(ctx context.Context, u models.User) error {
+	defer r.store.lock(ctx)()
+
+	if slices.ContainsFunc(r.store.state.users, func(existing models.User) bool { return existing.CPF == u.CPF }) {
+		return storage.UniqueViolation("users_cpf_key")
+	}
+	u.ID = r.store.nextID("users")
+	r.store.state.users = append(r.store.state.users, u)
+	return nil
+}
+
+func (r *userRepository) GetUserByCPF(ctx context.Context, cpf string) *models.User {
+	defer r.store.lock(ctx)()
+
+	i := slices.IndexFunc(r.store.state.users, func(u models.User) bool { return u.CPF == cpf })
+	if i < 0 {
+		return nil
+	}
+	return copyUser(r.store.state.users[i])
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id int) *models.User {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(id)
+	if i < 0 {
+		return nil
+	}
+	return copyUser(r.store.state.users[i])
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hash string, changedAt int64) error {
+	defer r.store.lock(ctx)()
+
+	if i := r.store.userIndex(userID); i >= 0 {
+		u := &r.store.state.users[i]
+		u.Password = hash
+		u.PasswordChangedAt = &changedAt
+		u.FailedLoginAttempts = 0
+		u.LockedUntil = nil
+	}
+	return nil
+}
+
+func (r *userRepository) RecordLoginFailure(ctx context.Context, userID int) (int, error) {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return 0, sql.ErrNoRows
+	}
+	r.store.state.users[i].FailedLoginAttempts++
+	return r.store.state.users[i].FailedLoginAttempts, nil
+}
+
+func (r *userRepository) LockUser(ctx context.Context, userID int, until int64) error {
+	defer r.store.lock(ctx)()
+
+	if i := r.store.userIndex(userID); i >= 0 {
+		r.store.state.users[i].LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *userRepository) ResetLoginFailures(ctx context.Context, userID int) error {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	r.store.state.users[i].FailedLoginAttempts = 0
+	r.store.state.users[i].LockedUntil = nil
+	return nil
+}
+
+func (r *userRepository) UpdateName(ctx context.Context, userID int, name string) error {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	r.store.state.users[i].Name = name
+	return nil
+}
+
+func (r *userRepository) ListUsers(ctx context.Context, filter user.ListFilter) ([]models.User, int, error) {
+	defer r.store.lock(ctx)()
+
+	search := strings.ToLower(filter.Search)
+	var matches []models.User
+	for _, u := range r.store.state.users {
+		if strings.Contains(strings.ToLower(u.Name), search) || strings.HasPrefix(u.CPF, filter.Search) {
+			matches = append(matches, *copyUser(u))
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b models.User) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	users := []models.User{}
+	if offset := max(filter.Offset, 0); offset < len(matches) {
+		users = append(users, matches[offset:min(offset+filter.Limit, len(matches))]...)
+	}
+	return users, len(matches), nil
+}
+
+func (r *userRepository) SetDeactivated(ctx context.Context, userID int, deactivatedAt *int64) error {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	r.store.state.users[i].DeactivatedAt = copyInt64(deactivatedAt)
+	return nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, userID int, role string) error {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	r.store.state.users[i].Role = role
+	return nil
+}
+
+func (r *userRepository) UpdateMembership(ctx context.Context, userID int, name, email string, weight int) error {
+	defer r.store.lock(ctx)()
+
+	i := r.store.userIndex(userID)
+	if i < 0 {
+		return sql.ErrNoRows
+	}
+	u := &r.store.state.users[i]
+	u.Name, u.Email, u.Weight = name, email, weight
+	return nil
+}
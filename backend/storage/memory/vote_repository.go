@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"slices"
+)
+
+type voteRepository struct {
+	store *Store
+}
+
+func NewVoteRepository(store *Store) vote.VoteRepository {
+	return &voteRepository{store: store}
+}
+
+func (r *voteRepository) RegisterVote(ctx context.Context, v models.Vote) error {
+	defer r.store.lock(ctx)()
+
+	if r.store.topicIndex(v.TopicID) < 0 {
+		return storage.ForeignKeyViolation("votes", "votes_topic_id_fkey")
+	}
+	if r.store.sessionIndex(v.SessionID) < 0 {
+		return storage.ForeignKeyViolation("votes", "votes_session_id_fkey")
+	}
+	if !r.store.userExists(v.UserID) {
+		return storage.ForeignKeyViolation("votes", "votes_user_id_fkey")
+	}
+	if r.hasVoted(v.SessionID, v.UserID) {
+		return storage.UniqueViolation("votes_session_id_user_id_key")
+	}
+	v.ID = r.store.nextID("votes")
+	r.store.state.votes = append(r.store.state.votes, v)
+	return nil
+}
+
+func (r *voteRepository) HasUserVoted(ctx context.Context, sessionID int, userID int) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	return r.hasVoted(sessionID, userID), nil
+}
+
+func (r *voteRepository) hasVoted(sessionID, userID int) bool {
+	return slices.ContainsFunc(r.store.state.votes, func(v models.Vote) bool {
+		return v.SessionID == sessionID && v.UserID == userID
+	})
+}
+
+func (r *voteRepository) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
+	defer r.store.lock(ctx)()
+
+	latest, ok := r.store.latestSession(topicID)
+	if !ok {
+		return 0, 0, nil
+	}
+	yes, no = r.count(latest.ID)
+	return yes, no, nil
+}
+
+func (r *voteRepository) ListRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error) {
+	defer r.store.lock(ctx)()
+
+	results := []models.RoundResult{}
+	for _, s := range r.store.state.sessions {
+		if s.TopicID != topicID {
+			continue
+		}
+		yes, no := r.count(s.ID)
+		results = append(results, models.RoundResult{
+			SessionID: s.ID,
+			Round:     s.Round,
+			OpenAt:    s.OpenAt,
+			CloseAt:   s.CloseAt,
+			Yes:       yes,
+			No:        no,
+		})
+	}
+	slices.SortFunc(results, func(a, b models.RoundResult) int { return a.Round - b.Round })
+	return results, nil
+}
+
+func (r *voteRepository) count(sessionID int) (yes int, no int) {
+	for _, v := range r.store.state.votes {
+		if v.SessionID != sessionID {
+			continue
+		}
+		switch v.Choice {
+		case "Sim":
+			yes++
+		case "Não":
+			no++
+		}
+	}
+	return yes, no
+}
+
+func (r *voteRepository) GetVotingSummary(ctx context.Context, userID int, recent int) (models.VotingSummary, error) {
+	defer r.store.lock(ctx)()
+
+	summary := models.VotingSummary{Recent: []models.VoteRecord{}}
+	topics := map[int]bool{}
+	for _, v := range r.store.state.votes {
+		if v.UserID != userID {
+			continue
+		}
+		summary.TotalVotes++
+		topics[v.TopicID] = true
+		switch v.Choice {
+		case "Sim":
+			summary.Yes++
+		case "Não":
+			summary.No++
+		}
+		s := r.store.state.sessions[r.store.sessionIndex(v.SessionID)]
+		t := r.store.state.topics[r.store.topicIndex(v.TopicID)]
+		summary.Recent = append(summary.Recent, models.VoteRecord{TopicID: v.TopicID, TopicName: t.Name, Round: s.Round, Choice: v.Choice, OpenAt: s.OpenAt})
+	}
+	summary.Topics = len(topics)
+
+	// Votes are appended in insertion order, so a stable sort keeps the
+	// latest vote first among rounds opened at the same time.
+	slices.Reverse(summary.Recent)
+	slices.SortStableFunc(summary.Recent, func(a, b models.VoteRecord) int { return cmp.Compare(b.OpenAt, a.OpenAt) })
+	summary.Recent = summary.Recent[:min(recent, len(summary.Recent))]
+	return summary, nil
+}
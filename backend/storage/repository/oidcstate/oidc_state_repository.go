@@ -0,0 +1,59 @@
+package oidcstate
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+)
+
+type OIDCStateRepository interface {
+	CreateLoginState(ctx context.Context, s models.OIDCLoginState) error
+	// UseLoginState deletes the pending login and returns it, so each
+	// callback is processed only once. Unknown and expired states yield
+	// sql.ErrNoRows.
+	UseLoginState(ctx context.Context, stateHash string, now int64) (*models.OIDCLoginState, error)
+	// DeleteExpiredLoginStates drops the logins abandoned at the provider.
+	DeleteExpiredLoginStates(ctx context.Context, now int64) error
+}
+
+type oidcStateRepository struct {
+	db      storage.DBTX
+	dialect storage.Dialect
+}
+
+func NewOIDCStateRepository(db storage.DBTX, dialect storage.Dialect) OIDCStateRepository {
+	return &oidcStateRepository{db: db, dialect: dialect}
+}
+
+func (r *oidcStateRepository) CreateLoginState(ctx context.Context, s models.OIDCLoginState) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, expires_at, created_at) VALUES ($1, $2, $3, $4, $5)", s.StateHash, s.Nonce, s.CodeVerifier, s.ExpiresAt, s.CreatedAt)
+	return r.dialect.Error("oidc_login_states", err)
+}
+
+func (r *oidcStateRepository) UseLoginState(ctx context.Context, stateHash string, now int64) (*models.OIDCLoginState, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	s := models.OIDCLoginState{StateHash: stateHash}
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "DELETE FROM oidc_login_states WHERE state_hash = $1 RETURNING nonce, code_verifier, expires_at, created_at", stateHash).Scan(&s.Nonce, &s.CodeVerifier, &s.ExpiresAt, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if s.ExpiresAt <= now {
+		return nil, sql.ErrNoRows
+	}
+	return &s, nil
+}
+
+func (r *oidcStateRepository) DeleteExpiredLoginStates(ctx context.Context, now int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM oidc_login_states WHERE expires_at <= $1", now)
+	return err
+}
@@ -0,0 +1,52 @@
+package passwordreset
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+)
+
+type PasswordResetRepository interface {
+	CreateResetToken(ctx context.Context, t models.PasswordResetToken) error
+	// UseResetToken consumes a pending, unexpired token in a single statement,
+	// so it can be redeemed only once, and returns its user. Unknown, used and
+	// expired tokens yield sql.ErrNoRows.
+	UseResetToken(ctx context.Context, tokenHash string, now int64) (int, error)
+	// InvalidateResetTokens consumes every pending token of the user.
+	InvalidateResetTokens(ctx context.Context, userID int, now int64) error
+}
+
+type passwordResetRepository struct {
+	db      storage.DBTX
+	dialect storage.Dialect
+}
+
+func NewPasswordResetRepository(db storage.DBTX, dialect storage.Dialect) PasswordResetRepository {
+	return &passwordResetRepository{db: db, dialect: dialect}
+}
+
+func (r *passwordResetRepository) CreateResetToken(ctx context.Context, t models.PasswordResetToken) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4)", t.UserID, t.TokenHash, t.ExpiresAt, t.CreatedAt)
+	return r.dialect.Error("password_reset_tokens", err)
+}
+
+func (r *passwordResetRepository) UseResetToken(ctx context.Context, tokenHash string, now int64) (int, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var userID int
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "UPDATE password_reset_tokens SET used_at = $2 WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2 RETURNING user_id", tokenHash, now).Scan(&userID)
+	return userID, err
+}
+
+func (r *passwordResetRepository) InvalidateResetTokens(ctx context.Context, userID int, now int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE password_reset_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL", userID, now)
+	return err
+}
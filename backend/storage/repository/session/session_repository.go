@@ -1,32 +1,53 @@
 package session
 
 import (
+	"context"
 	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
 	"desafio-tecnico-fullstack/backend/models"
-	"time"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
 )
 
 type SessionRepository interface {
-	OpenSession(topicID int, openAt, closeAt int64) error
-	GetSessionByTopic(topicID int) (*models.Session, error)
-	UpdateExpiredSessions() error
+	OpenSession(ctx context.Context, topicID int, openAt, closeAt int64) error
+	GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error)
+	UpdateExpiredSessions(ctx context.Context) (int64, error)
+	UpdateSession(ctx context.Context, session models.Session, topicStatus string) error
+	AddSessionEvent(ctx context.Context, event models.SessionEvent) error
+	ListSessionEvents(ctx context.Context, sessionID int) ([]models.SessionEvent, error)
 }
 
 type sessionRepository struct {
-	db *sql.DB
+	db      storage.DBTX
+	clock   clock.Clock
+	dialect storage.Dialect
 }
 
-func NewSessionRepository(db *sql.DB) SessionRepository {
-	return &sessionRepository{db: db}
+func NewSessionRepository(db storage.DBTX, clock clock.Clock, dialect storage.Dialect) SessionRepository {
+	return &sessionRepository{db: db, clock: clock, dialect: dialect}
 }
 
-func (r *sessionRepository) OpenSession(topicID int, openAt, closeAt int64) error {
-	_, err := r.db.Exec("INSERT INTO sessions (topic_id, open_at, close_at) VALUES ($1, $2, $3)", topicID, openAt, closeAt)
+func (r *sessionRepository) OpenSession(ctx context.Context, topicID int, openAt, closeAt int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	// An expired round still says 'open', which the single active round index
+	// would count against the new one.
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE sessions SET status = 'closed' WHERE topic_id = $1 AND status = 'open' AND close_at < $2", topicID, openAt)
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.Exec("UPDATE topics SET status = 'Sessão Aberta' WHERE id = $1", topicID)
+	_, err = r.dialect.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO sessions (topic_id, round, open_at, close_at)
+		VALUES ($1, COALESCE((SELECT MAX(round) FROM sessions WHERE topic_id = $1), 0) + 1, $2, $3)
+	`, topicID, openAt, closeAt)
+	if err != nil {
+		return r.dialect.Error("sessions", err)
+	}
+
+	_, err = r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE topics SET status = 'Sessão Aberta' WHERE id = $1", topicID)
 	if err != nil {
 		return err
 	}
@@ -34,27 +55,93 @@ func (r *sessionRepository) OpenSession(topicID int, openAt, closeAt int64) erro
 	return nil
 }
 
-func (r *sessionRepository) GetSessionByTopic(topicID int) (*models.Session, error) {
+func (r *sessionRepository) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var s models.Session
-	err := r.db.QueryRow("SELECT id, topic_id, open_at, close_at FROM sessions WHERE topic_id = $1", topicID).Scan(&s.ID, &s.TopicID, &s.OpenAt, &s.CloseAt)
+	var pausedAt sql.NullInt64
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT id, topic_id, round, open_at, close_at, status, paused_at
+		FROM sessions
+		WHERE topic_id = $1
+		ORDER BY round DESC
+		LIMIT 1
+	`, topicID).Scan(&s.ID, &s.TopicID, &s.Round, &s.OpenAt, &s.CloseAt, &s.Status, &pausedAt)
 	if err != nil {
 		return nil, err
 	}
+	if pausedAt.Valid {
+		s.PausedAt = &pausedAt.Int64
+	}
 	return &s, nil
 }
 
-func (r *sessionRepository) UpdateExpiredSessions() error {
-	now := time.Now().Unix()
+// UpdateExpiredSessions only looks at the latest round of each topic: the
+// sessions of earlier rounds are never closed and would end a reopened vote.
+func (r *sessionRepository) UpdateExpiredSessions(ctx context.Context) (int64, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	now := r.clock.Now().Unix()
 
-	_, err := r.db.Exec(`
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, `
 		UPDATE topics 
 		SET status = 'Votação Encerrada' 
 		WHERE id IN (
 			SELECT topic_id 
 			FROM sessions 
-			WHERE close_at < $1
+			WHERE close_at < $1 AND status = 'open'
+				AND round = (SELECT MAX(round) FROM sessions s2 WHERE s2.topic_id = sessions.topic_id)
 		) AND status = 'Sessão Aberta'
 	`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *sessionRepository) UpdateSession(ctx context.Context, session models.Session, topicStatus string) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE sessions SET close_at = $1, status = $2, paused_at = $3 WHERE id = $4",
+		session.CloseAt, session.Status, session.PausedAt, session.ID)
+	if err != nil {
+		return err
+	}
 
+	_, err = r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE topics SET status = $1 WHERE id = $2", topicStatus, session.TopicID)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *sessionRepository) AddSessionEvent(ctx context.Context, event models.SessionEvent) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO session_events (session_id, user_id, type, close_at, created_at) VALUES ($1, $2, $3, $4, $5)",
+		event.SessionID, event.UserID, event.Type, event.CloseAt, event.CreatedAt)
+	return r.dialect.Error("session_events", err)
+}
+
+func (r *sessionRepository) ListSessionEvents(ctx context.Context, sessionID int) ([]models.SessionEvent, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.dialect.Conn(ctx, r.db).QueryContext(ctx, "SELECT id, session_id, user_id, type, close_at, created_at FROM session_events WHERE session_id = $1 ORDER BY id", sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.SessionEvent{}
+	for rows.Next() {
+		var e models.SessionEvent
+		if err := rows.Scan(&e.ID, &e.SessionID, &e.UserID, &e.Type, &e.CloseAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
@@ -1,30 +1,39 @@
 package topic
 
 import (
-	"database/sql"
+	"context"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
 )
 
 type TopicRepository interface {
-	CreateTopic(topic models.Topic) error
-	ListTopics() ([]models.Topic, error)
+	CreateTopic(ctx context.Context, topic models.Topic) error
+	ListTopics(ctx context.Context) ([]models.Topic, error)
 }
 
 type topicRepository struct {
-	db *sql.DB
+	db      storage.DBTX
+	dialect storage.Dialect
 }
 
-func NewTopicRepository(db *sql.DB) TopicRepository {
-	return &topicRepository{db: db}
+func NewTopicRepository(db storage.DBTX, dialect storage.Dialect) TopicRepository {
+	return &topicRepository{db: db, dialect: dialect}
 }
 
-func (r *topicRepository) CreateTopic(topic models.Topic) error {
-	_, err := r.db.Exec("INSERT INTO topics (name, status) VALUES ($1, $2)", topic.Name, topic.Status)
+func (r *topicRepository) CreateTopic(ctx context.Context, topic models.Topic) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO topics (name, status) VALUES ($1, $2)", topic.Name, topic.Status)
 	return err
 }
 
-func (r *topicRepository) ListTopics() ([]models.Topic, error) {
-	rows, err := r.db.Query("SELECT id, name, status FROM topics")
+func (r *topicRepository) ListTopics(ctx context.Context) ([]models.Topic, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.dialect.Conn(ctx, r.db).QueryContext(ctx, "SELECT id, name, status FROM topics")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,117 @@
+package twofactor
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+)
+
+type TwoFactorRepository interface {
+	// GetTOTP returns sql.ErrNoRows when the user never enrolled.
+	GetTOTP(ctx context.Context, userID int) (*models.UserTOTP, error)
+	// SaveTOTPSecret starts a new, not yet enabled, enrollment, replacing any
+	// previous one.
+	SaveTOTPSecret(ctx context.Context, userID int, secret string) error
+	EnableTOTP(ctx context.Context, userID int, enabledAt int64) error
+	// UseTOTPStep records step as used unless it is not newer than the last
+	// used one, in which case it yields sql.ErrNoRows.
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores new
+	// ones. Callers should run it in a unit of work.
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode consumes an unused code of the user; anything else
+	// yields sql.ErrNoRows.
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string, now int64) error
+}
+
+type twoFactorRepository struct {
+	db      storage.DBTX
+	dialect storage.Dialect
+}
+
+func NewTwoFactorRepository(db storage.DBTX, dialect storage.Dialect) TwoFactorRepository {
+	return &twoFactorRepository{db: db, dialect: dialect}
+}
+
+func (r *twoFactorRepository) GetTOTP(ctx context.Context, userID int) (*models.UserTOTP, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	t := models.UserTOTP{UserID: userID}
+	var enabledAt sql.NullInt64
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT secret, enabled_at, last_used_step FROM user_totp WHERE user_id = $1", userID).Scan(&t.Secret, &enabledAt, &t.LastUsedStep)
+	if err != nil {
+		return nil, err
+	}
+	if enabledAt.Valid {
+		t.EnabledAt = &enabledAt.Int64
+	}
+	return &t, nil
+}
+
+func (r *twoFactorRepository) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, enabled_at = NULL, last_used_step = 0
+	`, userID, secret)
+	return r.dialect.Error("user_totp", err)
+}
+
+func (r *twoFactorRepository) EnableTOTP(ctx context.Context, userID int, enabledAt int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE user_totp SET enabled_at = $2 WHERE user_id = $1", userID, enabledAt)
+	return requireAffected(result, err)
+}
+
+func (r *twoFactorRepository) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE user_totp SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2", userID, step)
+	return requireAffected(result, err)
+}
+
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	conn := r.dialect.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, "DELETE FROM totp_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := conn.ExecContext(ctx, "INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+			return r.dialect.Error("totp_recovery_codes", err)
+		}
+	}
+	return nil
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string, now int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE totp_recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL", userID, codeHash, now)
+	return requireAffected(result, err)
+}
+
+func requireAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -1,33 +1,201 @@
 package user
 
 import (
+	"context"
 	"database/sql"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+	"strings"
 )
 
 type UserRepository interface {
-	AddUser(u models.User) error
-	GetUserByCPF(cpf string) *models.User
+	AddUser(ctx context.Context, u models.User) error
+	GetUserByCPF(ctx context.Context, cpf string) *models.User
+	GetUserByID(ctx context.Context, id int) *models.User
+	UpdatePassword(ctx context.Context, userID int, hash string, changedAt int64) error
+	UpdateName(ctx context.Context, userID int, name string) error
+	RecordLoginFailure(ctx context.Context, userID int) (int, error)
+	LockUser(ctx context.Context, userID int, until int64) error
+	ResetLoginFailures(ctx context.Context, userID int) error
+	// ListUsers returns a page of the users matching the filter, ordered by
+	// name, and how many match in total.
+	ListUsers(ctx context.Context, filter ListFilter) ([]models.User, int, error)
+	// SetDeactivated deactivates the user at the given time, or reactivates
+	// them when it is nil.
+	SetDeactivated(ctx context.Context, userID int, deactivatedAt *int64) error
+	UpdateRole(ctx context.Context, userID int, role string) error
+	// UpdateMembership replaces the data kept about the associate, leaving
+	// credentials and role alone.
+	UpdateMembership(ctx context.Context, userID int, name, email string, weight int) error
+}
+
+// ListFilter.Search matches names containing it or CPFs starting with it;
+// empty matches everyone.
+type ListFilter struct {
+	Search string
+	Limit  int
+	Offset int
+}
+
+// LikePattern escapes the LIKE wildcards in s, for use with ESCAPE '\'.
+func LikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 type userRepository struct {
-	db *sql.DB
+	db      storage.DBTX
+	dialect storage.Dialect
 }
 
-func NewUserRepository(db *sql.DB) UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db storage.DBTX, dialect storage.Dialect) UserRepository {
+	return &userRepository{db: db, dialect: dialect}
 }
 
-func (r *userRepository) AddUser(u models.User) error {
-	_, err := r.db.Exec("INSERT INTO users (name, cpf, password) VALUES ($1, $2, $3)", u.Name, u.CPF, u.Password)
-	return err
+func (r *userRepository) AddUser(ctx context.Context, u models.User) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO users (name, cpf, email, weight, password, role) VALUES ($1, $2, $3, $4, $5, $6)", u.Name, u.CPF, u.Email, u.Weight, u.Password, u.Role)
+	return r.dialect.Error("users", err)
 }
 
-func (r *userRepository) GetUserByCPF(cpf string) *models.User {
+func (r *userRepository) GetUserByCPF(ctx context.Context, cpf string) *models.User {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return scanUser(r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE cpf = $1", cpf))
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id int) *models.User {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return scanUser(r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+}
+
+const userColumns = "id, name, cpf, email, weight, password, role, failed_login_attempts, locked_until, password_changed_at, deactivated_at"
+
+func scanUser(row *sql.Row) *models.User {
 	var user models.User
-	err := r.db.QueryRow("SELECT id, name, cpf, password FROM users WHERE cpf = $1", cpf).Scan(&user.ID, &user.Name, &user.CPF, &user.Password)
+	err := row.Scan(&user.ID, &user.Name, &user.CPF, &user.Email, &user.Weight, &user.Password, &user.Role, &user.FailedLoginAttempts, &user.LockedUntil, &user.PasswordChangedAt, &user.DeactivatedAt)
 	if err != nil {
 		return nil
 	}
 	return &user
 }
+
+// UpdatePassword also clears any lockout: whoever proved they control the
+// account should be able to log in with the new password right away.
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hash string, changedAt int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET password = $1, password_changed_at = $2, failed_login_attempts = 0, locked_until = NULL WHERE id = $3", hash, changedAt, userID)
+	return err
+}
+
+// RecordLoginFailure increments the user's consecutive failures in a single
+// statement, so concurrent attempts are all counted, and returns the new total.
+func (r *userRepository) RecordLoginFailure(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	var failures int
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = $1 RETURNING failed_login_attempts", userID).Scan(&failures)
+	return failures, err
+}
+
+func (r *userRepository) LockUser(ctx context.Context, userID int, until int64) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET locked_until = $1 WHERE id = $2", until, userID)
+	return err
+}
+
+func (r *userRepository) ResetLoginFailures(ctx context.Context, userID int) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1", userID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *userRepository) UpdateName(ctx context.Context, userID int, name string) error {
+	return r.updateUser(ctx, "UPDATE users SET name = $1 WHERE id = $2", name, userID)
+}
+
+// LOWER rather than ILIKE, which SQLite lacks; SQLite lowers ASCII letters only.
+const userSearch = `($1 = '' OR LOWER(name) LIKE '%' || LOWER($2) || '%' ESCAPE '\' OR cpf LIKE $2 || '%' ESCAPE '\')`
+
+func (r *userRepository) ListUsers(ctx context.Context, filter ListFilter) ([]models.User, int, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	conn := r.dialect.Conn(ctx, r.db)
+	pattern := LikePattern(filter.Search)
+	var total int
+	err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE "+userSearch, filter.Search, pattern).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT "+userColumns+" FROM users WHERE "+userSearch+" ORDER BY name, id LIMIT $3 OFFSET $4", filter.Search, pattern, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Name, &u.CPF, &u.Email, &u.Weight, &u.Password, &u.Role, &u.FailedLoginAttempts, &u.LockedUntil, &u.PasswordChangedAt, &u.DeactivatedAt); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, u)
+	}
+	return users, total, rows.Err()
+}
+
+func (r *userRepository) SetDeactivated(ctx context.Context, userID int, deactivatedAt *int64) error {
+	return r.updateUser(ctx, "UPDATE users SET deactivated_at = $1 WHERE id = $2", deactivatedAt, userID)
+}
+
+func (r *userRepository) UpdateMembership(ctx context.Context, userID int, name, email string, weight int) error {
+	return r.updateUser(ctx, "UPDATE users SET name = $1, email = $2, weight = $3 WHERE id = $4", name, email, weight, userID)
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, userID int, role string) error {
+	return r.updateUser(ctx, "UPDATE users SET role = $1 WHERE id = $2", role, userID)
+}
+
+// updateUser runs an UPDATE of a single user, returning sql.ErrNoRows when
+// there is no such user.
+func (r *userRepository) updateUser(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
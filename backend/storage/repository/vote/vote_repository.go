@@ -1,44 +1,131 @@
 package vote
 
 import (
-	"database/sql"
+	"context"
 	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
 )
 
 type VoteRepository interface {
-	RegisterVote(vote models.Vote) error
-	HasUserVoted(topicID int, userID int) (bool, error)
-	GetResult(topicID int) (yes int, no int, err error)
+	RegisterVote(ctx context.Context, vote models.Vote) error
+	HasUserVoted(ctx context.Context, sessionID int, userID int) (bool, error)
+	GetResult(ctx context.Context, topicID int) (yes int, no int, err error)
+	ListRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error)
+	// GetVotingSummary lists at most recent votes, latest round first.
+	GetVotingSummary(ctx context.Context, userID int, recent int) (models.VotingSummary, error)
 }
 
 type voteRepository struct {
-	db *sql.DB
+	db      storage.DBTX
+	dialect storage.Dialect
 }
 
-func NewVoteRepository(db *sql.DB) VoteRepository {
-	return &voteRepository{db: db}
+func NewVoteRepository(db storage.DBTX, dialect storage.Dialect) VoteRepository {
+	return &voteRepository{db: db, dialect: dialect}
 }
 
-func (r *voteRepository) RegisterVote(vote models.Vote) error {
-	_, err := r.db.Exec("INSERT INTO votes (topic_id, user_id, choice) VALUES ($1, $2, $3)", vote.TopicID, vote.UserID, vote.Choice)
-	return err
+func (r *voteRepository) RegisterVote(ctx context.Context, vote models.Vote) error {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.dialect.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO votes (topic_id, session_id, user_id, choice) VALUES ($1, $2, $3, $4)", vote.TopicID, vote.SessionID, vote.UserID, vote.Choice)
+	return r.dialect.Error("votes", err)
 }
 
-func (r *voteRepository) HasUserVoted(topicID int, userID int) (bool, error) {
+func (r *voteRepository) HasUserVoted(ctx context.Context, sessionID int, userID int) (bool, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
 	var count int
-	err := r.db.QueryRow("SELECT COUNT(*) FROM votes WHERE topic_id = $1 AND user_id = $2", topicID, userID).Scan(&count)
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM votes WHERE session_id = $1 AND user_id = $2", sessionID, userID).Scan(&count)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *voteRepository) GetResult(topicID int) (yes int, no int, err error) {
-	err = r.db.QueryRow("SELECT COUNT(*) FROM votes WHERE topic_id = $1 AND choice = 'Sim'", topicID).Scan(&yes)
+const latestRoundQuery = "SELECT id FROM sessions WHERE topic_id = $1 ORDER BY round DESC LIMIT 1"
+
+func (r *voteRepository) GetResult(ctx context.Context, topicID int) (yes int, no int, err error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	err = r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM votes WHERE session_id = ("+latestRoundQuery+") AND choice = 'Sim'", topicID).Scan(&yes)
 	if err != nil {
 		return
 	}
 
-	err = r.db.QueryRow("SELECT COUNT(*) FROM votes WHERE topic_id = $1 AND choice = 'Não'", topicID).Scan(&no)
+	err = r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, "SELECT COUNT(*) FROM votes WHERE session_id = ("+latestRoundQuery+") AND choice = 'Não'", topicID).Scan(&no)
 	return
 }
+
+func (r *voteRepository) ListRoundResults(ctx context.Context, topicID int) ([]models.RoundResult, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.dialect.Conn(ctx, r.db).QueryContext(ctx, `
+		SELECT s.id, s.round, s.open_at, s.close_at,
+			COALESCE(SUM(CASE WHEN v.choice = 'Sim' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN v.choice = 'Não' THEN 1 ELSE 0 END), 0)
+		FROM sessions s
+		LEFT JOIN votes v ON v.session_id = s.id
+		WHERE s.topic_id = $1
+		GROUP BY s.id, s.round, s.open_at, s.close_at
+		ORDER BY s.round
+	`, topicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.RoundResult{}
+	for rows.Next() {
+		var rr models.RoundResult
+		if err := rows.Scan(&rr.SessionID, &rr.Round, &rr.OpenAt, &rr.CloseAt, &rr.Yes, &rr.No); err != nil {
+			return nil, err
+		}
+		results = append(results, rr)
+	}
+	return results, nil
+}
+
+func (r *voteRepository) GetVotingSummary(ctx context.Context, userID int, recent int) (models.VotingSummary, error) {
+	ctx, cancel := connection.WithQueryTimeout(ctx)
+	defer cancel()
+
+	summary := models.VotingSummary{Recent: []models.VoteRecord{}}
+	err := r.dialect.Conn(ctx, r.db).QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT topic_id),
+			COALESCE(SUM(CASE WHEN choice = 'Sim' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN choice = 'Não' THEN 1 ELSE 0 END), 0)
+		FROM votes
+		WHERE user_id = $1
+	`, userID).Scan(&summary.TotalVotes, &summary.Topics, &summary.Yes, &summary.No)
+	if err != nil {
+		return models.VotingSummary{}, err
+	}
+
+	rows, err := r.dialect.Conn(ctx, r.db).QueryContext(ctx, `
+		SELECT v.topic_id, t.name, s.round, v.choice, s.open_at
+		FROM votes v
+		JOIN sessions s ON s.id = v.session_id
+		JOIN topics t ON t.id = v.topic_id
+		WHERE v.user_id = $1
+		ORDER BY s.open_at DESC, v.id DESC
+		LIMIT $2
+	`, userID, recent)
+	if err != nil {
+		return models.VotingSummary{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vr models.VoteRecord
+		if err := rows.Scan(&vr.TopicID, &vr.TopicName, &vr.Round, &vr.Choice, &vr.OpenAt); err != nil {
+			return models.VotingSummary{}, err
+		}
+		summary.Recent = append(summary.Recent, vr)
+	}
+	return summary, rows.Err()
+}
@@ -0,0 +1,17 @@
+package sqlite
+
+import (
+	"desafio-tecnico-fullstack/backend/storage"
+	"regexp"
+)
+
+// Dialect runs the repositories of storage/repository on SQLite.
+var Dialect = storage.Dialect{Rebind: rebind, TranslateError: translateError}
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// rebind turns $n into ?n, which SQLite also binds to the nth argument, so a
+// query may still repeat or reorder its parameters.
+func rebind(query string) string {
+	return placeholder.ReplaceAllString(query, "?$1")
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"desafio-tecnico-fullstack/backend/storage"
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// translateError rewords SQLite constraint failures like Postgres reports
+// them, since services look for "duplicate key" to detect conflicts.
+func translateError(table string, err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		// "constraint failed: UNIQUE constraint failed: users.cpf (2067)"
+		columns := sqliteErr.Error()
+		if i := strings.LastIndex(columns, "failed: "); i >= 0 {
+			columns = columns[i+len("failed: "):]
+		}
+		if i := strings.LastIndex(columns, " ("); i >= 0 {
+			columns = columns[:i]
+		}
+		return storage.UniqueViolation(columns)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return storage.ForeignKeyViolation(table, table+"_fkey")
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+	"desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+	"desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	"desafio-tecnico-fullstack/backend/storage/repository/session"
+	"desafio-tecnico-fullstack/backend/storage/repository/topic"
+	"desafio-tecnico-fullstack/backend/storage/repository/twofactor"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"desafio-tecnico-fullstack/backend/storage/storagetest"
+)
+
+func TestSQLiteStorage_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, clk clock.Clock) storagetest.Repositories {
+		db, err := connection.NewSQLiteDB(filepath.Join(t.TempDir(), "votacao.db"))
+		if err != nil {
+			t.Fatalf("erro ao abrir o banco: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := connection.NewMigrator(db, config.StorageDriverSQLite)
+		if err != nil {
+			t.Fatalf("erro ao carregar migrações: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("erro ao aplicar migrações: %v", err)
+		}
+
+		return storagetest.Repositories{
+			Users:          user.NewUserRepository(db, Dialect),
+			Topics:         topic.NewTopicRepository(db, Dialect),
+			Sessions:       session.NewSessionRepository(db, clk, Dialect),
+			Votes:          vote.NewVoteRepository(db, Dialect),
+			PasswordResets: passwordreset.NewPasswordResetRepository(db, Dialect),
+			TwoFactor:      twofactor.NewTwoFactorRepository(db, Dialect),
+			OIDCStates:     oidcstate.NewOIDCStateRepository(db, Dialect),
+			UnitOfWork:     storage.NewUnitOfWork(db),
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package storagetest_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/connection"
+	"desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+	"desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	"desafio-tecnico-fullstack/backend/storage/repository/session"
+	"desafio-tecnico-fullstack/backend/storage/repository/topic"
+	"desafio-tecnico-fullstack/backend/storage/repository/twofactor"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"desafio-tecnico-fullstack/backend/storage/storagetest"
+
+	_ "github.com/lib/pq"
+)
+
+// TEST_DATABASE_URL must point to a disposable database: it is migrated to the
+// latest version and every test truncates all tables.
+func TestPostgresStorage_Contract(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL não definida")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("erro ao abrir o banco: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := connection.NewMigrator(db, config.StorageDriverPostgres)
+	if err != nil {
+		t.Fatalf("erro ao carregar migrações: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+
+	storagetest.Run(t, func(t *testing.T, clk clock.Clock) storagetest.Repositories {
+		_, err := db.Exec("TRUNCATE oidc_login_states, totp_recovery_codes, user_totp, password_reset_tokens, session_events, votes, sessions, topics, users RESTART IDENTITY CASCADE")
+		if err != nil {
+			t.Fatalf("erro ao limpar o banco: %v", err)
+		}
+		return storagetest.Repositories{
+			Users:          user.NewUserRepository(db, storage.Postgres),
+			Topics:         topic.NewTopicRepository(db, storage.Postgres),
+			Sessions:       session.NewSessionRepository(db, clk, storage.Postgres),
+			Votes:          vote.NewVoteRepository(db, storage.Postgres),
+			PasswordResets: passwordreset.NewPasswordResetRepository(db, storage.Postgres),
+			TwoFactor:      twofactor.NewTwoFactorRepository(db, storage.Postgres),
+			OIDCStates:     oidcstate.NewOIDCStateRepository(db, storage.Postgres),
+			UnitOfWork:     storage.NewUnitOfWork(db),
+		}
+	})
+}
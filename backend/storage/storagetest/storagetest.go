@@ -0,0 +1,921 @@
+// Package storagetest holds the contract every storage backend must honour.
+// Backends run it from their own tests through Run.
+package storagetest
+
+import (
+	"context"
+	"database/sql"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/models"
+	"desafio-tecnico-fullstack/backend/storage"
+	"desafio-tecnico-fullstack/backend/storage/repository/oidcstate"
+	"desafio-tecnico-fullstack/backend/storage/repository/passwordreset"
+	"desafio-tecnico-fullstack/backend/storage/repository/session"
+	"desafio-tecnico-fullstack/backend/storage/repository/topic"
+	"desafio-tecnico-fullstack/backend/storage/repository/twofactor"
+	"desafio-tecnico-fullstack/backend/storage/repository/user"
+	"desafio-tecnico-fullstack/backend/storage/repository/vote"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Repositories struct {
+	Users          user.UserRepository
+	Topics         topic.TopicRepository
+	Sessions       session.SessionRepository
+	Votes          vote.VoteRepository
+	PasswordResets passwordreset.PasswordResetRepository
+	TwoFactor      twofactor.TwoFactorRepository
+	OIDCStates     oidcstate.OIDCStateRepository
+	UnitOfWork     storage.UnitOfWork
+}
+
+// Factory returns repositories over an empty backend whose session
+// repository reads time from clk.
+type Factory func(t *testing.T, clk clock.Clock) Repositories
+
+var testNow = time.Unix(1750000000, 0)
+
+func Run(t *testing.T, newRepositories Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories, clk *clock.Fake)
+	}{
+		{"Users/AddAndGet", testUsersAddAndGet},
+		{"Users/DuplicateCPF", testUsersDuplicateCPF},
+		{"Users/LoginFailures", testUsersLoginFailures},
+		{"Users/GetByIDAndUpdatePassword", testUsersGetByIDAndUpdatePassword},
+		{"Users/UpdateName", testUsersUpdateName},
+		{"Users/List", testUsersList},
+		{"Users/DeactivateAndRole", testUsersDeactivateAndRole},
+		{"Users/UpdateMembership", testUsersUpdateMembership},
+		{"PasswordResets/SingleUse", testPasswordResetsSingleUse},
+		{"PasswordResets/Expired", testPasswordResetsExpired},
+		{"PasswordResets/Invalidate", testPasswordResetsInvalidate},
+		{"PasswordResets/RequireUser", testPasswordResetsRequireUser},
+		{"TwoFactor/Enrollment", testTwoFactorEnrollment},
+		{"TwoFactor/StepsAreNotReplayed", testTwoFactorSteps},
+		{"TwoFactor/RecoveryCodes", testTwoFactorRecoveryCodes},
+		{"OIDCStates/SingleUse", testOIDCStatesSingleUse},
+		{"OIDCStates/Expired", testOIDCStatesExpired},
+		{"Topics/CreateAndList", testTopicsCreateAndList},
+		{"Sessions/OpenRequiresTopic", testSessionsOpenRequiresTopic},
+		{"Sessions/OpenNumbersRounds", testSessionsOpenNumbersRounds},
+		{"Sessions/OpenSingleActiveRound", testSessionsOpenSingleActiveRound},
+		{"Sessions/GetMissing", testSessionsGetMissing},
+		{"Sessions/Update", testSessionsUpdate},
+		{"Sessions/UpdateExpired", testSessionsUpdateExpired},
+		{"Sessions/UpdateExpiredReopened", testSessionsUpdateExpiredReopened},
+		{"Sessions/Events", testSessionsEvents},
+		{"Votes/RegisterAndCount", testVotesRegisterAndCount},
+		{"Votes/OnePerSession", testVotesOnePerSession},
+		{"Votes/RequireUser", testVotesRequireUser},
+		{"Votes/RoundResults", testVotesRoundResults},
+		{"Votes/VotingSummary", testVotesVotingSummary},
+		{"UnitOfWork/Commit", testUnitOfWorkCommit},
+		{"UnitOfWork/Rollback", testUnitOfWorkRollback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(testNow)
+			tt.fn(t, newRepositories(t, clk), clk)
+		})
+	}
+}
+
+func testUsersAddAndGet(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+
+	if err := r.Users.AddUser(ctx, models.User{Name: "Maria", CPF: "12345678901", Email: "maria@exemplo.com", Weight: 3, Password: "hash", Role: models.RoleAdmin}); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	u := r.Users.GetUserByCPF(ctx, "12345678901")
+	if u == nil {
+		t.Fatal("esperava usuário cadastrado")
+	}
+	if u.ID == 0 || u.Name != "Maria" || u.Email != "maria@exemplo.com" || u.Weight != 3 || u.Password != "hash" || u.Role != models.RoleAdmin {
+		t.Errorf("usuário incorreto: %+v", u)
+	}
+	if r.Users.GetUserByCPF(ctx, "00000000000") != nil {
+		t.Error("esperava nil para CPF inexistente")
+	}
+}
+
+func testUsersDuplicateCPF(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	mustAddUser(t, r, "12345678901")
+
+	err := r.Users.AddUser(ctx, models.User{Name: "Outro", CPF: "12345678901", Password: "hash", Role: models.RoleAssociate})
+	if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("esperava violação de unicidade, obteve: %v", err)
+	}
+}
+
+func testUsersLoginFailures(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	userID := mustAddUser(t, r, "12345678901").ID
+
+	for want := 1; want <= 2; want++ {
+		failures, err := r.Users.RecordLoginFailure(ctx, userID)
+		if err != nil || failures != want {
+			t.Fatalf("esperava %d falhas, obteve %d (erro: %v)", want, failures, err)
+		}
+	}
+	until := testNow.Add(time.Minute).Unix()
+	if err := r.Users.LockUser(ctx, userID, until); err != nil {
+		t.Fatalf("erro ao bloquear usuário: %v", err)
+	}
+
+	u := r.Users.GetUserByCPF(ctx, "12345678901")
+	if u.FailedLoginAttempts != 2 || u.LockedUntil == nil || *u.LockedUntil != until {
+		t.Errorf("estado de bloqueio incorreto: %+v", u)
+	}
+
+	if err := r.Users.ResetLoginFailures(ctx, userID); err != nil {
+		t.Fatalf("erro ao desbloquear usuário: %v", err)
+	}
+	u = r.Users.GetUserByCPF(ctx, "12345678901")
+	if u.FailedLoginAttempts != 0 || u.LockedUntil != nil {
+		t.Errorf("esperava usuário desbloqueado: %+v", u)
+	}
+
+	if err := r.Users.ResetLoginFailures(ctx, userID+1000); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("esperava sql.ErrNoRows para usuário inexistente, obteve: %v", err)
+	}
+}
+
+func testUsersGetByIDAndUpdatePassword(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	added := mustAddUser(t, r, "12345678901")
+	if _, err := r.Users.RecordLoginFailure(ctx, added.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Users.LockUser(ctx, added.ID, testNow.Unix()); err != nil {
+		t.Fatal(err)
+	}
+
+	changedAt := testNow.Unix()
+	if err := r.Users.UpdatePassword(ctx, added.ID, "novo-hash", changedAt); err != nil {
+		t.Fatalf("erro ao atualizar senha: %v", err)
+	}
+
+	u := r.Users.GetUserByID(ctx, added.ID)
+	if u == nil || u.CPF != "12345678901" || u.Password != "novo-hash" {
+		t.Fatalf("usuário incorreto: %+v", u)
+	}
+	if u.PasswordChangedAt == nil || *u.PasswordChangedAt != changedAt {
+		t.Errorf("esperava password_changed_at %d, obteve %v", changedAt, u.PasswordChangedAt)
+	}
+	if u.FailedLoginAttempts != 0 || u.LockedUntil != nil {
+		t.Errorf("trocar a senha deveria desbloquear a conta: %+v", u)
+	}
+	if r.Users.GetUserByID(ctx, added.ID+1000) != nil {
+		t.Error("esperava nil para ID inexistente")
+	}
+}
+
+func testUsersUpdateName(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	added := mustAddUser(t, r, "12345678901")
+
+	if err := r.Users.UpdateName(ctx, added.ID, "Maria Souza"); err != nil {
+		t.Fatalf("erro ao atualizar nome: %v", err)
+	}
+	if u := r.Users.GetUserByID(ctx, added.ID); u == nil || u.Name != "Maria Souza" || u.CPF != "12345678901" {
+		t.Errorf("usuário incorreto: %+v", u)
+	}
+	if err := r.Users.UpdateName(ctx, added.ID+1000, "Ninguém"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("esperava sql.ErrNoRows para ID inexistente, obteve: %v", err)
+	}
+}
+
+func testUsersList(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	for _, u := range []models.User{
+		{Name: "Carla Lima", CPF: "33300000000"},
+		{Name: "Ana Souza", CPF: "11100000000"},
+		{Name: "Bruno Souza", CPF: "22200000000"},
+		{Name: "Diego 100%", CPF: "44400000000"},
+	} {
+		u.Password, u.Role = "hash", models.RoleAssociate
+		if err := r.Users.AddUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func(users []models.User) []string {
+		var names []string
+		for _, u := range users {
+			names = append(names, u.Name)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		name   string
+		filter user.ListFilter
+		want   []string
+		total  int
+	}{
+		{"first page", user.ListFilter{Limit: 2}, []string{"Ana Souza", "Bruno Souza"}, 4},
+		{"last page", user.ListFilter{Limit: 2, Offset: 2}, []string{"Carla Lima", "Diego 100%"}, 4},
+		{"past the end", user.ListFilter{Limit: 2, Offset: 10}, nil, 4},
+		{"name ignoring case", user.ListFilter{Search: "souza", Limit: 10}, []string{"Ana Souza", "Bruno Souza"}, 2},
+		{"cpf prefix", user.ListFilter{Search: "222", Limit: 10}, []string{"Bruno Souza"}, 1},
+		{"cpf only matches the prefix", user.ListFilter{Search: "000", Limit: 10}, nil, 0},
+		{"wildcards are literal", user.ListFilter{Search: "0%", Limit: 10}, []string{"Diego 100%"}, 1},
+		{"underscore is literal", user.ListFilter{Search: "_", Limit: 10}, nil, 0},
+	}
+	for _, tc := range testCases {
+		users, total, err := r.Users.ListUsers(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: erro ao listar usuários: %v", tc.name, err)
+		}
+		if users == nil || !slices.Equal(names(users), tc.want) || total != tc.total {
+			t.Errorf("%s: esperava %v (total %d), obteve %v (total %d)", tc.name, tc.want, tc.total, names(users), total)
+		}
+	}
+}
+
+func testUsersDeactivateAndRole(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	added := mustAddUser(t, r, "12345678901")
+
+	at := testNow.Unix()
+	if err := r.Users.SetDeactivated(ctx, added.ID, &at); err != nil {
+		t.Fatalf("erro ao desativar usuário: %v", err)
+	}
+	if err := r.Users.UpdateRole(ctx, added.ID, models.RoleAdmin); err != nil {
+		t.Fatalf("erro ao alterar papel: %v", err)
+	}
+	u := r.Users.GetUserByID(ctx, added.ID)
+	if u.DeactivatedAt == nil || *u.DeactivatedAt != at || u.Role != models.RoleAdmin {
+		t.Errorf("usuário incorreto: %+v", u)
+	}
+
+	if err := r.Users.SetDeactivated(ctx, added.ID, nil); err != nil {
+		t.Fatalf("erro ao reativar usuário: %v", err)
+	}
+	if u := r.Users.GetUserByCPF(ctx, "12345678901"); u.DeactivatedAt != nil {
+		t.Errorf("esperava usuário reativado: %+v", u)
+	}
+
+	if err := r.Users.SetDeactivated(ctx, added.ID+1000, &at); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("esperava sql.ErrNoRows para ID inexistente, obteve: %v", err)
+	}
+	if err := r.Users.UpdateRole(ctx, added.ID+1000, models.RoleAdmin); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("esperava sql.ErrNoRows para ID inexistente, obteve: %v", err)
+	}
+}
+
+func testUsersUpdateMembership(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	added := mustAddUser(t, r, "12345678901")
+
+	if err := r.Users.UpdateMembership(ctx, added.ID, "Maria Souza", "maria@exemplo.com", 5); err != nil {
+		t.Fatalf("erro ao atualizar associado: %v", err)
+	}
+	u := r.Users.GetUserByID(ctx, added.ID)
+	if u.Name != "Maria Souza" || u.Email != "maria@exemplo.com" || u.Weight != 5 || u.Password != "hash" || u.Role != models.RoleAssociate {
+		t.Errorf("usuário incorreto: %+v", u)
+	}
+	if err := r.Users.UpdateMembership(ctx, added.ID+1000, "Ninguém", "", 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("esperava sql.ErrNoRows para ID inexistente, obteve: %v", err)
+	}
+}
+
+func mustCreateResetToken(t *testing.T, r Repositories, userID int, hash string, expiresAt int64) {
+	t.Helper()
+	err := r.PasswordResets.CreateResetToken(context.Background(), models.PasswordResetToken{UserID: userID, TokenHash: hash, ExpiresAt: expiresAt, CreatedAt: testNow.Unix()})
+	if err != nil {
+		t.Fatalf("erro ao criar token de redefinição: %v", err)
+	}
+}
+
+func testPasswordResetsSingleUse(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	u := mustAddUser(t, r, "12345678901")
+	now := testNow.Unix()
+	mustCreateResetToken(t, r, u.ID, "hash-1", now+60)
+
+	userID, err := r.PasswordResets.UseResetToken(ctx, "hash-1", now)
+	if err != nil || userID != u.ID {
+		t.Fatalf("esperava usuário %d, obteve %d (erro: %v)", u.ID, userID, err)
+	}
+	if _, err := r.PasswordResets.UseResetToken(ctx, "hash-1", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("token já usado deveria ser recusado, obteve: %v", err)
+	}
+	if _, err := r.PasswordResets.UseResetToken(ctx, "desconhecido", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("token desconhecido deveria ser recusado, obteve: %v", err)
+	}
+
+	err = r.PasswordResets.CreateResetToken(ctx, models.PasswordResetToken{UserID: u.ID, TokenHash: "hash-1", ExpiresAt: now + 60, CreatedAt: now})
+	if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("esperava violação de unicidade do hash, obteve: %v", err)
+	}
+}
+
+func testPasswordResetsExpired(t *testing.T, r Repositories, _ *clock.Fake) {
+	u := mustAddUser(t, r, "12345678901")
+	now := testNow.Unix()
+	mustCreateResetToken(t, r, u.ID, "hash-1", now)
+
+	if _, err := r.PasswordResets.UseResetToken(context.Background(), "hash-1", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("token expirado deveria ser recusado, obteve: %v", err)
+	}
+}
+
+func testPasswordResetsInvalidate(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	u := mustAddUser(t, r, "12345678901")
+	other := mustAddUser(t, r, "10987654321")
+	now := testNow.Unix()
+	mustCreateResetToken(t, r, u.ID, "hash-1", now+60)
+	mustCreateResetToken(t, r, u.ID, "hash-2", now+60)
+	mustCreateResetToken(t, r, other.ID, "hash-3", now+60)
+
+	if err := r.PasswordResets.InvalidateResetTokens(ctx, u.ID, now); err != nil {
+		t.Fatalf("erro ao invalidar tokens: %v", err)
+	}
+	for _, hash := range []string{"hash-1", "hash-2"} {
+		if _, err := r.PasswordResets.UseResetToken(ctx, hash, now); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("token %s deveria ter sido invalidado, obteve: %v", hash, err)
+		}
+	}
+	if _, err := r.PasswordResets.UseResetToken(ctx, "hash-3", now); err != nil {
+		t.Errorf("tokens de outros usuários não deveriam ser afetados: %v", err)
+	}
+}
+
+func testPasswordResetsRequireUser(t *testing.T, r Repositories, _ *clock.Fake) {
+	err := r.PasswordResets.CreateResetToken(context.Background(), models.PasswordResetToken{UserID: 999, TokenHash: "hash-1", ExpiresAt: testNow.Unix(), CreatedAt: testNow.Unix()})
+	if err == nil || !strings.Contains(err.Error(), "foreign key") {
+		t.Errorf("esperava violação de chave estrangeira, obteve: %v", err)
+	}
+}
+
+func testTwoFactorEnrollment(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	u := mustAddUser(t, r, "12345678901")
+
+	if _, err := r.TwoFactor.GetTOTP(ctx, u.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("esperava sql.ErrNoRows sem cadastro, obteve: %v", err)
+	}
+	if err := r.TwoFactor.EnableTOTP(ctx, u.ID, testNow.Unix()); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("não deveria ativar sem segredo, obteve: %v", err)
+	}
+
+	if err := r.TwoFactor.SaveTOTPSecret(ctx, u.ID, "SEGREDO1"); err != nil {
+		t.Fatalf("erro ao salvar segredo: %v", err)
+	}
+	if err := r.TwoFactor.EnableTOTP(ctx, u.ID, testNow.Unix()); err != nil {
+		t.Fatalf("erro ao ativar: %v", err)
+	}
+	if err := r.TwoFactor.UseTOTPStep(ctx, u.ID, 10); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.TwoFactor.GetTOTP(ctx, u.ID)
+	if err != nil || got.Secret != "SEGREDO1" || got.EnabledAt == nil || *got.EnabledAt != testNow.Unix() || got.LastUsedStep != 10 {
+		t.Fatalf("cadastro inesperado: %+v (erro: %v)", got, err)
+	}
+
+	if err := r.TwoFactor.SaveTOTPSecret(ctx, u.ID, "SEGREDO2"); err != nil {
+		t.Fatalf("erro ao substituir segredo: %v", err)
+	}
+	got, _ = r.TwoFactor.GetTOTP(ctx, u.ID)
+	if got.Secret != "SEGREDO2" || got.EnabledAt != nil || got.LastUsedStep != 0 {
+		t.Errorf("novo segredo deveria reiniciar o cadastro: %+v", got)
+	}
+
+	err = r.TwoFactor.SaveTOTPSecret(ctx, 999, "SEGREDO")
+	if err == nil || !strings.Contains(err.Error(), "foreign key") {
+		t.Errorf("esperava violação de chave estrangeira, obteve: %v", err)
+	}
+}
+
+func testTwoFactorSteps(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	u := mustAddUser(t, r, "12345678901")
+	if err := r.TwoFactor.SaveTOTPSecret(ctx, u.ID, "SEGREDO"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.TwoFactor.UseTOTPStep(ctx, u.ID, 100); err != nil {
+		t.Fatalf("erro ao usar passo: %v", err)
+	}
+	for _, step := range []int64{100, 99} {
+		if err := r.TwoFactor.UseTOTPStep(ctx, u.ID, step); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("passo %d não deveria ser aceito, obteve: %v", step, err)
+		}
+	}
+	if err := r.TwoFactor.UseTOTPStep(ctx, u.ID, 101); err != nil {
+		t.Errorf("passo seguinte deveria ser aceito: %v", err)
+	}
+}
+
+func testTwoFactorRecoveryCodes(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	u := mustAddUser(t, r, "12345678901")
+	other := mustAddUser(t, r, "10987654321")
+	now := testNow.Unix()
+
+	if err := r.TwoFactor.ReplaceRecoveryCodes(ctx, u.ID, []string{"a", "b"}); err != nil {
+		t.Fatalf("erro ao salvar códigos: %v", err)
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, u.ID, "a", now); err != nil {
+		t.Fatalf("erro ao usar código: %v", err)
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, u.ID, "a", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("código já usado deveria ser recusado, obteve: %v", err)
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, other.ID, "b", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("código de outro usuário deveria ser recusado, obteve: %v", err)
+	}
+
+	if err := r.TwoFactor.ReplaceRecoveryCodes(ctx, u.ID, []string{"c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, u.ID, "b", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("códigos antigos deveriam ser descartados, obteve: %v", err)
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, u.ID, "c", now); err != nil {
+		t.Errorf("novo código deveria ser aceito: %v", err)
+	}
+}
+
+func mustCreateLoginState(t *testing.T, r Repositories, hash string, expiresAt int64) {
+	t.Helper()
+	err := r.OIDCStates.CreateLoginState(context.Background(), models.OIDCLoginState{StateHash: hash, Nonce: "nonce-" + hash, CodeVerifier: "verificador-" + hash, ExpiresAt: expiresAt, CreatedAt: testNow.Unix()})
+	if err != nil {
+		t.Fatalf("erro ao criar login OIDC: %v", err)
+	}
+}
+
+func testOIDCStatesSingleUse(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	mustCreateLoginState(t, r, "estado-1", now+60)
+
+	got, err := r.OIDCStates.UseLoginState(ctx, "estado-1", now)
+	if err != nil || got.Nonce != "nonce-estado-1" || got.CodeVerifier != "verificador-estado-1" || got.ExpiresAt != now+60 {
+		t.Fatalf("login OIDC inesperado: %+v (erro: %v)", got, err)
+	}
+	if _, err := r.OIDCStates.UseLoginState(ctx, "estado-1", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("estado já usado deveria ser recusado, obteve: %v", err)
+	}
+	if _, err := r.OIDCStates.UseLoginState(ctx, "desconhecido", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("estado desconhecido deveria ser recusado, obteve: %v", err)
+	}
+}
+
+func testOIDCStatesExpired(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	mustCreateLoginState(t, r, "expirado", now)
+	mustCreateLoginState(t, r, "abandonado", now-1)
+	mustCreateLoginState(t, r, "pendente", now+60)
+
+	if _, err := r.OIDCStates.UseLoginState(ctx, "expirado", now); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("estado expirado deveria ser recusado, obteve: %v", err)
+	}
+	if err := r.OIDCStates.DeleteExpiredLoginStates(ctx, now); err != nil {
+		t.Fatalf("erro ao remover estados expirados: %v", err)
+	}
+	// Recreating the hash only succeeds if the expired state is gone.
+	mustCreateLoginState(t, r, "abandonado", now+60)
+	if _, err := r.OIDCStates.UseLoginState(ctx, "pendente", now); err != nil {
+		t.Errorf("estado pendente não deveria ser removido: %v", err)
+	}
+}
+
+func testTopicsCreateAndList(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+
+	topics, err := r.Topics.ListTopics(ctx)
+	if err != nil || topics == nil || len(topics) != 0 {
+		t.Fatalf("esperava lista vazia, obteve %v (erro: %v)", topics, err)
+	}
+
+	first := mustCreateTopic(t, r, "Pauta 1")
+	second := mustCreateTopic(t, r, "Pauta 2")
+	if first.ID == second.ID {
+		t.Errorf("esperava ids distintos, obteve %d", first.ID)
+	}
+	if first.Status != models.TopicStatusWaiting {
+		t.Errorf("esperava status %q, obteve %q", models.TopicStatusWaiting, first.Status)
+	}
+}
+
+func testSessionsOpenRequiresTopic(t *testing.T, r Repositories, _ *clock.Fake) {
+	err := r.Sessions.OpenSession(context.Background(), 999, testNow.Unix(), testNow.Unix()+60)
+	if err == nil || !strings.Contains(err.Error(), "foreign key") {
+		t.Errorf("esperava violação de chave estrangeira, obteve: %v", err)
+	}
+}
+
+func testSessionsOpenNumbersRounds(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+
+	mustOpenSession(t, r, tp.ID, now-120, now-60)
+	mustOpenSession(t, r, tp.ID, now, now+60)
+
+	s, err := r.Sessions.GetSessionByTopic(ctx, tp.ID)
+	if err != nil {
+		t.Fatalf("esperava sessão, obteve erro: %v", err)
+	}
+	if s.Round != 2 || s.OpenAt != now || s.CloseAt != now+60 || s.Status != models.SessionStatusOpen || s.PausedAt != nil {
+		t.Errorf("esperava a segunda rodada aberta, obteve %+v", s)
+	}
+	if got := topicStatus(t, r, tp.ID); got != models.TopicStatusOpen {
+		t.Errorf("esperava pauta %q, obteve %q", models.TopicStatusOpen, got)
+	}
+}
+
+// testSessionsOpenSingleActiveRound covers two requests that both saw no
+// round in progress: the storage refuses the second one.
+func testSessionsOpenSingleActiveRound(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+	mustOpenSession(t, r, tp.ID, now, now+60)
+
+	err := r.Sessions.OpenSession(ctx, tp.ID, now+10, now+70)
+	if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Fatalf("esperava violação de unicidade com uma rodada ativa, obteve: %v", err)
+	}
+
+	s := mustOpenSession(t, r, tp.ID, now+120, now+180)
+	if s.Round != 2 {
+		t.Errorf("esperava a segunda rodada após a expiração, obteve %+v", s)
+	}
+}
+
+func testSessionsGetMissing(t *testing.T, r Repositories, _ *clock.Fake) {
+	tp := mustCreateTopic(t, r, "Pauta")
+
+	if s, err := r.Sessions.GetSessionByTopic(context.Background(), tp.ID); err == nil {
+		t.Errorf("esperava erro para pauta sem sessão, obteve %+v", s)
+	}
+}
+
+func testSessionsUpdate(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+	s := mustOpenSession(t, r, tp.ID, now, now+60)
+
+	pausedAt := now + 10
+	s.Status = models.SessionStatusPaused
+	s.PausedAt = &pausedAt
+	s.CloseAt = now + 120
+	if err := r.Sessions.UpdateSession(ctx, *s, models.TopicStatusPaused); err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+
+	got, err := r.Sessions.GetSessionByTopic(ctx, tp.ID)
+	if err != nil {
+		t.Fatalf("esperava sessão, obteve erro: %v", err)
+	}
+	if got.Status != models.SessionStatusPaused || got.PausedAt == nil || *got.PausedAt != pausedAt || got.CloseAt != now+120 {
+		t.Errorf("sessão não atualizada: %+v", got)
+	}
+	if status := topicStatus(t, r, tp.ID); status != models.TopicStatusPaused {
+		t.Errorf("esperava pauta %q, obteve %q", models.TopicStatusPaused, status)
+	}
+}
+
+func testSessionsUpdateExpired(t *testing.T, r Repositories, clk *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	expired := mustCreateTopic(t, r, "Expirada")
+	running := mustCreateTopic(t, r, "Em andamento")
+	paused := mustCreateTopic(t, r, "Pausada")
+	mustOpenSession(t, r, expired.ID, now-120, now+30)
+	mustOpenSession(t, r, running.ID, now, now+600)
+	ps := mustOpenSession(t, r, paused.ID, now-120, now+30)
+	ps.Status = models.SessionStatusPaused
+	ps.PausedAt = &now
+	if err := r.Sessions.UpdateSession(ctx, *ps, models.TopicStatusPaused); err != nil {
+		t.Fatalf("esperava sucesso ao pausar, obteve erro: %v", err)
+	}
+
+	clk.Advance(time.Minute)
+
+	closed, err := r.Sessions.UpdateExpiredSessions(ctx)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if closed != 1 {
+		t.Errorf("esperava 1 pauta encerrada, obteve %d", closed)
+	}
+	if got := topicStatus(t, r, expired.ID); got != models.TopicStatusClosed {
+		t.Errorf("esperava pauta expirada %q, obteve %q", models.TopicStatusClosed, got)
+	}
+	if got := topicStatus(t, r, running.ID); got != models.TopicStatusOpen {
+		t.Errorf("esperava pauta em andamento %q, obteve %q", models.TopicStatusOpen, got)
+	}
+	if got := topicStatus(t, r, paused.ID); got != models.TopicStatusPaused {
+		t.Errorf("esperava pauta pausada %q, obteve %q", models.TopicStatusPaused, got)
+	}
+
+	if closed, _ := r.Sessions.UpdateExpiredSessions(ctx); closed != 0 {
+		t.Errorf("esperava nenhuma pauta na segunda execução, obteve %d", closed)
+	}
+}
+
+func testSessionsUpdateExpiredReopened(t *testing.T, r Repositories, clk *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Reaberta")
+	mustOpenSession(t, r, tp.ID, now-120, now-60)
+	mustOpenSession(t, r, tp.ID, now, now+600)
+
+	closed, err := r.Sessions.UpdateExpiredSessions(ctx)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if closed != 0 {
+		t.Errorf("a rodada anterior não deveria encerrar a pauta reaberta, obteve %d", closed)
+	}
+	if got := topicStatus(t, r, tp.ID); got != models.TopicStatusOpen {
+		t.Errorf("esperava pauta %q, obteve %q", models.TopicStatusOpen, got)
+	}
+
+	clk.Advance(11 * time.Minute)
+	if closed, _ := r.Sessions.UpdateExpiredSessions(ctx); closed != 1 {
+		t.Errorf("esperava a pauta encerrada ao fim da segunda rodada, obteve %d", closed)
+	}
+}
+
+func testSessionsEvents(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	admin := mustAddUser(t, r, "12345678901")
+	tp := mustCreateTopic(t, r, "Pauta")
+	s := mustOpenSession(t, r, tp.ID, now, now+60)
+
+	events, err := r.Sessions.ListSessionEvents(ctx, s.ID)
+	if err != nil || events == nil || len(events) != 0 {
+		t.Fatalf("esperava histórico vazio, obteve %v (erro: %v)", events, err)
+	}
+
+	for _, typ := range []string{models.SessionEventPaused, models.SessionEventResumed} {
+		event := models.SessionEvent{SessionID: s.ID, UserID: admin.ID, Type: typ, CloseAt: now + 60, CreatedAt: now}
+		if err := r.Sessions.AddSessionEvent(ctx, event); err != nil {
+			t.Fatalf("esperava sucesso, obteve erro: %v", err)
+		}
+	}
+
+	events, err = r.Sessions.ListSessionEvents(ctx, s.ID)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != models.SessionEventPaused || events[1].Type != models.SessionEventResumed || events[0].ID == 0 {
+		t.Errorf("histórico incorreto: %+v", events)
+	}
+
+	err = r.Sessions.AddSessionEvent(ctx, models.SessionEvent{SessionID: s.ID + 100, UserID: admin.ID, Type: models.SessionEventClosed})
+	if err == nil || !strings.Contains(err.Error(), "foreign key") {
+		t.Errorf("esperava violação de chave estrangeira, obteve: %v", err)
+	}
+}
+
+func testVotesRegisterAndCount(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+
+	if yes, no, err := r.Votes.GetResult(ctx, tp.ID); err != nil || yes != 0 || no != 0 {
+		t.Errorf("esperava 0/0 sem sessão, obteve %d/%d (erro: %v)", yes, no, err)
+	}
+
+	s := mustOpenSession(t, r, tp.ID, now, now+60)
+	for i, choice := range []string{"Sim", "Sim", "Não"} {
+		u := mustAddUser(t, r, "1234567890"+string(rune('0'+i)))
+		mustVote(t, r, tp.ID, s.ID, u.ID, choice)
+	}
+
+	voted, err := r.Votes.HasUserVoted(ctx, s.ID, mustGetUser(t, r, "12345678900").ID)
+	if err != nil || !voted {
+		t.Errorf("esperava voto registrado, obteve %v (erro: %v)", voted, err)
+	}
+
+	yes, no, err := r.Votes.GetResult(ctx, tp.ID)
+	if err != nil || yes != 2 || no != 1 {
+		t.Errorf("esperava 2/1, obteve %d/%d (erro: %v)", yes, no, err)
+	}
+}
+
+func testVotesOnePerSession(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+	u := mustAddUser(t, r, "12345678901")
+	first := mustOpenSession(t, r, tp.ID, now-120, now-60)
+	mustVote(t, r, tp.ID, first.ID, u.ID, "Sim")
+
+	err := r.Votes.RegisterVote(ctx, models.Vote{TopicID: tp.ID, SessionID: first.ID, UserID: u.ID, Choice: "Não"})
+	if err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("esperava violação de unicidade, obteve: %v", err)
+	}
+
+	second := mustOpenSession(t, r, tp.ID, now, now+60)
+	if voted, _ := r.Votes.HasUserVoted(ctx, second.ID, u.ID); voted {
+		t.Error("voto da rodada anterior não deveria contar na nova rodada")
+	}
+	mustVote(t, r, tp.ID, second.ID, u.ID, "Não")
+}
+
+func testVotesRequireUser(t *testing.T, r Repositories, _ *clock.Fake) {
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+	s := mustOpenSession(t, r, tp.ID, now, now+60)
+
+	err := r.Votes.RegisterVote(context.Background(), models.Vote{TopicID: tp.ID, SessionID: s.ID, UserID: 999, Choice: "Sim"})
+	if err == nil || !strings.Contains(err.Error(), "foreign key") {
+		t.Errorf("esperava violação de chave estrangeira, obteve: %v", err)
+	}
+}
+
+func testVotesRoundResults(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	tp := mustCreateTopic(t, r, "Pauta")
+	u := mustAddUser(t, r, "12345678901")
+	first := mustOpenSession(t, r, tp.ID, now-120, now-60)
+	mustVote(t, r, tp.ID, first.ID, u.ID, "Não")
+	second := mustOpenSession(t, r, tp.ID, now, now+60)
+
+	results, err := r.Votes.ListRoundResults(ctx, tp.ID)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("esperava 2 rodadas, obteve %d", len(results))
+	}
+	if results[0].SessionID != first.ID || results[0].Round != 1 || results[0].No != 1 || results[0].Yes != 0 {
+		t.Errorf("primeira rodada incorreta: %+v", results[0])
+	}
+	if results[1].SessionID != second.ID || results[1].Round != 2 || results[1].Yes != 0 || results[1].No != 0 || results[1].OpenAt != now {
+		t.Errorf("segunda rodada incorreta: %+v", results[1])
+	}
+
+	if yes, no, _ := r.Votes.GetResult(ctx, tp.ID); yes != 0 || no != 0 {
+		t.Errorf("resultado deveria considerar só a rodada atual, obteve %d/%d", yes, no)
+	}
+}
+
+func testVotesVotingSummary(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	now := testNow.Unix()
+	u := mustAddUser(t, r, "12345678901")
+	other := mustAddUser(t, r, "10987654321")
+
+	if summary, err := r.Votes.GetVotingSummary(ctx, u.ID, 5); err != nil || summary.TotalVotes != 0 || summary.Recent == nil || len(summary.Recent) != 0 {
+		t.Errorf("esperava resumo vazio, obteve %+v (erro: %v)", summary, err)
+	}
+
+	first := mustCreateTopic(t, r, "Primeira")
+	second := mustCreateTopic(t, r, "Segunda")
+	firstRound1 := mustOpenSession(t, r, first.ID, now-300, now-240)
+	mustVote(t, r, first.ID, firstRound1.ID, u.ID, "Sim")
+	firstRound2 := mustOpenSession(t, r, first.ID, now-120, now-60)
+	mustVote(t, r, first.ID, firstRound2.ID, u.ID, "Não")
+	secondRound := mustOpenSession(t, r, second.ID, now, now+60)
+	mustVote(t, r, second.ID, secondRound.ID, u.ID, "Sim")
+	mustVote(t, r, second.ID, secondRound.ID, other.ID, "Não")
+
+	summary, err := r.Votes.GetVotingSummary(ctx, u.ID, 2)
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if summary.TotalVotes != 3 || summary.Topics != 2 || summary.Yes != 2 || summary.No != 1 {
+		t.Errorf("totais incorretos: %+v", summary)
+	}
+	want := []models.VoteRecord{
+		{TopicID: second.ID, TopicName: "Segunda", Round: 1, Choice: "Sim", OpenAt: now},
+		{TopicID: first.ID, TopicName: "Primeira", Round: 2, Choice: "Não", OpenAt: now - 120},
+	}
+	if len(summary.Recent) != len(want) {
+		t.Fatalf("esperava %d votos recentes, obteve %+v", len(want), summary.Recent)
+	}
+	for i := range want {
+		if summary.Recent[i] != want[i] {
+			t.Errorf("voto recente %d: esperava %+v, obteve %+v", i, want[i], summary.Recent[i])
+		}
+	}
+}
+
+func testUnitOfWorkCommit(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	tp := mustCreateTopic(t, r, "Pauta")
+
+	err := r.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		return r.Sessions.OpenSession(ctx, tp.ID, testNow.Unix(), testNow.Unix()+60)
+	})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if _, err := r.Sessions.GetSessionByTopic(ctx, tp.ID); err != nil {
+		t.Errorf("esperava sessão gravada, obteve erro: %v", err)
+	}
+}
+
+func testUnitOfWorkRollback(t *testing.T, r Repositories, _ *clock.Fake) {
+	ctx := context.Background()
+	tp := mustCreateTopic(t, r, "Pauta")
+	failure := errors.New("falha no meio da operação")
+
+	err := r.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		if err := r.Sessions.OpenSession(ctx, tp.ID, testNow.Unix(), testNow.Unix()+60); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("esperava o erro original, obteve: %v", err)
+	}
+	if s, err := r.Sessions.GetSessionByTopic(ctx, tp.ID); err == nil {
+		t.Errorf("sessão deveria ter sido desfeita, obteve %+v", s)
+	}
+	if got := topicStatus(t, r, tp.ID); got != models.TopicStatusWaiting {
+		t.Errorf("status da pauta deveria ter sido desfeito, obteve %q", got)
+	}
+}
+
+func mustAddUser(t *testing.T, r Repositories, cpf string) *models.User {
+	t.Helper()
+	err := r.Users.AddUser(context.Background(), models.User{Name: "Associado " + cpf, CPF: cpf, Password: "hash", Role: models.RoleAssociate})
+	if err != nil {
+		t.Fatalf("erro ao cadastrar usuário: %v", err)
+	}
+	return mustGetUser(t, r, cpf)
+}
+
+func mustGetUser(t *testing.T, r Repositories, cpf string) *models.User {
+	t.Helper()
+	u := r.Users.GetUserByCPF(context.Background(), cpf)
+	if u == nil {
+		t.Fatalf("usuário %s não encontrado", cpf)
+	}
+	return u
+}
+
+func mustCreateTopic(t *testing.T, r Repositories, name string) models.Topic {
+	t.Helper()
+	if err := r.Topics.CreateTopic(context.Background(), models.Topic{Name: name, Status: models.TopicStatusWaiting}); err != nil {
+		t.Fatalf("erro ao criar pauta: %v", err)
+	}
+	topics, err := r.Topics.ListTopics(context.Background())
+	if err != nil {
+		t.Fatalf("erro ao listar pautas: %v", err)
+	}
+	for _, tp := range topics {
+		if tp.Name == name {
+			return tp
+		}
+	}
+	t.Fatalf("pauta %q não encontrada", name)
+	return models.Topic{}
+}
+
+func topicStatus(t *testing.T, r Repositories, topicID int) string {
+	t.Helper()
+	topics, err := r.Topics.ListTopics(context.Background())
+	if err != nil {
+		t.Fatalf("erro ao listar pautas: %v", err)
+	}
+	for _, tp := range topics {
+		if tp.ID == topicID {
+			return tp.Status
+		}
+	}
+	t.Fatalf("pauta %d não encontrada", topicID)
+	return ""
+}
+
+func mustOpenSession(t *testing.T, r Repositories, topicID int, openAt, closeAt int64) *models.Session {
+	t.Helper()
+	if err := r.Sessions.OpenSession(context.Background(), topicID, openAt, closeAt); err != nil {
+		t.Fatalf("erro ao abrir sessão: %v", err)
+	}
+	s, err := r.Sessions.GetSessionByTopic(context.Background(), topicID)
+	if err != nil {
+		t.Fatalf("erro ao buscar sessão: %v", err)
+	}
+	return s
+}
+
+func mustVote(t *testing.T, r Repositories, topicID, sessionID, userID int, choice string) {
+	t.Helper()
+	err := r.Votes.RegisterVote(context.Background(), models.Vote{TopicID: topicID, SessionID: sessionID, UserID: userID, Choice: choice})
+	if err != nil {
+		t.Fatalf("erro ao registrar voto: %v", err)
+	}
+}
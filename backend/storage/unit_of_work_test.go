@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type fakeDriver struct {
+	commits   int
+	rollbacks int
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("não suportado")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return &fakeTx{d: c.d}, nil }
+
+type fakeTx struct{ d *fakeDriver }
+
+func (t *fakeTx) Commit() error   { t.d.commits++; return nil }
+func (t *fakeTx) Rollback() error { t.d.rollbacks++; return nil }
+
+func newFakeDB(t *testing.T) (*sql.DB, *fakeDriver) {
+	d := &fakeDriver{}
+	db := sql.OpenDB(&fakeConnector{d: d})
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c *fakeConnector) Driver() driver.Driver                        { return c.d }
+
+func TestUnitOfWork_CommitsOnSuccess(t *testing.T) {
+	db, d := newFakeDB(t)
+	uow := NewUnitOfWork(db)
+
+	var conn DBTX
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		conn = Conn(ctx, db)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if _, ok := conn.(*sql.Tx); !ok {
+		t.Errorf("esperava que os repositórios usassem a transação, obteve %T", conn)
+	}
+	if d.commits != 1 || d.rollbacks != 0 {
+		t.Errorf("esperava 1 commit e nenhum rollback, obteve %d e %d", d.commits, d.rollbacks)
+	}
+}
+
+func TestUnitOfWork_RollsBackOnError(t *testing.T) {
+	db, d := newFakeDB(t)
+	uow := NewUnitOfWork(db)
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		return errors.New("voto já registrado")
+	})
+	if err == nil || err.Error() != "voto já registrado" {
+		t.Errorf("esperava o erro original, obteve: %v", err)
+	}
+	if d.commits != 0 || d.rollbacks != 1 {
+		t.Errorf("esperava nenhum commit e 1 rollback, obteve %d e %d", d.commits, d.rollbacks)
+	}
+}
+
+func TestUnitOfWork_RollsBackOnPanic(t *testing.T) {
+	db, d := newFakeDB(t)
+	uow := NewUnitOfWork(db)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("esperava que o panic fosse propagado")
+		}
+		if d.rollbacks != 1 {
+			t.Errorf("esperava 1 rollback, obteve %d", d.rollbacks)
+		}
+	}()
+	uow.Do(context.Background(), func(ctx context.Context) error {
+		panic("falha inesperada")
+	})
+}
+
+func TestUnitOfWork_NestedCallsJoinOuterTransaction(t *testing.T) {
+	db, d := newFakeDB(t)
+	uow := NewUnitOfWork(db)
+
+	err := uow.Do(context.Background(), func(ctx context.Context) error {
+		outer := Conn(ctx, db)
+		return uow.Do(ctx, func(ctx context.Context) error {
+			if Conn(ctx, db) != outer {
+				t.Error("esperava a mesma transação na chamada aninhada")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if d.commits != 1 {
+		t.Errorf("esperava 1 commit, obteve %d", d.commits)
+	}
+}
+
+func TestConn_WithoutTransactionUsesDB(t *testing.T) {
+	db, _ := newFakeDB(t)
+
+	if Conn(context.Background(), db) != DBTX(db) {
+		t.Error("esperava o banco fora de uma unidade de trabalho")
+	}
+}
@@ -0,0 +1,127 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minRSABits = 2048
+
+// verificationKey is a public key accepted when validating tokens.
+type verificationKey struct {
+	kid string
+	key crypto.PublicKey
+	jwk JWK
+}
+
+// JWK is the public part of a key as published in the JWKS document.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func loadPrivateKey(algorithm, path string) (crypto.Signer, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave privada: %w", err)
+	}
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("chave privada RSA inválida em %s: %w", path, err)
+		}
+		return key, nil
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("chave privada Ed25519 inválida em %s: %w", path, err)
+		}
+		return key.(ed25519.PrivateKey), nil
+	}
+	return nil, fmt.Errorf("algoritmo %s não usa chave privada", algorithm)
+}
+
+func loadPublicKey(algorithm, path string) (crypto.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave pública: %w", err)
+	}
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := jwt.ParseRSAPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("chave pública RSA inválida em %s: %w", path, err)
+		}
+		return key, nil
+	case AlgorithmEdDSA:
+		key, err := jwt.ParseEdPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("chave pública Ed25519 inválida em %s: %w", path, err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("algoritmo %s não usa chave pública", algorithm)
+}
+
+// newVerificationKey derives the key ID from the RFC 7638 thumbprint, so the
+// same key always gets the same kid on every instance without configuring it.
+func newVerificationKey(algorithm string, key crypto.PublicKey) (verificationKey, error) {
+	var jwk JWK
+	var thumbprintInput any
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return verificationKey{}, fmt.Errorf("chave RSA deve ter pelo menos %d bits", minRSABits)
+		}
+		n := base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		jwk = JWK{KeyType: "RSA", N: n, E: e}
+		thumbprintInput = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{e, "RSA", n}
+	case ed25519.PublicKey:
+		x := base64.RawURLEncoding.EncodeToString(k)
+		jwk = JWK{KeyType: "OKP", Curve: "Ed25519", X: x}
+		thumbprintInput = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", x}
+	default:
+		return verificationKey{}, fmt.Errorf("tipo de chave não suportado: %T", key)
+	}
+
+	canonical, err := json.Marshal(thumbprintInput)
+	if err != nil {
+		return verificationKey{}, err
+	}
+	sum := sha256.Sum256(canonical)
+	kid := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	jwk.KeyID = kid
+	jwk.Use = "sig"
+	jwk.Algorithm = algorithm
+	return verificationKey{kid: kid, key: key, jwk: jwk}, nil
+}
@@ -0,0 +1,224 @@
+// Package tokens issues and validates the API's access tokens (JWT) and
+// publishes the public keys that verify them.
+package tokens
+
+import (
+	"crypto"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmHS256 = config.JWTAlgorithmHS256
+	AlgorithmRS256 = config.JWTAlgorithmRS256
+	AlgorithmEdDSA = config.JWTAlgorithmEdDSA
+)
+
+var ErrInvalidToken = errors.New("token inválido")
+
+// purposeTwoFactor marks challenge tokens, which only grant the right to
+// present a second factor.
+const purposeTwoFactor = "2fa"
+
+// Claims are the validated contents of an access token. TwoFactor tells
+// that the login verified a second factor, or went through the identity
+// provider, which is trusted with every factor.
+type Claims struct {
+	UserID    int
+	Role      string
+	TwoFactor bool
+	IssuedAt  time.Time
+}
+
+type Manager interface {
+	Generate(userID int, role string, twoFactor bool) (string, error)
+	Validate(token string) (Claims, error)
+	// GenerateChallenge issues a short-lived token stating that userID passed
+	// the password check, to be exchanged for an access token once the second
+	// factor is verified. Validate never accepts it.
+	GenerateChallenge(userID int, ttl time.Duration) (string, error)
+	ValidateChallenge(token string) (userID int, err error)
+	// JWKS returns the public keys that currently verify tokens: the signing
+	// key and every key kept for rotation. It is empty for HS256.
+	JWKS() JWKS
+}
+
+type claims struct {
+	UserID    int    `json:"user_id"`
+	Role      string `json:"role"`
+	TwoFactor bool   `json:"2fa,omitempty"`
+	Purpose   string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+type manager struct {
+	method     jwt.SigningMethod
+	signingKey any
+	signingKid string
+	keys       map[string]verificationKey
+	secret     []byte
+	issuer     string
+	audience   string
+	ttl        time.Duration
+	clock      clock.Clock
+}
+
+func NewManager(cfg config.JWTConfig, clock clock.Clock) (Manager, error) {
+	m := &manager{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.TTL,
+		clock:    clock,
+		keys:     map[string]verificationKey{},
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		m.method = jwt.SigningMethodHS256
+		m.secret = []byte(cfg.Secret)
+		m.signingKey = m.secret
+		return m, nil
+	case AlgorithmRS256:
+		m.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		m.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("algoritmo JWT não suportado: %q", cfg.Algorithm)
+	}
+
+	signer, err := loadPrivateKey(cfg.Algorithm, cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	m.signingKey = signer
+	if m.signingKid, err = m.addKey(cfg.Algorithm, signer.Public()); err != nil {
+		return nil, err
+	}
+
+	for _, path := range cfg.PublicKeyFiles {
+		key, err := loadPublicKey(cfg.Algorithm, path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := m.addKey(cfg.Algorithm, key); err != nil {
+			return nil, fmt.Errorf("chave pública %s: %w", path, err)
+		}
+	}
+	return m, nil
+}
+
+func (m *manager) addKey(algorithm string, key crypto.PublicKey) (string, error) {
+	vk, err := newVerificationKey(algorithm, key)
+	if err != nil {
+		return "", err
+	}
+	m.keys[vk.kid] = vk
+	return vk.kid, nil
+}
+
+func (m *manager) Generate(userID int, role string, twoFactor bool) (string, error) {
+	return m.sign(claims{UserID: userID, Role: role, TwoFactor: twoFactor}, m.ttl)
+}
+
+func (m *manager) GenerateChallenge(userID int, ttl time.Duration) (string, error) {
+	return m.sign(claims{UserID: userID, Purpose: purposeTwoFactor}, ttl)
+}
+
+func (m *manager) sign(c claims, ttl time.Duration) (string, error) {
+	now := m.clock.Now()
+	c.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   strconv.Itoa(c.UserID),
+		Issuer:    m.issuer,
+		Audience:  jwt.ClaimStrings{m.audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	token := jwt.NewWithClaims(m.method, c)
+	if m.signingKid != "" {
+		token.Header["kid"] = m.signingKid
+	}
+	return token.SignedString(m.signingKey)
+}
+
+func (m *manager) Validate(tokenString string) (Claims, error) {
+	c, err := m.parse(tokenString)
+	if err != nil {
+		return Claims{}, err
+	}
+	if c.Purpose != "" {
+		return Claims{}, fmt.Errorf("%w: token de desafio", ErrInvalidToken)
+	}
+	return Claims{UserID: c.UserID, Role: c.Role, TwoFactor: c.TwoFactor, IssuedAt: c.IssuedAt.Time}, nil
+}
+
+func (m *manager) ValidateChallenge(tokenString string) (int, error) {
+	c, err := m.parse(tokenString)
+	if err != nil {
+		return 0, err
+	}
+	if c.Purpose != purposeTwoFactor {
+		return 0, fmt.Errorf("%w: não é um token de desafio", ErrInvalidToken)
+	}
+	return c.UserID, nil
+}
+
+// parse accepts only tokens signed with the configured algorithm, by a known
+// key, for this issuer and audience, and not expired.
+func (m *manager) parse(tokenString string) (claims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{m.method.Alg()}), jwt.WithoutClaimsValidation())
+
+	var c claims
+	_, err := parser.ParseWithClaims(tokenString, &c, m.keyFor)
+	if err != nil {
+		return claims{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	now := m.clock.Now()
+	switch {
+	case !c.VerifyExpiresAt(now, true):
+		return claims{}, fmt.Errorf("%w: expirado", ErrInvalidToken)
+	case !c.VerifyIssuer(m.issuer, true):
+		return claims{}, fmt.Errorf("%w: emissor inesperado", ErrInvalidToken)
+	case !c.VerifyAudience(m.audience, true):
+		return claims{}, fmt.Errorf("%w: audiência inesperada", ErrInvalidToken)
+	case c.UserID == 0:
+		return claims{}, fmt.Errorf("%w: sem user_id", ErrInvalidToken)
+	case c.IssuedAt == nil:
+		return claims{}, fmt.Errorf("%w: sem iat", ErrInvalidToken)
+	}
+	return c, nil
+}
+
+func (m *manager) keyFor(token *jwt.Token) (any, error) {
+	if m.secret != nil {
+		return m.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	vk, ok := m.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("chave %q desconhecida", kid)
+	}
+	return vk.key, nil
+}
+
+// JWKS lists the signing key first, then the rotated keys ordered by kid.
+func (m *manager) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, kid := range slices.Sorted(maps.Keys(m.keys)) {
+		if kid != m.signingKid {
+			set.Keys = append(set.Keys, m.keys[kid].jwk)
+		}
+	}
+	if vk, ok := m.keys[m.signingKid]; ok {
+		set.Keys = slices.Insert(set.Keys, 0, vk.jwk)
+	}
+	return set
+}
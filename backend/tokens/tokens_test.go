@@ -0,0 +1,241 @@
+package tokens
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var testNow = time.Unix(1750000000, 0)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newKeyFiles writes a PKCS#8 private key and its PKIX public key.
+func newKeyFiles(t *testing.T, key crypto.Signer) (private, public string) {
+	t.Helper()
+	privDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, "private.pem", "PRIVATE KEY", privDER), writePEM(t, "public.pem", "PUBLIC KEY", pubDER)
+}
+
+func newEd25519(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testConfig(algorithm string) config.JWTConfig {
+	return config.JWTConfig{
+		Algorithm: algorithm,
+		Issuer:    "votacao-api",
+		Audience:  "votacao-api",
+		TTL:       time.Hour,
+	}
+}
+
+func mustManager(t *testing.T, cfg config.JWTConfig, clk clock.Clock) Manager {
+	t.Helper()
+	m, err := NewManager(cfg, clk)
+	if err != nil {
+		t.Fatalf("erro ao criar gerenciador: %v", err)
+	}
+	return m
+}
+
+func TestManager_EdDSARoundTrip(t *testing.T) {
+	private, _ := newKeyFiles(t, newEd25519(t))
+	cfg := testConfig(AlgorithmEdDSA)
+	cfg.PrivateKeyFile = private
+	m := mustManager(t, cfg, clock.NewFake(testNow))
+
+	token, err := m.Generate(7, "admin", true)
+	if err != nil {
+		t.Fatalf("erro ao gerar token: %v", err)
+	}
+	claims, err := m.Validate(token)
+	if err != nil || claims.UserID != 7 || claims.Role != "admin" || !claims.TwoFactor {
+		t.Fatalf("esperava usuário 7/admin com verificação em duas etapas, obteve %+v (erro: %v)", claims, err)
+	}
+	if !claims.IssuedAt.Equal(testNow) {
+		t.Errorf("esperava iat %v, obteve %v", testNow, claims.IssuedAt)
+	}
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	jwks := m.JWKS()
+	if len(jwks.Keys) != 1 || parsed.Header["kid"] != jwks.Keys[0].KeyID {
+		t.Errorf("kid do token deveria estar no JWKS: %v %+v", parsed.Header["kid"], jwks)
+	}
+	if k := jwks.Keys[0]; k.KeyType != "OKP" || k.Curve != "Ed25519" || k.Algorithm != "EdDSA" || k.Use != "sig" || k.X == "" {
+		t.Errorf("JWK inesperada: %+v", k)
+	}
+	registered := parsed.Claims.(jwt.MapClaims)
+	if registered["iss"] != "votacao-api" || registered["aud"] == nil || registered["sub"] != "7" {
+		t.Errorf("claims registradas ausentes: %v", registered)
+	}
+}
+
+func TestManager_RS256RoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER := x509.MarshalPKCS1PrivateKey(key)
+	cfg := testConfig(AlgorithmRS256)
+	cfg.PrivateKeyFile = writePEM(t, "rsa.pem", "RSA PRIVATE KEY", privDER)
+	m := mustManager(t, cfg, clock.NewFake(testNow))
+
+	token, _ := m.Generate(3, "associado", false)
+	if claims, err := m.Validate(token); err != nil || claims.UserID != 3 || claims.TwoFactor {
+		t.Fatalf("esperava usuário 3, obteve %d (erro: %v)", claims.UserID, err)
+	}
+	if k := m.JWKS().Keys[0]; k.KeyType != "RSA" || k.E != "AQAB" || k.N == "" {
+		t.Errorf("JWK RSA inesperada: %+v", k)
+	}
+}
+
+func TestManager_RejectsSmallRSAKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testConfig(AlgorithmRS256)
+	cfg.PrivateKeyFile = writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+
+	if _, err := NewManager(cfg, clock.New()); err == nil {
+		t.Error("esperava erro para chave RSA de 1024 bits")
+	}
+}
+
+func TestManager_Rotation(t *testing.T) {
+	clk := clock.NewFake(testNow)
+	oldPrivate, oldPublic := newKeyFiles(t, newEd25519(t))
+	newPrivate, _ := newKeyFiles(t, newEd25519(t))
+
+	oldCfg := testConfig(AlgorithmEdDSA)
+	oldCfg.PrivateKeyFile = oldPrivate
+	oldToken, _ := mustManager(t, oldCfg, clk).Generate(1, "associado", false)
+
+	rotated := testConfig(AlgorithmEdDSA)
+	rotated.PrivateKeyFile = newPrivate
+	if _, err := mustManager(t, rotated, clk).Validate(oldToken); err == nil {
+		t.Error("token da chave antiga não deveria valer sem ela na lista de verificação")
+	}
+
+	rotated.PublicKeyFiles = []string{oldPublic}
+	m := mustManager(t, rotated, clk)
+	if _, err := m.Validate(oldToken); err != nil {
+		t.Errorf("token da chave antiga deveria valer durante a rotação: %v", err)
+	}
+	newToken, _ := m.Generate(1, "associado", false)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, jwt.MapClaims{})
+	if jwks := m.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != parsed.Header["kid"] {
+		t.Errorf("JWKS deveria listar a chave de assinatura primeiro e a antiga em seguida: %+v", jwks)
+	}
+}
+
+func TestManager_RejectsOtherAlgorithms(t *testing.T) {
+	private, public := newKeyFiles(t, newEd25519(t))
+	cfg := testConfig(AlgorithmEdDSA)
+	cfg.PrivateKeyFile = private
+	m := mustManager(t, cfg, clock.NewFake(testNow))
+	publicPEM, _ := os.ReadFile(public)
+	jwks := m.JWKS()
+
+	claims := jwt.MapClaims{"user_id": 1, "role": "admin", "iss": "votacao-api", "aud": "votacao-api", "exp": testNow.Add(time.Hour).Unix()}
+
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = jwks.Keys[0].KeyID
+	forged, _ := hmac.SignedString(publicPEM)
+	if _, err := m.Validate(forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token HS256 assinado com a chave pública deveria ser recusado, obteve: %v", err)
+	}
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := m.Validate(unsigned); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token sem assinatura deveria ser recusado, obteve: %v", err)
+	}
+}
+
+func TestManager_ChecksClaims(t *testing.T) {
+	clk := clock.NewFake(testNow)
+	cfg := testConfig(AlgorithmHS256)
+	cfg.Secret = "0123456789abcdef0123456789abcdef"
+	m := mustManager(t, cfg, clk)
+
+	token, _ := m.Generate(1, "associado", false)
+
+	other := cfg
+	other.Audience = "outro-servico"
+	if _, err := mustManager(t, other, clk).Validate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("esperava recusa por audiência, obteve: %v", err)
+	}
+	other = cfg
+	other.Issuer = "outro-emissor"
+	if _, err := mustManager(t, other, clk).Validate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("esperava recusa por emissor, obteve: %v", err)
+	}
+
+	clk.Advance(time.Hour + time.Second)
+	if _, err := m.Validate(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("esperava recusa por expiração, obteve: %v", err)
+	}
+	if len(m.JWKS().Keys) != 0 {
+		t.Error("HS256 não deveria publicar chaves")
+	}
+}
+
+func TestManager_ChallengeTokensAreSeparate(t *testing.T) {
+	clk := clock.NewFake(testNow)
+	cfg := testConfig(AlgorithmHS256)
+	cfg.Secret = "0123456789abcdef0123456789abcdef"
+	m := mustManager(t, cfg, clk)
+
+	challenge, err := m.GenerateChallenge(4, 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID, err := m.ValidateChallenge(challenge); err != nil || userID != 4 {
+		t.Fatalf("esperava desafio do usuário 4, obteve %d (erro: %v)", userID, err)
+	}
+	if _, err := m.Validate(challenge); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("desafio não deveria valer como token de acesso, obteve: %v", err)
+	}
+
+	access, _ := m.Generate(4, "admin", false)
+	if _, err := m.ValidateChallenge(access); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token de acesso não deveria valer como desafio, obteve: %v", err)
+	}
+
+	clk.Advance(5*time.Minute + time.Second)
+	if _, err := m.ValidateChallenge(challenge); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("desafio expirado deveria ser recusado, obteve: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits.
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		got, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		if err != nil || got != want {
+			t.Errorf("em %d: esperava %s, obteve %s (erro: %v)", unix, want, got, err)
+		}
+	}
+}
+
+func TestValidate_AllowsSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	previous, _ := Code(rfcSecret, Step(now)-1)
+
+	step, ok := Validate(rfcSecret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Errorf("código do passo anterior deveria valer com tolerância 1: %d %v", step, ok)
+	}
+	if _, ok := Validate(rfcSecret, previous, now, 0); ok {
+		t.Error("código do passo anterior não deveria valer sem tolerância")
+	}
+	if _, ok := Validate(rfcSecret, "12345", now, 1); ok {
+		t.Error("código com tamanho errado não deveria valer")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("esperava segredo de 32 caracteres base32, obteve %q", secret)
+	}
+	if _, err := Code(secret, 1); err != nil {
+		t.Errorf("segredo gerado deveria ser utilizável: %v", err)
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("ABC", "Votação", "12345678901")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || !strings.HasSuffix(u.Path, ":12345678901") {
+		t.Errorf("URI inesperada: %s", uri)
+	}
+	if q := u.Query(); q.Get("secret") != "ABC" || q.Get("issuer") != "Votação" || q.Get("digits") != "6" {
+		t.Errorf("parâmetros inesperados: %v", q)
+	}
+}
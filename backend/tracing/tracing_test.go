@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupInMemory() *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	Install(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	return exporter
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "zipkin", "votacao-api")
+	if err == nil {
+		t.Error("esperava erro para exportador desconhecido")
+	}
+}
+
+func TestSetup_NoneIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, "votacao-api")
+	if err != nil {
+		t.Fatalf("esperava sucesso, obteve erro: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown não deveria falhar: %v", err)
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	exporter := setupInMemory()
+
+	_, span := otel.Tracer("test").Start(context.Background(), "VoteService.Vote")
+	End(span, errors.New("voto já registrado"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("esperava 1 span, obteve %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "voto já registrado" {
+		t.Errorf("status do span incorreto: %+v", spans[0].Status)
+	}
+	if len(spans[0].Events) != 1 {
+		t.Errorf("esperava o erro registrado como evento, obteve %d eventos", len(spans[0].Events))
+	}
+}
+
+func TestGinRequestSpanNamedAfterRoute(t *testing.T) {
+	exporter := setupInMemory()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("votacao-api"))
+	router.GET("/api/topics/:topic_id/result", func(c *gin.Context) {
+		_, child := otel.Tracer("test").Start(c.Request.Context(), "VoteService.GetResult")
+		child.End()
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/api/topics/1/result", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("esperava 2 spans, obteve %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if parent.Name != "GET /api/topics/:topic_id/result" {
+		t.Errorf("nome do span HTTP incorreto: %q", parent.Name)
+	}
+	if child.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Error("span do serviço deveria ser filho do span da requisição")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/clock"
+	"desafio-tecnico-fullstack/backend/config"
+	"desafio-tecnico-fullstack/backend/models"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+const userUsage = "uso: app user promote CPF"
+
+// runUser implements the "user promote" subcommand, which makes a registered
+// user an administrator. It is how the first administrator is created; the
+// next ones can be promoted through the API.
+func runUser(ctx context.Context, args []string) error {
+	if len(args) != 2 || args[0] != "promote" {
+		return errors.New(userUsage)
+	}
+	if config.AppConfig.Storage.Driver == config.StorageDriverMemory {
+		return errors.New("o armazenamento em memória não é compartilhado com a API; use postgres ou sqlite")
+	}
+
+	repos, err := openStorage(ctx, config.AppConfig.Storage.Driver, clock.New())
+	if err != nil {
+		return err
+	}
+	defer repos.close()
+
+	u := repos.users.GetUserByCPF(ctx, args[1])
+	if u == nil {
+		return fmt.Errorf("usuário com CPF %s não encontrado", args[1])
+	}
+	if u.Role == models.RoleAdmin {
+		slog.Info("usuário já é administrador", "user_id", u.ID)
+		return nil
+	}
+	if err := repos.users.UpdateRole(ctx, u.ID, models.RoleAdmin); err != nil {
+		return err
+	}
+	slog.Info("usuário promovido a administrador", "user_id", u.ID)
+	return nil
+}
@@ -1,11 +1,16 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
 
 type APIResponse struct {
-	Status string      `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
-	Error  string      `json:"error,omitempty"`
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 func RespondSuccess(c *gin.Context, data interface{}) {
@@ -16,8 +21,22 @@ func RespondSuccess(c *gin.Context, data interface{}) {
 }
 
 func RespondError(c *gin.Context, status int, errMsg string) {
+	c.Error(errors.New(errMsg))
+	c.JSON(status, APIResponse{
+		Status:    "error",
+		Error:     errMsg,
+		RequestID: c.GetString("request_id"),
+	})
+}
+
+// RespondErrorWithData is RespondError for errors that carry details, such as
+// the per-row report of a rejected import.
+func RespondErrorWithData(c *gin.Context, status int, errMsg string, data interface{}) {
+	c.Error(errors.New(errMsg))
 	c.JSON(status, APIResponse{
-		Status: "error",
-		Error:  errMsg,
+		Status:    "error",
+		Data:      data,
+		Error:     errMsg,
+		RequestID: c.GetString("request_id"),
 	})
 }
@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/services/session"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+type SessionExpiryWorker struct {
+	sessionService session.SessionService
+	interval       time.Duration
+	running        atomic.Bool
+	lastBeat       atomic.Int64
+}
+
+func NewSessionExpiryWorker(sessionService session.SessionService, interval time.Duration) *SessionExpiryWorker {
+	return &SessionExpiryWorker{sessionService: sessionService, interval: interval}
+}
+
+// Run closes expired sessions every interval until ctx is cancelled. It
+// refuses an interval that is not positive, which time.NewTicker would panic
+// on, and stays not Alive.
+func (w *SessionExpiryWorker) Run(ctx context.Context) {
+	if w.interval <= 0 {
+		slog.Error("intervalo inválido para encerrar sessões expiradas", "interval", w.interval)
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.running.Store(true)
+	defer w.running.Store(false)
+	w.lastBeat.Store(time.Now().UnixNano())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.lastBeat.Store(time.Now().UnixNano())
+			if err := w.sessionService.UpdateExpiredSessions(ctx); err != nil {
+				slog.Error("erro ao encerrar sessões expiradas", "error", err)
+			}
+		}
+	}
+}
+
+// Alive reports whether Run is active and has ticked recently.
+func (w *SessionExpiryWorker) Alive() bool {
+	if !w.running.Load() {
+		return false
+	}
+	lastBeat := time.Unix(0, w.lastBeat.Load())
+	return time.Since(lastBeat) <= 2*w.interval
+}
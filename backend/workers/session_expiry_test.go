@@ -0,0 +1,98 @@
+package workers
+
+import (
+	"context"
+	"desafio-tecnico-fullstack/backend/models"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mockSessionService struct {
+	updates atomic.Int32
+}
+
+func (m *mockSessionService) OpenSession(ctx context.Context, topicID int, durationMinutes int) error {
+	return nil
+}
+
+func (m *mockSessionService) GetSessionByTopic(ctx context.Context, topicID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) GetSessionDetails(ctx context.Context, topicID int) (*models.SessionDetails, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) UpdateExpiredSessions(ctx context.Context) error {
+	m.updates.Add(1)
+	return nil
+}
+
+func (m *mockSessionService) ExtendSession(ctx context.Context, topicID int, userID int, minutes int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) CloseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) PauseSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) ResumeSession(ctx context.Context, topicID int, userID int) (*models.Session, error) {
+	return nil, nil
+}
+
+func (m *mockSessionService) ListSessionEvents(ctx context.Context, topicID int) ([]models.SessionEvent, error) {
+	return nil, nil
+}
+
+func TestSessionExpiryWorker_RunsUntilCancelled(t *testing.T) {
+	service := &mockSessionService{}
+	worker := NewSessionExpiryWorker(service, 5*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	if !worker.Alive() {
+		t.Error("worker deveria estar ativo durante a execução")
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker não parou após o cancelamento do contexto")
+	}
+
+	if worker.Alive() {
+		t.Error("worker não deveria estar ativo após parar")
+	}
+	if service.updates.Load() == 0 {
+		t.Error("esperava ao menos uma verificação de sessões expiradas")
+	}
+}
+
+func TestSessionExpiryWorker_RejectsNonPositiveInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		worker := NewSessionExpiryWorker(&mockSessionService{}, interval)
+		worker.Run(context.Background())
+		if worker.Alive() {
+			t.Errorf("%v: worker não deveria estar ativo", interval)
+		}
+	}
+}
+
+func TestSessionExpiryWorker_NotAliveBeforeRun(t *testing.T) {
+	worker := NewSessionExpiryWorker(&mockSessionService{}, time.Second)
+	if worker.Alive() {
+		t.Error("worker não deveria estar ativo antes de iniciar")
+	}
+}
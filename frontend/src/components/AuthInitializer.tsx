@@ -1,12 +1,16 @@
 import { useEffect } from 'react';
 import { useAppDispatch } from '../hooks/redux';
-import { initializeAuth } from '../store/authSlice';
+import { getAuthToken } from '../services/api';
+import { fetchCurrentUser, initializeAuth } from '../store/authSlice';
 
 export const AuthInitializer: React.FC<{ children: React.ReactNode }> = ({ children }) => {
   const dispatch = useAppDispatch();
 
   useEffect(() => {
     dispatch(initializeAuth());
+    if (getAuthToken()) {
+      dispatch(fetchCurrentUser());
+    }
   }, [dispatch]);
 
   return <>{children}</>;
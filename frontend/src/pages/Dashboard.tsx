@@ -10,7 +10,8 @@ export const Dashboard: React.FC = () => {
   const dispatch = useAppDispatch();
   const navigate = useNavigate();
   
-  const { isAuthenticated } = useAppSelector((state) => state.auth);
+  const { isAuthenticated, user } = useAppSelector((state) => state.auth);
+  const isAdmin = user?.role === 'admin';
   const { topics, loading, error } = useAppSelector((state) => state.topics);
 
   useSessionChecker();
@@ -102,7 +103,7 @@ export const Dashboard: React.FC = () => {
                       </Link>
                     )}
 
-                    {isAuthenticated && topic.status === 'Aguardando Abertura' && (
+                    {isAdmin && topic.status === 'Aguardando Abertura' && (
                       <button
                         onClick={() => navigate(`/topic/${topic.id}/manage`)}
                         className="btn btn-warning"
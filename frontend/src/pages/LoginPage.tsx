@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
 import { useAppDispatch, useAppSelector } from '../hooks/redux';
-import { loginUser, clearError } from '../store/authSlice';
+import { loginUser, verifyTwoFactor, cancelTwoFactor, clearError } from '../store/authSlice';
 import type { LoginCredentials } from '../types/Auth';
 
 export const LoginPage: React.FC = () => {
@@ -9,8 +9,9 @@ export const LoginPage: React.FC = () => {
     cpf: '',
     password: '',
   });
+  const [code, setCode] = useState('');
   const dispatch = useAppDispatch();
-  const { loading, error, isAuthenticated } = useAppSelector((state) => state.auth);
+  const { loading, error, isAuthenticated, twoFactorChallenge } = useAppSelector((state) => state.auth);
   const navigate = useNavigate();
 
   useEffect(() => {
@@ -22,6 +23,7 @@ export const LoginPage: React.FC = () => {
   useEffect(() => {
     return () => {
       dispatch(clearError());
+      dispatch(cancelTwoFactor());
     };
   }, [dispatch]);
 
@@ -31,6 +33,20 @@ export const LoginPage: React.FC = () => {
     dispatch(loginUser(credentials));
   };
 
+  const handleVerify = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!twoFactorChallenge) {
+      return;
+    }
+    dispatch(clearError());
+    dispatch(verifyTwoFactor({ challengeToken: twoFactorChallenge.challengeToken, code }));
+  };
+
+  const handleCancel = () => {
+    setCode('');
+    dispatch(cancelTwoFactor());
+  };
+
   const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     const { name, value } = e.target;
     setCredentials(prev => ({
@@ -39,6 +55,61 @@ export const LoginPage: React.FC = () => {
     }));
   };
 
+  if (twoFactorChallenge) {
+    return (
+      <div className="centered-page">
+        <div className="card container-sm w-full">
+          <div className="card-body">
+            <h1 className="text-center mb-6">Verificação em duas etapas</h1>
+
+            <form onSubmit={handleVerify}>
+              <div className="form-group">
+                <label htmlFor="code" className="form-label">
+                  Código:
+                </label>
+                <input
+                  type="text"
+                  id="code"
+                  name="code"
+                  value={code}
+                  onChange={(e) => setCode(e.target.value)}
+                  required
+                  autoFocus
+                  autoComplete="one-time-code"
+                  placeholder="123456"
+                  className="form-input"
+                />
+                <small className="text-muted text-sm">
+                  Digite o código do aplicativo autenticador ou um código de recuperação
+                </small>
+              </div>
+
+              {error && (
+                <div className="alert alert-danger">
+                  {error}
+                </div>
+              )}
+
+              <button
+                type="submit"
+                disabled={loading}
+                className="btn btn-primary btn-lg w-full"
+              >
+                {loading ? 'Verificando...' : 'Verificar'}
+              </button>
+            </form>
+
+            <div className="text-center mt-6">
+              <button type="button" onClick={handleCancel} className="btn btn-secondary">
+                Voltar
+              </button>
+            </div>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className="centered-page">
       <div className="card container-sm w-full">
@@ -93,11 +93,11 @@ export const RegisterPage: React.FC = () => {
                 value={credentials.password}
                 onChange={handleChange}
                 required
-                minLength={6}
+                minLength={8}
                 className="form-input"
               />
               <small className="text-muted text-sm">
-                Mínimo 6 caracteres
+                Mínimo 8 caracteres, com letras e números
               </small>
             </div>
 
@@ -1,5 +1,5 @@
 import type { Topic } from '../types/Topic';
-import type { User, LoginCredentials, RegisterCredentials } from '../types/Auth';
+import type { User, LoginCredentials, LoginResult, RegisterCredentials } from '../types/Auth';
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL;
 
@@ -18,7 +18,7 @@ export const fetchTopics = async (): Promise<Topic[]> => {
   
   return responseData.data || [];
 };
-  export const login = async (credentials: LoginCredentials): Promise<User> => {
+  export const login = async (credentials: LoginCredentials): Promise<LoginResult> => {
   const response = await fetch(`${API_BASE_URL}/auth/login`, {
     method: 'POST',
     headers: {
@@ -37,6 +37,45 @@ export const fetchTopics = async (): Promise<Topic[]> => {
     throw new Error(responseData.error);
   }
 
+  if (responseData.data?.two_factor_required) {
+    return {
+      twoFactorRequired: true,
+      challenge: {
+        challengeToken: responseData.data.challenge_token,
+        expiresIn: responseData.data.expires_in,
+      },
+    };
+  }
+
+  if (responseData.data?.token) {
+    localStorage.setItem('auth_token', responseData.data.token);
+  }
+
+  const userData = { ...responseData.data };
+  delete userData.token;
+  
+  return { twoFactorRequired: false, user: userData };
+};
+
+export const verifyTwoFactor = async (challengeToken: string, code: string): Promise<User> => {
+  const response = await fetch(`${API_BASE_URL}/auth/login/2fa`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ challenge_token: challengeToken, code }),
+  });
+
+  const responseData = await response.json();
+
+  if (!response.ok) {
+    throw new Error(responseData.error || `HTTP error! status: ${response.status}`);
+  }
+
+  if (responseData.status === 'error') {
+    throw new Error(responseData.error);
+  }
+
   if (responseData.data?.token) {
     localStorage.setItem('auth_token', responseData.data.token);
   }
@@ -76,6 +115,40 @@ export const register = async (credentials: RegisterCredentials): Promise<User>
   return userData;
 };
 
+// UnauthorizedError means the API refused the token itself (401): it expired
+// or was revoked, unlike network or server errors.
+export class UnauthorizedError extends Error {
+  constructor(message = 'Sessão expirada') {
+    super(message);
+    this.name = 'UnauthorizedError';
+  }
+}
+
+export const getMe = async (): Promise<User> => {
+  const token = getAuthToken();
+  const response = await fetch(`${API_BASE_URL}/me`, {
+    headers: {
+      'Authorization': `Bearer ${token}`,
+    },
+  });
+
+  if (response.status === 401) {
+    throw new UnauthorizedError();
+  }
+
+  const responseData = await response.json();
+
+  if (!response.ok) {
+    throw new Error(responseData.error || `HTTP error! status: ${response.status}`);
+  }
+
+  if (responseData.status === 'error') {
+    throw new Error(responseData.error);
+  }
+
+  return responseData.data;
+};
+
 export const logout = (): void => {
   localStorage.removeItem('auth_token');
 };
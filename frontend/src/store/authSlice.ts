@@ -1,6 +1,6 @@
 import { createSlice, createAsyncThunk, type PayloadAction } from '@reduxjs/toolkit';
-import { login as apiLogin, register as apiRegister, logout as apiLogout, getAuthToken } from '../services/api';
-import type { LoginCredentials, RegisterCredentials, User } from '../types/Auth';
+import { login as apiLogin, verifyTwoFactor as apiVerifyTwoFactor, register as apiRegister, logout as apiLogout, getMe, getAuthToken, UnauthorizedError } from '../services/api';
+import type { LoginCredentials, RegisterCredentials, TwoFactorChallenge, User } from '../types/Auth';
 
 interface AuthState {
   user: User | null;
@@ -8,6 +8,7 @@ interface AuthState {
   isAuthenticated: boolean;
   loading: boolean;
   error: string | null;
+  twoFactorChallenge: TwoFactorChallenge | null;
 }
 
 const initialState: AuthState = {
@@ -16,24 +17,44 @@ const initialState: AuthState = {
   isAuthenticated: !!getAuthToken(),
   loading: false,
   error: null,
+  twoFactorChallenge: null,
 };
 
 export const loginUser = createAsyncThunk(
   'auth/login',
   async (credentials: LoginCredentials, { rejectWithValue }) => {
     try {
-      const user = await apiLogin(credentials);
+      const result = await apiLogin(credentials);
+      if (result.twoFactorRequired) {
+        return { user: null, token: null, challenge: result.challenge };
+      }
       const token = getAuthToken();
       if (!token) {
         throw new Error('Token not received from server');
       }
-      return { user, token };
+      return { user: result.user, token, challenge: null };
     } catch (error) {
       return rejectWithValue(error instanceof Error ? error.message : 'Login failed');
     }
   }
 );
 
+export const verifyTwoFactor = createAsyncThunk(
+  'auth/verifyTwoFactor',
+  async ({ challengeToken, code }: { challengeToken: string; code: string }, { rejectWithValue }) => {
+    try {
+      const user = await apiVerifyTwoFactor(challengeToken, code);
+      const token = getAuthToken();
+      if (!token) {
+        throw new Error('Token not received from server');
+      }
+      return { user, token };
+    } catch (error) {
+      return rejectWithValue(error instanceof Error ? error.message : 'Verification failed');
+    }
+  }
+);
+
 export const registerUser = createAsyncThunk(
   'auth/register',
   async (credentials: RegisterCredentials, { rejectWithValue }) => {
@@ -50,6 +71,24 @@ export const registerUser = createAsyncThunk(
   }
 );
 
+export const fetchCurrentUser = createAsyncThunk(
+  'auth/me',
+  async (_, { rejectWithValue }) => {
+    try {
+      return await getMe();
+    } catch (error) {
+      const unauthorized = error instanceof UnauthorizedError;
+      if (unauthorized) {
+        apiLogout();
+      }
+      return rejectWithValue({
+        message: error instanceof Error ? error.message : 'Session expired',
+        unauthorized,
+      });
+    }
+  }
+);
+
 export const logoutUser = createAsyncThunk(
   'auth/logout',
   async () => {
@@ -65,6 +104,10 @@ const authSlice = createSlice({
     clearError: (state) => {
       state.error = null;
     },
+    cancelTwoFactor: (state) => {
+      state.twoFactorChallenge = null;
+      state.error = null;
+    },
     initializeAuth: (state) => {
       const token = getAuthToken();
       state.token = token;
@@ -77,11 +120,12 @@ const authSlice = createSlice({
         state.loading = true;
         state.error = null;
       })
-      .addCase(loginUser.fulfilled, (state, action: PayloadAction<{ user: User; token: string | null }>) => {
+      .addCase(loginUser.fulfilled, (state, action: PayloadAction<{ user: User | null; token: string | null; challenge: TwoFactorChallenge | null }>) => {
         state.loading = false;
         state.user = action.payload.user;
         state.token = action.payload.token;
-        state.isAuthenticated = true;
+        state.isAuthenticated = !action.payload.challenge;
+        state.twoFactorChallenge = action.payload.challenge;
         state.error = null;
       })
       .addCase(loginUser.rejected, (state, action) => {
@@ -89,6 +133,23 @@ const authSlice = createSlice({
         state.user = null;
         state.token = null;
         state.isAuthenticated = false;
+        state.twoFactorChallenge = null;
+        state.error = action.payload as string;
+      })
+      .addCase(verifyTwoFactor.pending, (state) => {
+        state.loading = true;
+        state.error = null;
+      })
+      .addCase(verifyTwoFactor.fulfilled, (state, action: PayloadAction<{ user: User; token: string | null }>) => {
+        state.loading = false;
+        state.user = action.payload.user;
+        state.token = action.payload.token;
+        state.isAuthenticated = true;
+        state.twoFactorChallenge = null;
+        state.error = null;
+      })
+      .addCase(verifyTwoFactor.rejected, (state, action) => {
+        state.loading = false;
         state.error = action.payload as string;
       })
       .addCase(registerUser.pending, (state) => {
@@ -109,15 +170,29 @@ const authSlice = createSlice({
         state.isAuthenticated = false;
         state.error = action.payload as string;
       })
+      .addCase(fetchCurrentUser.fulfilled, (state, action: PayloadAction<User>) => {
+        state.user = action.payload;
+      })
+      .addCase(fetchCurrentUser.rejected, (state, action) => {
+        const payload = action.payload as { message: string; unauthorized: boolean } | undefined;
+        if (payload?.unauthorized) {
+          state.user = null;
+          state.token = null;
+          state.isAuthenticated = false;
+          return;
+        }
+        state.error = payload?.message ?? null;
+      })
       .addCase(logoutUser.fulfilled, (state) => {
         state.user = null;
         state.token = null;
         state.isAuthenticated = false;
         state.loading = false;
         state.error = null;
+        state.twoFactorChallenge = null;
       });
   },
 });
 
-export const { clearError, initializeAuth } = authSlice.actions;
+export const { clearError, cancelTwoFactor, initializeAuth } = authSlice.actions;
 export default authSlice.reducer; 
\ No newline at end of file
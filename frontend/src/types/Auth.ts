@@ -2,6 +2,7 @@ export interface User {
   id: number;
   cpf: string;
   name: string;
+  role?: string;
 }
 
 export interface LoginCredentials {
@@ -9,6 +10,15 @@ export interface LoginCredentials {
   password: string;
 }
 
+export interface TwoFactorChallenge {
+  challengeToken: string;
+  expiresIn: number;
+}
+
+export type LoginResult =
+  | { twoFactorRequired: false; user: User }
+  | { twoFactorRequired: true; challenge: TwoFactorChallenge };
+
 export interface RegisterCredentials {
   name: string;
   cpf: string;